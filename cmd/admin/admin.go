@@ -0,0 +1,219 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/admin"
+	"github.com/oxiginedev/sabipass/internal/database/postgres"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// flags are shared by every admin subcommand.
+type flags struct {
+	actor  string
+	reason string
+}
+
+func Command(cfg *config.Config) *cobra.Command {
+	f := &flags{}
+
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Support actions on users, quizzes and players",
+	}
+
+	cmd.PersistentFlags().StringVar(&f.actor, "actor", defaultActor(), "Who is acting, recorded in the audit log")
+	cmd.PersistentFlags().StringVar(&f.reason, "reason", "", "Why, recorded in the audit log")
+
+	cmd.AddCommand(userCommand(cfg))
+	cmd.AddCommand(roleCommand(cfg, f, "promote", "Make a user an admin", models.UserRoleAdmin))
+	cmd.AddCommand(roleCommand(cfg, f, "demote", "Make an admin a regular user", models.UserRoleUser))
+	cmd.AddCommand(userActionCommand(cfg, f, "suspend", "Suspend a user's account", (*admin.Admin).Suspend))
+	cmd.AddCommand(userActionCommand(cfg, f, "unsuspend", "Lift a user's suspension or ban", (*admin.Admin).Unsuspend))
+	cmd.AddCommand(userActionCommand(cfg, f, "ban", "Ban a user's account", (*admin.Admin).Ban))
+	cmd.AddCommand(userActionCommand(cfg, f, "warn", "Record a warning against a user", (*admin.Admin).Warn))
+	cmd.AddCommand(userActionCommand(cfg, f, "revoke-sessions", "Sign a user out everywhere", (*admin.Admin).RevokeSessions))
+	cmd.AddCommand(quizActionCommand(cfg, f, "hide-quiz", "Hide a quiz so it cannot be played", (*admin.Admin).HideQuiz))
+	cmd.AddCommand(quizActionCommand(cfg, f, "unhide-quiz", "Make a hidden quiz playable again", (*admin.Admin).UnhideQuiz))
+	cmd.AddCommand(transferCommand(cfg, f))
+	cmd.AddCommand(auditCommand(cfg))
+
+	return cmd
+}
+
+func userCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "user <email|id>",
+		Short: "Show a user",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			withAdmin(cfg, func(ctx context.Context, a *admin.Admin) {
+				user := findUser(ctx, a, args[0])
+
+				out, err := json.MarshalIndent(user, "", "  ")
+				if err != nil {
+					slog.Error("could not encode user", slog.Any("error", err))
+					os.Exit(1)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			})
+		},
+	}
+}
+
+func roleCommand(cfg *config.Config, f *flags, use, short string, role models.UserRole) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <email|id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			withAdmin(cfg, func(ctx context.Context, a *admin.Admin) {
+				user := findUser(ctx, a, args[0])
+				done(cmd, use, a.SetRole(ctx, f.actor, user, role, f.reason))
+			})
+		},
+	}
+}
+
+func userActionCommand(cfg *config.Config,
+	f *flags,
+	use, short string,
+	action func(*admin.Admin, context.Context, string, *models.User, string) error,
+) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <email|id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			withAdmin(cfg, func(ctx context.Context, a *admin.Admin) {
+				user := findUser(ctx, a, args[0])
+				done(cmd, use, action(a, ctx, f.actor, user, f.reason))
+			})
+		},
+	}
+}
+
+func quizActionCommand(cfg *config.Config,
+	f *flags,
+	use, short string,
+	action func(*admin.Admin, context.Context, string, *models.Quiz, string) error,
+) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <quiz id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			withAdmin(cfg, func(ctx context.Context, a *admin.Admin) {
+				quiz := findQuiz(ctx, a, args[0])
+				done(cmd, use, action(a, ctx, f.actor, quiz, f.reason))
+			})
+		},
+	}
+}
+
+func transferCommand(cfg *config.Config, f *flags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "transfer-quiz <quiz id> <email|id>",
+		Short: "Give a quiz to another user",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			withAdmin(cfg, func(ctx context.Context, a *admin.Admin) {
+				quiz := findQuiz(ctx, a, args[0])
+				to := findUser(ctx, a, args[1])
+				done(cmd, "transfer-quiz", a.TransferQuiz(ctx, f.actor, quiz, to, f.reason))
+			})
+		},
+	}
+}
+
+func auditCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit <user or quiz id>",
+		Short: "List the admin actions taken on a user or quiz",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			withAdmin(cfg, func(ctx context.Context, a *admin.Admin) {
+				entries, err := a.AuditLog(ctx, args[0])
+				if err != nil {
+					slog.Error("could not get audit log", slog.Any("error", err))
+					os.Exit(1)
+				}
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TIME\tACTOR\tACTION\tDETAILS")
+				for _, entry := range entries {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+						entry.CreatedAt.Format(time.RFC3339), entry.Actor, entry.Action, entry.Details)
+				}
+				_ = w.Flush()
+			})
+		},
+	}
+}
+
+func withAdmin(cfg *config.Config, fn func(context.Context, *admin.Admin)) {
+	pgdb, err := postgres.NewDB(cfg)
+	if err != nil {
+		slog.Error("could not connect to database", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer pgdb.Close()
+
+	userRepo := postgres.NewUserRepository(pgdb)
+	quizRepo := postgres.NewQuizRepository(pgdb)
+	participantRepo := postgres.NewGameParticipantRepository(pgdb)
+	reportRepo := postgres.NewModerationReportRepository(pgdb)
+	auditRepo := postgres.NewAdminAuditLogRepository(pgdb)
+
+	fn(context.Background(), admin.NewAdmin(userRepo, quizRepo, participantRepo, reportRepo, auditRepo))
+}
+
+func findUser(ctx context.Context, a *admin.Admin, lookup string) *models.User {
+	user, err := a.FindUser(ctx, lookup)
+	if err != nil {
+		slog.Error("could not find user", slog.String("user", lookup), slog.Any("error", err))
+		os.Exit(1)
+	}
+	return user
+}
+
+func findQuiz(ctx context.Context, a *admin.Admin, id string) *models.Quiz {
+	quiz, err := a.FindQuiz(ctx, id)
+	if err != nil {
+		slog.Error("could not find quiz", slog.String("quiz", id), slog.Any("error", err))
+		os.Exit(1)
+	}
+	return quiz
+}
+
+// done reports how an action went, doing nothing because it was already
+// done is not a failure.
+func done(cmd *cobra.Command, action string, err error) {
+	switch {
+	case errors.Is(err, admin.ErrUnchanged):
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: nothing to change\n", action)
+	case err != nil:
+		slog.Error("could not "+action, slog.Any("error", err))
+		os.Exit(1)
+	default:
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: done\n", action)
+	}
+}
+
+func defaultActor() string {
+	current, err := user.Current()
+	if err != nil {
+		return "cli"
+	}
+	return "cli:" + current.Username
+}
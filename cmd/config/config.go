@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/spf13/cobra"
+)
+
+func Command(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+
+	cmd.AddCommand(printCommand(cfg))
+	return cmd
+}
+
+func printCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:         "print",
+		Short:       "Print the effective configuration with secrets redacted",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{config.SkipValidation: "true"},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cfg.Print(cmd.OutOrStdout()); err != nil {
+				slog.Error("could not print config", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			// printed anyway, this is where a broken config gets looked at
+			if err := cfg.Validate(); err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr())
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+			}
+		},
+	}
+}
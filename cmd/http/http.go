@@ -1,43 +1,225 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/oxiginedev/sabipass/cmd/worker"
 	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/analytics"
 	"github.com/oxiginedev/sabipass/internal/api"
+	"github.com/oxiginedev/sabipass/internal/broker"
 	"github.com/oxiginedev/sabipass/internal/database/postgres"
+	"github.com/oxiginedev/sabipass/internal/health"
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/live"
+	"github.com/oxiginedev/sabipass/internal/media"
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/moderation"
 	"github.com/oxiginedev/sabipass/internal/pkg/jwt"
+	"github.com/oxiginedev/sabipass/internal/ratelimit"
+	"github.com/oxiginedev/sabipass/internal/report"
 	"github.com/oxiginedev/sabipass/internal/server"
+	"github.com/oxiginedev/sabipass/internal/storage"
+	"github.com/oxiginedev/sabipass/internal/tracing"
 	"github.com/spf13/cobra"
 )
 
 func Command(cfg *config.Config) *cobra.Command {
-	return &cobra.Command{
-		Use:   "http",
-		Short: "Start the HTTP server",
+	var migrate bool
+
+	cmd := &cobra.Command{
+		Use:         "http",
+		Short:       "Start the HTTP server",
+		Annotations: map[string]string{config.ServerCommand: "true"},
 		Run: func(cmd *cobra.Command, args []string) {
+			shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+			if err != nil {
+				slog.Error("could not set up tracing", slog.Any("error", err))
+				os.Exit(1)
+			}
+
 			pgdb, err := postgres.NewDB(cfg)
 			if err != nil {
 				slog.Error("could not connect to database", slog.Any("error", err))
 				os.Exit(1)
 			}
 
+			if migrate {
+				if err := applyMigrations(pgdb); err != nil {
+					slog.Error("could not apply migrations", slog.Any("error", err))
+					os.Exit(1)
+				}
+			}
+
+			if err := metrics.RegisterDB(pgdb.DB.DB, "postgres"); err != nil {
+				slog.Error("could not register database metrics", slog.Any("error", err))
+				os.Exit(1)
+			}
+
 			userRepo := postgres.NewUserRepository(pgdb)
 			quizRepo := postgres.NewQuizRepository(pgdb)
+			questionRepo := postgres.NewQuestionRepository(pgdb)
 			questionTypeRepo := postgres.NewQuestionTypeRepository(pgdb)
+			uploadRepo := postgres.NewUploadRepository(pgdb)
+			sessionRepo := postgres.NewGameSessionRepository(pgdb)
+			participantRepo := postgres.NewGameParticipantRepository(pgdb)
+			answerRepo := postgres.NewGameAnswerRepository(pgdb)
+			auditRepo := postgres.NewGameAuditLogRepository(pgdb)
+			summaryRepo := postgres.NewGameSummaryRepository(pgdb)
+			analyticsRepo := postgres.NewAnalyticsRepository(pgdb)
+			reportRepo := postgres.NewModerationReportRepository(pgdb)
+			adminAuditRepo := postgres.NewAdminAuditLogRepository(pgdb)
+			jobRepo := postgres.NewJobRepository(pgdb)
 
-			tokenManager := jwt.NewJwtTokenManager(cfg)
-			handler := api.NewAPI(cfg, tokenManager, userRepo, quizRepo, questionTypeRepo)
+			blobStore, err := storage.NewBlobStore(cfg)
+			if err != nil {
+				slog.Error("could not create blob store", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			b, elector, err := broker.New(ctx, cfg, pgdb.DB)
+			if err != nil {
+				slog.Error("could not create broker", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			limiter, err := ratelimit.New(cfg, pgdb.DB)
+			if err != nil {
+				slog.Error("could not create rate limiter", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			// the limits on every request stay in memory, shared buckets
+			// would lock a row on every request of a busy address
+			requestLimiter := ratelimit.NewMemoryLimiter()
+
+			reporter := report.NewReporter(quizRepo, participantRepo, answerRepo, summaryRepo)
+			engine := live.NewEngine(cfg, quizRepo, sessionRepo, participantRepo, answerRepo, auditRepo, reporter, b, elector)
+			go engine.Run(ctx)
+
+			queue := jobs.NewQueue(jobRepo)
+
+			migrator, err := postgres.NewMigrator(pgdb)
+			if err != nil {
+				slog.Error("could not load migrations", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			checks := health.New()
+			checks.Add("postgres", func(ctx context.Context) error {
+				ctx, cancel := pgdb.WithContext(ctx)
+				defer cancel()
+				return pgdb.PingContext(ctx)
+			})
+			checks.Add("migrations", func(ctx context.Context) error {
+				ctx, cancel := pgdb.WithContext(ctx)
+				defer cancel()
+
+				current, err := migrator.Current(ctx)
+				if err != nil {
+					return err
+				}
+				if !current {
+					return errors.New("pending or dirty migrations")
+				}
+				return nil
+			})
+			checks.Add("broker", func(ctx context.Context) error {
+				ctx, cancel := pgdb.WithContext(ctx)
+				defer cancel()
+				return b.Ping(ctx)
+			})
+
+			tokenManager, err := jwt.NewJwtTokenManager(cfg)
+			if err != nil {
+				slog.Error("could not load jwt keys", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			handler := api.NewAPI(cfg, tokenManager, blobStore, queue, engine, reporter, checks, limiter, requestLimiter, userRepo, quizRepo, questionRepo, questionTypeRepo, uploadRepo,
+				sessionRepo, participantRepo, answerRepo, auditRepo, analyticsRepo, reportRepo, adminAuditRepo)
 
 			srv := server.NewServer(cfg, func() {
+				cancel()
+				if err := limiter.Close(); err != nil {
+					slog.Error("could not close rate limiter", slog.Any("error", err))
+				}
+				if err := requestLimiter.Close(); err != nil {
+					slog.Error("could not close rate limiter", slog.Any("error", err))
+				}
+				if err := b.Close(); err != nil {
+					slog.Error("could not close broker", slog.Any("error", err))
+				}
+
 				err := pgdb.Close()
 				if err != nil {
 					slog.Error("could not close database connection", slog.Any("error", err))
 				}
+
+				flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer flushCancel()
+
+				if err := shutdownTracing(flushCtx); err != nil {
+					slog.Error("could not flush traces", slog.Any("error", err))
+				}
 			})
-			srv.SetHandler(handler.RegisterRoutes())
-			srv.Listen()
+			srv.OnStopping(checks.Drain)
+			routes, err := handler.RegisterRoutes()
+			if err != nil {
+				slog.Error("could not register routes", slog.Any("error", err))
+				os.Exit(1)
+			}
+			srv.SetHandler(routes)
+
+			srv.SetAdminHandler(handler.RegisterAdminRoutes())
+
+			if cfg.Jobs.Embedded {
+				roller := analytics.NewRoller(quizRepo, participantRepo, answerRepo, analyticsRepo)
+				thumbnailer := media.NewThumbnailer(blobStore, uploadRepo)
+				moderator := moderation.NewModerator(moderation.NewKeywordClassifier(cfg.Moderation.Keywords), quizRepo, reportRepo)
+
+				w := jobs.NewWorker(cfg, jobRepo)
+				if err := worker.Register(cfg, w, engine, roller, thumbnailer, moderator); err != nil {
+					slog.Error("could not register jobs", slog.Any("error", err))
+					os.Exit(1)
+				}
+
+				w.Start()
+				srv.OnShutdown(w.Drain)
+			}
+
+			stopCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := srv.Listen(stopCtx); err != nil {
+				slog.Error("server stopped with an error", slog.Any("error", err))
+				os.Exit(1)
+			}
 		},
 	}
+
+	cmd.Flags().BoolVar(&migrate, "migrate", false, "Apply pending migrations before starting")
+	return cmd
+}
+
+func applyMigrations(pgdb *postgres.DB) error {
+	migrator, err := postgres.NewMigrator(pgdb)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background(), 0)
+	for _, migration := range applied {
+		slog.Info("applied migration", slog.Uint64("version", migration.Version), slog.String("name", migration.Name))
+	}
+	return err
 }
@@ -4,8 +4,14 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/oxiginedev/sabipass/cmd/admin"
+	configcmd "github.com/oxiginedev/sabipass/cmd/config"
 	"github.com/oxiginedev/sabipass/cmd/http"
+	"github.com/oxiginedev/sabipass/cmd/migrate"
+	"github.com/oxiginedev/sabipass/cmd/seed"
+	"github.com/oxiginedev/sabipass/cmd/worker"
 	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
@@ -27,13 +33,35 @@ func main() {
 				return err
 			}
 
-			return config.Load(envFile, cfg)
+			if err := config.Load(envFile, cfg); err != nil {
+				return err
+			}
+
+			switch {
+			case cmd.Annotations[config.SkipValidation] != "":
+			case cmd.Annotations[config.ServerCommand] != "":
+				if err := cfg.ValidateServer(); err != nil {
+					return err
+				}
+			default:
+				if err := cfg.Validate(); err != nil {
+					return err
+				}
+			}
+
+			slog.SetDefault(logger.New(cfg, os.Stderr))
+			return nil
 		},
 	}
 
 	rootCmd.PersistentFlags().String("env", ".env", "Environment file")
 
 	rootCmd.AddCommand(http.Command(cfg))
+	rootCmd.AddCommand(worker.Command(cfg))
+	rootCmd.AddCommand(migrate.Command(cfg))
+	rootCmd.AddCommand(seed.Command(cfg))
+	rootCmd.AddCommand(admin.Command(cfg))
+	rootCmd.AddCommand(configcmd.Command(cfg))
 
 	err = rootCmd.Execute()
 	if err != nil {
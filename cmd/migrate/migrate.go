@@ -0,0 +1,204 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/database/postgres"
+	"github.com/spf13/cobra"
+)
+
+const migrationsDir = "internal/database/postgres/migrations"
+
+func Command(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database migrations",
+	}
+
+	cmd.AddCommand(upCommand(cfg))
+	cmd.AddCommand(downCommand(cfg))
+	cmd.AddCommand(statusCommand(cfg))
+	cmd.AddCommand(createCommand())
+	cmd.AddCommand(forceCommand(cfg))
+
+	return cmd
+}
+
+func upCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "up [steps]",
+		Short: "Apply pending migrations, all of them unless steps is given",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			steps := parseSteps(args, 0)
+
+			withMigrator(cfg, func(migrator *postgres.Migrator) {
+				applied, err := migrator.Up(context.Background(), steps)
+				for _, migration := range applied {
+					fmt.Fprintf(cmd.OutOrStdout(), "applied %d_%s\n", migration.Version, migration.Name)
+				}
+				if err != nil {
+					slog.Error("could not apply migrations", slog.Any("error", err))
+					os.Exit(1)
+				}
+
+				if len(applied) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no pending migrations")
+				}
+			})
+		},
+	}
+}
+
+func downCommand(cfg *config.Config) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "down [steps]",
+		Short: "Roll back the last migration, or the last steps of them",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			steps := parseSteps(args, 1)
+			if all {
+				steps = 0
+			}
+
+			withMigrator(cfg, func(migrator *postgres.Migrator) {
+				rolledBack, err := migrator.Down(context.Background(), steps)
+				for _, migration := range rolledBack {
+					fmt.Fprintf(cmd.OutOrStdout(), "rolled back %d_%s\n", migration.Version, migration.Name)
+				}
+				if err != nil {
+					slog.Error("could not roll back migrations", slog.Any("error", err))
+					os.Exit(1)
+				}
+
+				if len(rolledBack) == 0 {
+					fmt.Fprintln(cmd.OutOrStdout(), "no applied migrations")
+				}
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Roll back every migration")
+	return cmd
+}
+
+func statusCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and whether they are applied",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			withMigrator(cfg, func(migrator *postgres.Migrator) {
+				statuses, version, dirty, err := migrator.Status(context.Background())
+				if err != nil {
+					slog.Error("could not get migration status", slog.Any("error", err))
+					os.Exit(1)
+				}
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+				for _, status := range statuses {
+					state := "pending"
+					if status.Applied {
+						state = "applied"
+					}
+					if dirty && status.Version == version {
+						state = "dirty"
+					}
+					fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, state)
+				}
+				if err := w.Flush(); err != nil {
+					slog.Error("could not print migration status", slog.Any("error", err))
+					os.Exit(1)
+				}
+			})
+		},
+	}
+}
+
+func createCommand() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:         "create <name>",
+		Short:       "Create the up and down files of a new migration",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{config.SkipValidation: "true"},
+		Run: func(cmd *cobra.Command, args []string) {
+			up, down, err := postgres.CreateMigration(dir, args[0], time.Now())
+			if err != nil {
+				slog.Error("could not create migration", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), up)
+			fmt.Fprintln(cmd.OutOrStdout(), down)
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", migrationsDir, "Directory to create the migration in")
+	return cmd
+}
+
+func forceCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "force <version>",
+		Short: "Set the migration version and clear the dirty flag without running migrations",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			version, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				slog.Error("the version must be a migration version or 0", slog.String("version", args[0]))
+				os.Exit(1)
+			}
+
+			withMigrator(cfg, func(migrator *postgres.Migrator) {
+				if err := migrator.Force(context.Background(), version); err != nil {
+					slog.Error("could not force migration version", slog.Any("error", err))
+					os.Exit(1)
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "forced version %d\n", version)
+			})
+		},
+	}
+}
+
+func withMigrator(cfg *config.Config, fn func(*postgres.Migrator)) {
+	pgdb, err := postgres.NewDB(cfg)
+	if err != nil {
+		slog.Error("could not connect to database", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer pgdb.Close()
+
+	migrator, err := postgres.NewMigrator(pgdb)
+	if err != nil {
+		slog.Error("could not load migrations", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	fn(migrator)
+}
+
+func parseSteps(args []string, fallback int) int {
+	if len(args) == 0 {
+		return fallback
+	}
+
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		slog.Error("steps must be a positive number", slog.String("steps", args[0]))
+		os.Exit(1)
+	}
+	return steps
+}
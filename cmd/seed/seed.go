@@ -0,0 +1,79 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/database/postgres"
+	"github.com/oxiginedev/sabipass/internal/report"
+	"github.com/oxiginedev/sabipass/internal/seed"
+	"github.com/spf13/cobra"
+)
+
+func Command(cfg *config.Config) *cobra.Command {
+	var (
+		demo bool
+		opts seed.DemoOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Seed the question types, and demo data with --demo",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			pgdb, err := postgres.NewDB(cfg)
+			if err != nil {
+				slog.Error("could not connect to database", slog.Any("error", err))
+				os.Exit(1)
+			}
+			defer pgdb.Close()
+
+			quizRepo := postgres.NewQuizRepository(pgdb)
+			participantRepo := postgres.NewGameParticipantRepository(pgdb)
+			answerRepo := postgres.NewGameAnswerRepository(pgdb)
+			summaryRepo := postgres.NewGameSummaryRepository(pgdb)
+
+			reporter := report.NewReporter(quizRepo, participantRepo, answerRepo, summaryRepo)
+			seeder := seed.NewSeeder(pgdb.DB, reporter)
+
+			ctx := context.Background()
+
+			inserted, err := seeder.QuestionTypes(ctx)
+			if err != nil {
+				slog.Error("could not seed question types", slog.Any("error", err))
+				os.Exit(1)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "seeded %d question types\n", inserted)
+
+			if !demo {
+				return
+			}
+
+			if opts.Users < 0 || opts.QuizzesPerUser < 0 || opts.SessionsPerQuiz < 0 || opts.PlayersPerSession < 0 {
+				slog.Error("demo counts cannot be negative")
+				os.Exit(1)
+			}
+
+			result, err := seeder.Demo(ctx, &opts)
+			if err != nil {
+				slog.Error("could not seed demo data", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "seeded %d users, %d quizzes, %d sessions and %d answers\n",
+				result.Users, result.Quizzes, result.Sessions, result.Answers)
+		},
+	}
+
+	cmd.Flags().BoolVar(&demo, "demo", false, "Also generate demo users, quizzes and finished games")
+	cmd.Flags().IntVar(&opts.Users, "users", 10, "Number of demo users")
+	cmd.Flags().IntVar(&opts.QuizzesPerUser, "quizzes", 2, "Number of quizzes per demo user")
+	cmd.Flags().IntVar(&opts.SessionsPerQuiz, "sessions", 3, "Number of finished games per demo quiz")
+	cmd.Flags().IntVar(&opts.PlayersPerSession, "players", 8, "Number of players per demo game")
+	cmd.Flags().Uint64Var(&opts.Seed, "random-seed", 1, "Seed for the random picks of demo players, the same seed plays the same way")
+
+	return cmd
+}
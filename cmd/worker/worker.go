@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/analytics"
+	"github.com/oxiginedev/sabipass/internal/broker"
+	"github.com/oxiginedev/sabipass/internal/database/postgres"
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/live"
+	"github.com/oxiginedev/sabipass/internal/media"
+	"github.com/oxiginedev/sabipass/internal/moderation"
+	"github.com/oxiginedev/sabipass/internal/report"
+	"github.com/oxiginedev/sabipass/internal/server"
+	"github.com/oxiginedev/sabipass/internal/storage"
+	"github.com/oxiginedev/sabipass/internal/tracing"
+	"github.com/spf13/cobra"
+)
+
+func Command(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "worker",
+		Short: "Run background jobs",
+		Run: func(cmd *cobra.Command, args []string) {
+			shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+			if err != nil {
+				slog.Error("could not set up tracing", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			pgdb, err := postgres.NewDB(cfg)
+			if err != nil {
+				slog.Error("could not connect to database", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			quizRepo := postgres.NewQuizRepository(pgdb)
+			uploadRepo := postgres.NewUploadRepository(pgdb)
+			sessionRepo := postgres.NewGameSessionRepository(pgdb)
+			participantRepo := postgres.NewGameParticipantRepository(pgdb)
+			answerRepo := postgres.NewGameAnswerRepository(pgdb)
+			auditRepo := postgres.NewGameAuditLogRepository(pgdb)
+			summaryRepo := postgres.NewGameSummaryRepository(pgdb)
+			analyticsRepo := postgres.NewAnalyticsRepository(pgdb)
+			reportRepo := postgres.NewModerationReportRepository(pgdb)
+			jobRepo := postgres.NewJobRepository(pgdb)
+
+			blobStore, err := storage.NewBlobStore(cfg)
+			if err != nil {
+				slog.Error("could not create blob store", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// ending abandoned games goes through the engine, which tells
+			// the http instances about it through the broker
+			b, elector, err := broker.New(ctx, cfg, pgdb.DB)
+			if err != nil {
+				slog.Error("could not create broker", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			reporter := report.NewReporter(quizRepo, participantRepo, answerRepo, summaryRepo)
+			engine := live.NewEngine(cfg, quizRepo, sessionRepo, participantRepo, answerRepo, auditRepo, reporter, b, elector)
+			roller := analytics.NewRoller(quizRepo, participantRepo, answerRepo, analyticsRepo)
+			thumbnailer := media.NewThumbnailer(blobStore, uploadRepo)
+			moderator := moderation.NewModerator(moderation.NewKeywordClassifier(cfg.Moderation.Keywords), quizRepo, reportRepo)
+
+			w := jobs.NewWorker(cfg, jobRepo)
+			if err := Register(cfg, w, engine, roller, thumbnailer, moderator); err != nil {
+				slog.Error("could not register jobs", slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			w.Start()
+			slog.Info("worker started")
+
+			server.WaitForSignal()
+			slog.Info("draining worker...")
+
+			drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Jobs.DrainTimeout)
+			defer drainCancel()
+
+			if err := w.Drain(drainCtx); err != nil {
+				slog.Error("worker forced to stop", slog.Any("error", err))
+			}
+
+			cancel()
+			if err := b.Close(); err != nil {
+				slog.Error("could not close broker", slog.Any("error", err))
+			}
+
+			if err := pgdb.Close(); err != nil {
+				slog.Error("could not close database connection", slog.Any("error", err))
+			}
+
+			flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer flushCancel()
+
+			if err := shutdownTracing(flushCtx); err != nil {
+				slog.Error("could not flush traces", slog.Any("error", err))
+			}
+
+			slog.Info("worker exited properly")
+		},
+	}
+}
+
+// Register sets up the handlers and schedules of every background job on
+// w. Both sabipass worker and an http server running an embedded worker
+// use it, so they run the same jobs.
+func Register(cfg *config.Config,
+	w *jobs.Worker,
+	engine *live.Engine,
+	roller *analytics.Roller,
+	thumbnailer *media.Thumbnailer,
+	moderator *moderation.Moderator,
+) error {
+	analytics.RollupJob.Handle(w, roller.HandleRollup)
+	live.CleanupJob.Handle(w, engine.HandleCleanup)
+	media.ThumbnailJob.Handle(w, thumbnailer.HandleThumbnail)
+	moderation.ClassifyJob.Handle(w, moderator.HandleClassify)
+
+	if err := analytics.RollupJob.Schedule(w, cfg.Analytics.Schedule, analytics.RollupArgs{}); err != nil {
+		return err
+	}
+
+	return live.CleanupJob.Schedule(w, cfg.Live.CleanupSchedule, live.CleanupArgs{})
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"log/slog"
 	"os"
 	"time"
 
@@ -12,15 +13,95 @@ import (
 // ENUM(production, local)
 type Environment string
 
+// ENUM(local, s3)
+type StorageDriver string
+
+// ENUM(memory, postgres)
+type BrokerDriver string
+
+// ENUM(text, json)
+type LogFormat string
+
+// ENUM(memory, postgres)
+type RateLimitDriver string
+
+// ENUM(none, otlp, stdout)
+type TracingExporter string
+
+// Config is read from the environment. Fields tagged secret can be read
+// from the file named by the same variable suffixed with _FILE instead,
+// and are left out when the config is printed.
 type Config struct {
-	Environment Environment
+	Environment Environment `envconfig:"SABIPASS_ENVIRONMENT" default:"production"`
 	HTTP        struct {
-		Port uint16 `default:"8000"`
+		Port uint16 `envconfig:"SABIPASS_HTTP_PORT" default:"8000"`
+		// AdminPort serves the metrics and health probes, apart from the API
+		// so they are not exposed to the public. 0 turns it off.
+		AdminPort uint16 `envconfig:"SABIPASS_HTTP_ADMIN_PORT" default:"9000"`
+		// ReadTimeout and WriteTimeout bound plain requests, live game
+		// sockets manage their own deadlines once upgraded.
+		ReadTimeout       time.Duration `envconfig:"SABIPASS_HTTP_READ_TIMEOUT" default:"10s"`
+		ReadHeaderTimeout time.Duration `envconfig:"SABIPASS_HTTP_READ_HEADER_TIMEOUT" default:"2s"`
+		WriteTimeout      time.Duration `envconfig:"SABIPASS_HTTP_WRITE_TIMEOUT" default:"10s"`
+		IdleTimeout       time.Duration `envconfig:"SABIPASS_HTTP_IDLE_TIMEOUT" default:"2m"`
+		// ShutdownTimeout is how long requests in flight get to finish once
+		// the server stops taking new ones.
+		ShutdownTimeout time.Duration `envconfig:"SABIPASS_HTTP_SHUTDOWN_TIMEOUT" default:"5s"`
+		// ShutdownDelay is how long the server keeps serving after it starts
+		// reporting not ready, for load balancers to notice and stop routing
+		// to it.
+		ShutdownDelay time.Duration `envconfig:"SABIPASS_HTTP_SHUTDOWN_DELAY" default:"5s"`
+		// TrustedProxies are the CIDRs of the proxies allowed to tell the
+		// client address in X-Forwarded-For, none are trusted by default.
+		TrustedProxies []string `envconfig:"SABIPASS_HTTP_TRUSTED_PROXIES"`
+		// HSTSMaxAge is sent in production, where the API is expected to be
+		// served over TLS. 0 leaves the header out.
+		HSTSMaxAge time.Duration `envconfig:"SABIPASS_HTTP_HSTS_MAX_AGE" default:"8760h"`
+		// TLS serves the API over https, with HTTP/2, when both files are
+		// set. They are read again on SIGHUP so certificates can be renewed
+		// without a restart.
+		TLS struct {
+			CertFile string `envconfig:"SABIPASS_HTTP_TLS_CERT_FILE"`
+			KeyFile  string `envconfig:"SABIPASS_HTTP_TLS_KEY_FILE"`
+		}
+		// H2C serves HTTP/2 without TLS, for proxies that terminate TLS and
+		// speak HTTP/2 to the API.
+		H2C bool `envconfig:"SABIPASS_HTTP_H2C" default:"false"`
+	}
+
+	// CORS lets the web app call the API from its own origin. Locally every
+	// origin is allowed when none is set.
+	CORS struct {
+		AllowedOrigins   []string      `envconfig:"SABIPASS_CORS_ALLOWED_ORIGINS"`
+		AllowedMethods   []string      `envconfig:"SABIPASS_CORS_ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE"`
+		AllowedHeaders   []string      `envconfig:"SABIPASS_CORS_ALLOWED_HEADERS" default:"Authorization,Content-Type,X-Request-ID"`
+		AllowCredentials bool          `envconfig:"SABIPASS_CORS_ALLOW_CREDENTIALS" default:"false"`
+		MaxAge           time.Duration `envconfig:"SABIPASS_CORS_MAX_AGE" default:"10m"`
+	}
+
+	Log struct {
+		Level  slog.Level `envconfig:"SABIPASS_LOG_LEVEL" default:"info"`
+		Format LogFormat  `envconfig:"SABIPASS_LOG_FORMAT" default:"text"`
+		// SampleRate is the share of successful requests that get logged,
+		// from 0 to 1. Failed requests are always logged.
+		SampleRate float64 `envconfig:"SABIPASS_LOG_SAMPLE_RATE" default:"1"`
+	}
+
+	// Tracing sends spans to an OTLP collector over HTTP, or prints them
+	// with the stdout exporter when debugging locally.
+	Tracing struct {
+		Exporter     TracingExporter `envconfig:"SABIPASS_TRACING_EXPORTER" default:"none"`
+		ServiceName  string          `envconfig:"SABIPASS_TRACING_SERVICE_NAME" default:"sabipass"`
+		OTLPEndpoint string          `envconfig:"SABIPASS_TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+		OTLPInsecure bool            `envconfig:"SABIPASS_TRACING_OTLP_INSECURE"`
+		// SampleRatio is the share of traces that are kept, from 0 to 1.
+		// Traces started by another service keep that service's decision.
+		SampleRatio float64 `envconfig:"SABIPASS_TRACING_SAMPLE_RATIO" default:"1"`
 	}
 
 	Database struct {
 		Postgres struct {
-			DSN          string        `envconfig:"SABIPASS_POSTGRES_DSN"`
+			DSN          string        `envconfig:"SABIPASS_POSTGRES_DSN" secret:"true"`
 			QueryTimeout time.Duration `envconfig:"SABIPASS_POSTGRES_QUERY_TIMEOUT" default:"5s"`
 		}
 	}
@@ -28,15 +109,106 @@ type Config struct {
 	Oauth struct {
 		Google struct {
 			ClientID     string `envconfig:"SABIPASS_GOOGLE_CLIENT_ID"`
-			ClientSecret string `envconfig:"SABIPASS_GOOGLE_CLIENT_SECRET"`
+			ClientSecret string `envconfig:"SABIPASS_GOOGLE_CLIENT_SECRET" secret:"true"`
 			RedirectURL  string `envconfig:"SABIPASS_GOOGLE_REDIRECT_URL"`
 		}
 	}
 
+	Storage struct {
+		Driver StorageDriver `envconfig:"SABIPASS_STORAGE_DRIVER" default:"local"`
+		Local  struct {
+			Path    string `envconfig:"SABIPASS_STORAGE_LOCAL_PATH" default:"storage"`
+			BaseURL string `envconfig:"SABIPASS_STORAGE_LOCAL_BASE_URL" default:"http://localhost:8000/media"`
+		}
+		S3 struct {
+			Endpoint        string `envconfig:"SABIPASS_S3_ENDPOINT"`
+			Region          string `envconfig:"SABIPASS_S3_REGION" default:"us-east-1"`
+			Bucket          string `envconfig:"SABIPASS_S3_BUCKET"`
+			AccessKeyID     string `envconfig:"SABIPASS_S3_ACCESS_KEY_ID"`
+			SecretAccessKey string `envconfig:"SABIPASS_S3_SECRET_ACCESS_KEY" secret:"true"`
+			PublicURL       string `envconfig:"SABIPASS_S3_PUBLIC_URL"`
+			UsePathStyle    bool   `envconfig:"SABIPASS_S3_USE_PATH_STYLE"`
+		}
+		MaxImageSize int64 `envconfig:"SABIPASS_STORAGE_MAX_IMAGE_SIZE" default:"5242880"`
+		MaxAudioSize int64 `envconfig:"SABIPASS_STORAGE_MAX_AUDIO_SIZE" default:"15728640"`
+	}
+
+	// Broker carries live game updates between instances, memory only
+	// works when running a single instance.
+	Broker struct {
+		Driver BrokerDriver `envconfig:"SABIPASS_BROKER_DRIVER" default:"memory"`
+	}
+
+	// RateLimit limits are in requests per minute, 0 turns a limit off. The
+	// driver holds the auth, join code and answer limits, the memory driver
+	// keeps separate limits on every instance. The IP and user limits are
+	// always kept by each instance, as they apply to every request.
+	RateLimit struct {
+		Driver RateLimitDriver `envconfig:"SABIPASS_RATE_LIMIT_DRIVER" default:"memory"`
+		// IP applies to every request from an address, on each instance.
+		IP int `envconfig:"SABIPASS_RATE_LIMIT_IP" default:"600"`
+		// User applies to every request of a signed in user, on each
+		// instance.
+		User int `envconfig:"SABIPASS_RATE_LIMIT_USER" default:"300"`
+		// Auth applies to each sign in route, by address.
+		Auth int `envconfig:"SABIPASS_RATE_LIMIT_AUTH" default:"10"`
+		// JoinCode counts the game and challenge codes an address gets
+		// wrong, to stop codes being guessed.
+		JoinCode int `envconfig:"SABIPASS_RATE_LIMIT_JOIN_CODE" default:"10"`
+		// Answers applies to the answers a player sends over a live game
+		// socket.
+		Answers int `envconfig:"SABIPASS_RATE_LIMIT_ANSWERS" default:"30"`
+	}
+
+	Live struct {
+		// HostGracePeriod is how long a live game keeps running after its
+		// host disconnects before it pauses.
+		HostGracePeriod time.Duration `envconfig:"SABIPASS_LIVE_HOST_GRACE_PERIOD" default:"30s"`
+		// AbandonAfter is how long a live game goes without its host before
+		// the cleanup job ends it.
+		AbandonAfter    time.Duration `envconfig:"SABIPASS_LIVE_ABANDON_AFTER" default:"24h"`
+		CleanupSchedule string        `envconfig:"SABIPASS_LIVE_CLEANUP_SCHEDULE" default:"0 * * * *"`
+	}
+
+	Analytics struct {
+		// Schedule is the cron spec finished sessions are rolled up into the
+		// statistics of their quizzes on.
+		Schedule string `envconfig:"SABIPASS_ANALYTICS_SCHEDULE" default:"* * * * *"`
+	}
+
+	Jobs struct {
+		Concurrency  int           `envconfig:"SABIPASS_JOBS_CONCURRENCY" default:"10"`
+		PollInterval time.Duration `envconfig:"SABIPASS_JOBS_POLL_INTERVAL" default:"1s"`
+		// DrainTimeout is how long running jobs get to finish on shutdown
+		// before they are stopped and put back in the queue.
+		DrainTimeout time.Duration `envconfig:"SABIPASS_JOBS_DRAIN_TIMEOUT" default:"30s"`
+		// Embedded runs a worker inside the http server as well, so a single
+		// instance needs no separate sabipass worker.
+		Embedded bool `envconfig:"SABIPASS_JOBS_EMBEDDED"`
+	}
+
+	Moderation struct {
+		// Keywords flag new public quizzes for review when their text
+		// contains any of them, comma separated.
+		Keywords []string `envconfig:"SABIPASS_MODERATION_KEYWORDS"`
+	}
+
 	Auth struct {
+		// JWT tokens are signed with SigningKey when it is set, RS256 for an
+		// RSA key and EdDSA for an Ed25519 one, and with SecretKey using
+		// HS256 otherwise. Tokens signed with SecretKey are still accepted
+		// after moving to a signing key, as long as it stays set.
 		JWT struct {
-			SecretKey string
-			Expiry    time.Duration `default:"1h"`
+			SecretKey string `envconfig:"SABIPASS_AUTH_JWT_SECRET_KEY" secret:"true"`
+			// SigningKey is a PEM encoded private key.
+			SigningKey string `envconfig:"SABIPASS_AUTH_JWT_SIGNING_KEY" secret:"true"`
+			// VerificationKeyFiles are PEM encoded public keys of retired
+			// signing keys, the tokens they signed stay valid until they
+			// expire. They are published with the signing key.
+			VerificationKeyFiles []string      `envconfig:"SABIPASS_AUTH_JWT_VERIFICATION_KEY_FILES"`
+			Issuer               string        `envconfig:"SABIPASS_AUTH_JWT_ISSUER" default:"sabipass"`
+			Audience             string        `envconfig:"SABIPASS_AUTH_JWT_AUDIENCE" default:"sabipass"`
+			Expiry               time.Duration `envconfig:"SABIPASS_AUTH_JWT_EXPIRY" default:"1h"`
 		}
 	}
 }
@@ -53,5 +225,9 @@ func Load(pathToFile string, cfg *Config) error {
 		return err
 	}
 
-	return envconfig.Process("SABIPASS", cfg)
+	if err := envconfig.Process("SABIPASS", cfg); err != nil {
+		return err
+	}
+
+	return readSecretFiles(cfg)
 }
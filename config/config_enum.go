@@ -10,6 +10,40 @@ import (
 	"fmt"
 )
 
+const (
+	// BrokerDriverMemory is a BrokerDriver of type memory.
+	BrokerDriverMemory BrokerDriver = "memory"
+	// BrokerDriverPostgres is a BrokerDriver of type postgres.
+	BrokerDriverPostgres BrokerDriver = "postgres"
+)
+
+var ErrInvalidBrokerDriver = errors.New("not a valid BrokerDriver")
+
+// String implements the Stringer interface.
+func (x BrokerDriver) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x BrokerDriver) IsValid() bool {
+	_, err := ParseBrokerDriver(string(x))
+	return err == nil
+}
+
+var _BrokerDriverValue = map[string]BrokerDriver{
+	"memory":   BrokerDriverMemory,
+	"postgres": BrokerDriverPostgres,
+}
+
+// ParseBrokerDriver attempts to convert a string to a BrokerDriver.
+func ParseBrokerDriver(name string) (BrokerDriver, error) {
+	if x, ok := _BrokerDriverValue[name]; ok {
+		return x, nil
+	}
+	return BrokerDriver(""), fmt.Errorf("%s is %w", name, ErrInvalidBrokerDriver)
+}
+
 const (
 	// EnvironmentProduction is a Environment of type production.
 	EnvironmentProduction Environment = "production"
@@ -43,3 +77,142 @@ func ParseEnvironment(name string) (Environment, error) {
 	}
 	return Environment(""), fmt.Errorf("%s is %w", name, ErrInvalidEnvironment)
 }
+
+const (
+	// LogFormatText is a LogFormat of type text.
+	LogFormatText LogFormat = "text"
+	// LogFormatJson is a LogFormat of type json.
+	LogFormatJson LogFormat = "json"
+)
+
+var ErrInvalidLogFormat = errors.New("not a valid LogFormat")
+
+// String implements the Stringer interface.
+func (x LogFormat) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x LogFormat) IsValid() bool {
+	_, err := ParseLogFormat(string(x))
+	return err == nil
+}
+
+var _LogFormatValue = map[string]LogFormat{
+	"text": LogFormatText,
+	"json": LogFormatJson,
+}
+
+// ParseLogFormat attempts to convert a string to a LogFormat.
+func ParseLogFormat(name string) (LogFormat, error) {
+	if x, ok := _LogFormatValue[name]; ok {
+		return x, nil
+	}
+	return LogFormat(""), fmt.Errorf("%s is %w", name, ErrInvalidLogFormat)
+}
+
+const (
+	// RateLimitDriverMemory is a RateLimitDriver of type memory.
+	RateLimitDriverMemory RateLimitDriver = "memory"
+	// RateLimitDriverPostgres is a RateLimitDriver of type postgres.
+	RateLimitDriverPostgres RateLimitDriver = "postgres"
+)
+
+var ErrInvalidRateLimitDriver = errors.New("not a valid RateLimitDriver")
+
+// String implements the Stringer interface.
+func (x RateLimitDriver) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x RateLimitDriver) IsValid() bool {
+	_, err := ParseRateLimitDriver(string(x))
+	return err == nil
+}
+
+var _RateLimitDriverValue = map[string]RateLimitDriver{
+	"memory":   RateLimitDriverMemory,
+	"postgres": RateLimitDriverPostgres,
+}
+
+// ParseRateLimitDriver attempts to convert a string to a RateLimitDriver.
+func ParseRateLimitDriver(name string) (RateLimitDriver, error) {
+	if x, ok := _RateLimitDriverValue[name]; ok {
+		return x, nil
+	}
+	return RateLimitDriver(""), fmt.Errorf("%s is %w", name, ErrInvalidRateLimitDriver)
+}
+
+const (
+	// StorageDriverLocal is a StorageDriver of type local.
+	StorageDriverLocal StorageDriver = "local"
+	// StorageDriverS3 is a StorageDriver of type s3.
+	StorageDriverS3 StorageDriver = "s3"
+)
+
+var ErrInvalidStorageDriver = errors.New("not a valid StorageDriver")
+
+// String implements the Stringer interface.
+func (x StorageDriver) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x StorageDriver) IsValid() bool {
+	_, err := ParseStorageDriver(string(x))
+	return err == nil
+}
+
+var _StorageDriverValue = map[string]StorageDriver{
+	"local": StorageDriverLocal,
+	"s3":    StorageDriverS3,
+}
+
+// ParseStorageDriver attempts to convert a string to a StorageDriver.
+func ParseStorageDriver(name string) (StorageDriver, error) {
+	if x, ok := _StorageDriverValue[name]; ok {
+		return x, nil
+	}
+	return StorageDriver(""), fmt.Errorf("%s is %w", name, ErrInvalidStorageDriver)
+}
+
+const (
+	// TracingExporterNone is a TracingExporter of type none.
+	TracingExporterNone TracingExporter = "none"
+	// TracingExporterOtlp is a TracingExporter of type otlp.
+	TracingExporterOtlp TracingExporter = "otlp"
+	// TracingExporterStdout is a TracingExporter of type stdout.
+	TracingExporterStdout TracingExporter = "stdout"
+)
+
+var ErrInvalidTracingExporter = errors.New("not a valid TracingExporter")
+
+// String implements the Stringer interface.
+func (x TracingExporter) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x TracingExporter) IsValid() bool {
+	_, err := ParseTracingExporter(string(x))
+	return err == nil
+}
+
+var _TracingExporterValue = map[string]TracingExporter{
+	"none":   TracingExporterNone,
+	"otlp":   TracingExporterOtlp,
+	"stdout": TracingExporterStdout,
+}
+
+// ParseTracingExporter attempts to convert a string to a TracingExporter.
+func ParseTracingExporter(name string) (TracingExporter, error) {
+	if x, ok := _TracingExporterValue[name]; ok {
+		return x, nil
+	}
+	return TracingExporter(""), fmt.Errorf("%s is %w", name, ErrInvalidTracingExporter)
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// setting is a config field along with the variable it is read from.
+type setting struct {
+	name   string
+	value  reflect.Value
+	secret bool
+}
+
+// settings lists the fields of v, nested structs flattened, in the order
+// they are declared.
+func settings(v reflect.Value) []setting {
+	var out []setting
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("envconfig")
+
+		if field.Type.Kind() == reflect.Struct && name == "" {
+			out = append(out, settings(v.Field(i))...)
+			continue
+		}
+
+		out = append(out, setting{
+			name:   name,
+			value:  v.Field(i),
+			secret: field.Tag.Get("secret") == "true",
+		})
+	}
+
+	return out
+}
+
+// readSecretFiles sets every secret whose _FILE variable is set to the
+// content of that file, so secrets mounted by an orchestrator never have
+// to go through the environment.
+func readSecretFiles(cfg *Config) error {
+	for _, s := range settings(reflect.ValueOf(cfg).Elem()) {
+		if !s.secret {
+			continue
+		}
+
+		path := os.Getenv(s.name + "_FILE")
+		if path == "" {
+			continue
+		}
+
+		if os.Getenv(s.name) != "" {
+			return fmt.Errorf("config: set either %s or %s_FILE, not both", s.name, s.name)
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("config: could not read %s_FILE: %w", s.name, err)
+		}
+
+		s.value.SetString(strings.TrimRight(string(raw), "\r\n"))
+	}
+
+	return nil
+}
+
+// Print writes the config as the variables it is read from, in the
+// format of an env file. Secrets that are set are redacted.
+func (c *Config) Print(w io.Writer) error {
+	for _, s := range settings(reflect.ValueOf(c).Elem()) {
+		value := formatValue(s.value)
+		if s.secret && value != "" {
+			value = redacted
+		}
+
+		if strings.ContainsAny(value, " #\"'") {
+			value = strconv.Quote(value)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s=%s\n", s.name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() != reflect.Slice {
+		return fmt.Sprint(v.Interface())
+	}
+
+	items := make([]string, v.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(items, ",")
+}
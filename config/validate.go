@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	// SkipValidation is the cobra annotation of commands that run without
+	// a valid config.
+	SkipValidation = "skip-config-validation"
+
+	// ServerCommand is the cobra annotation of commands that serve the API,
+	// their config is checked with Config.ValidateServer.
+	ServerCommand = "server-command"
+
+	// minProductionSecretSize is the shortest JWT secret accepted in
+	// production, the size of an HS256 key.
+	minProductionSecretSize = 32
+)
+
+// Validate reports every setting that keeps any sabipass command from
+// running, or from running safely in the configured environment.
+func (c *Config) Validate() error {
+	v := &validator{}
+	c.validate(v)
+	return v.err()
+}
+
+// ValidateServer reports what Validate does along with the auth and HTTP
+// settings, which only the API server uses.
+func (c *Config) ValidateServer() error {
+	v := &validator{}
+	c.validate(v)
+	c.validateServer(v)
+	return v.err()
+}
+
+func (c *Config) validate(v *validator) {
+	v.oneOf("SABIPASS_ENVIRONMENT", c.Environment.IsValid(), c.Environment.String(),
+		EnvironmentProduction.String(), EnvironmentLocal.String())
+	v.oneOf("SABIPASS_LOG_FORMAT", c.Log.Format.IsValid(), c.Log.Format.String(),
+		LogFormatText.String(), LogFormatJson.String())
+	v.oneOf("SABIPASS_TRACING_EXPORTER", c.Tracing.Exporter.IsValid(), c.Tracing.Exporter.String(),
+		TracingExporterNone.String(), TracingExporterOtlp.String(), TracingExporterStdout.String())
+	v.oneOf("SABIPASS_STORAGE_DRIVER", c.Storage.Driver.IsValid(), c.Storage.Driver.String(),
+		StorageDriverLocal.String(), StorageDriverS3.String())
+	v.oneOf("SABIPASS_BROKER_DRIVER", c.Broker.Driver.IsValid(), c.Broker.Driver.String(),
+		BrokerDriverMemory.String(), BrokerDriverPostgres.String())
+	v.oneOf("SABIPASS_RATE_LIMIT_DRIVER", c.RateLimit.Driver.IsValid(), c.RateLimit.Driver.String(),
+		RateLimitDriverMemory.String(), RateLimitDriverPostgres.String())
+
+	v.check(c.Database.Postgres.DSN != "", "SABIPASS_POSTGRES_DSN is required")
+	v.check(c.Database.Postgres.QueryTimeout > 0, "SABIPASS_POSTGRES_QUERY_TIMEOUT must be positive")
+
+	v.check(c.Log.SampleRate >= 0 && c.Log.SampleRate <= 1, "SABIPASS_LOG_SAMPLE_RATE must be between 0 and 1")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "SABIPASS_TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	v.check(c.Jobs.Concurrency > 0, "SABIPASS_JOBS_CONCURRENCY must be positive")
+	v.check(c.Jobs.PollInterval > 0, "SABIPASS_JOBS_POLL_INTERVAL must be positive")
+	v.check(c.Jobs.DrainTimeout > 0, "SABIPASS_JOBS_DRAIN_TIMEOUT must be positive")
+
+	if c.Storage.Driver == StorageDriverS3 {
+		endpoint, err := url.Parse(c.Storage.S3.Endpoint)
+		v.check(err == nil && (endpoint.Scheme == "http" || endpoint.Scheme == "https") && endpoint.Host != "",
+			"SABIPASS_S3_ENDPOINT must be an http or https url with the s3 storage driver, got %q", c.Storage.S3.Endpoint)
+		v.check(c.Storage.S3.Bucket != "", "SABIPASS_S3_BUCKET is required with the s3 storage driver")
+		v.check(c.Storage.S3.AccessKeyID != "", "SABIPASS_S3_ACCESS_KEY_ID is required with the s3 storage driver")
+		v.check(c.Storage.S3.SecretAccessKey != "", "SABIPASS_S3_SECRET_ACCESS_KEY is required with the s3 storage driver")
+	}
+}
+
+func (c *Config) validateServer(v *validator) {
+	v.check(c.Auth.JWT.SecretKey != "" || c.Auth.JWT.SigningKey != "",
+		"SABIPASS_AUTH_JWT_SIGNING_KEY or SABIPASS_AUTH_JWT_SECRET_KEY is required, tokens would be signed with an empty key")
+	v.check(c.Auth.JWT.Issuer != "", "SABIPASS_AUTH_JWT_ISSUER is required")
+	v.check(c.Auth.JWT.Audience != "", "SABIPASS_AUTH_JWT_AUDIENCE is required")
+	v.check(c.Auth.JWT.Expiry > 0, "SABIPASS_AUTH_JWT_EXPIRY must be positive")
+
+	v.check((c.HTTP.TLS.CertFile == "") == (c.HTTP.TLS.KeyFile == ""),
+		"SABIPASS_HTTP_TLS_CERT_FILE and SABIPASS_HTTP_TLS_KEY_FILE must be set together")
+	v.check(c.HTTP.ShutdownTimeout > 0, "SABIPASS_HTTP_SHUTDOWN_TIMEOUT must be positive")
+
+	v.check(!c.CORS.AllowCredentials || !slices.Contains(c.CORS.AllowedOrigins, "*"),
+		"SABIPASS_CORS_ALLOWED_ORIGINS cannot be * when SABIPASS_CORS_ALLOW_CREDENTIALS is on, any site could act for signed in users")
+
+	if c.Environment == EnvironmentProduction {
+		v.check(c.Auth.JWT.SecretKey == "" || len(c.Auth.JWT.SecretKey) >= minProductionSecretSize,
+			"SABIPASS_AUTH_JWT_SECRET_KEY must be at least %d bytes in production", minProductionSecretSize)
+		v.check(c.Oauth.Google.ClientID != "", "SABIPASS_GOOGLE_CLIENT_ID is required in production")
+		v.check(c.Oauth.Google.ClientSecret != "", "SABIPASS_GOOGLE_CLIENT_SECRET is required in production")
+		v.check(c.Oauth.Google.RedirectURL != "", "SABIPASS_GOOGLE_REDIRECT_URL is required in production")
+	}
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *validator) oneOf(name string, ok bool, value string, allowed ...string) {
+	v.check(ok, "%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("config: invalid configuration:\n%w", errors.Join(v.errs...))
+}
@@ -0,0 +1,335 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+var (
+	ErrUnchanged = errors.New("admin: nothing to change")
+	// ErrNoAuthor is returned when acting on the author of reported content
+	// that has none, such as a guest player.
+	ErrNoAuthor = errors.New("admin: the reported content has no author account")
+)
+
+// Admin carries out support actions on users, quizzes and players,
+// recording each one in the admin audit log in the same transaction as
+// the change.
+type Admin struct {
+	userRepo        models.UserRepository
+	quizRepo        models.QuizRepository
+	participantRepo models.GameParticipantRepository
+	reportRepo      models.ModerationReportRepository
+	auditRepo       models.AdminAuditLogRepository
+}
+
+func NewAdmin(userRepo models.UserRepository,
+	quizRepo models.QuizRepository,
+	participantRepo models.GameParticipantRepository,
+	reportRepo models.ModerationReportRepository,
+	auditRepo models.AdminAuditLogRepository,
+) *Admin {
+	return &Admin{
+		userRepo:        userRepo,
+		quizRepo:        quizRepo,
+		participantRepo: participantRepo,
+		reportRepo:      reportRepo,
+		auditRepo:       auditRepo,
+	}
+}
+
+// FindUser finds a user by their email, or by their id when the lookup
+// is not an email.
+func (a *Admin) FindUser(ctx context.Context, lookup string) (*models.User, error) {
+	if strings.Contains(lookup, "@") {
+		return a.userRepo.FindOne(ctx, &models.FindUserOptions{Email: lookup})
+	}
+
+	if uuid.Validate(lookup) != nil {
+		return nil, database.ErrUserNotFound
+	}
+	return a.userRepo.FindOne(ctx, &models.FindUserOptions{ID: lookup})
+}
+
+func (a *Admin) FindQuiz(ctx context.Context, id string) (*models.Quiz, error) {
+	if uuid.Validate(id) != nil {
+		return nil, database.ErrQuizNotFound
+	}
+	return a.quizRepo.FindOne(ctx, &models.FindQuizOptions{ID: id})
+}
+
+// SetRole promotes or demotes the user to role.
+func (a *Admin) SetRole(ctx context.Context, actor string, user *models.User, role models.UserRole, reason string) error {
+	if user.Role == role {
+		return ErrUnchanged
+	}
+
+	action := models.AdminActionPromote
+	if role != models.UserRoleAdmin {
+		action = models.AdminActionDemote
+	}
+
+	entry, err := newEntry(actor, action, user.ID, map[string]any{
+		"reason": reason,
+		"from":   user.Role,
+		"to":     role,
+	})
+	if err != nil {
+		return err
+	}
+
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	return a.auditRepo.UpdateUser(ctx, user, entry)
+}
+
+// Suspend keeps the user from signing in or using their tokens until
+// they are unsuspended.
+func (a *Admin) Suspend(ctx context.Context, actor string, user *models.User, reason string) error {
+	return a.setStatus(ctx, actor, user, models.UserStatusSuspended, models.AdminActionSuspend, reason)
+}
+
+// Unsuspend makes the user active again, lifting a ban as well.
+func (a *Admin) Unsuspend(ctx context.Context, actor string, user *models.User, reason string) error {
+	return a.setStatus(ctx, actor, user, models.UserStatusActive, models.AdminActionUnsuspend, reason)
+}
+
+// Ban is a suspension that is not expected to be lifted.
+func (a *Admin) Ban(ctx context.Context, actor string, user *models.User, reason string) error {
+	return a.setStatus(ctx, actor, user, models.UserStatusBanned, models.AdminActionBan, reason)
+}
+
+// Warn leaves the account as it is, the warning is kept in the audit log
+// so later decisions can take it into account.
+func (a *Admin) Warn(ctx context.Context, actor string, user *models.User, reason string) error {
+	entry, err := newEntry(actor, models.AdminActionWarn, user.ID, map[string]any{"reason": reason})
+	if err != nil {
+		return err
+	}
+
+	return a.auditRepo.Create(ctx, entry)
+}
+
+func (a *Admin) setStatus(ctx context.Context,
+	actor string,
+	user *models.User,
+	status models.UserStatus,
+	action models.AdminAction,
+	reason string,
+) error {
+	if user.Status == status {
+		return ErrUnchanged
+	}
+
+	entry, err := newEntry(actor, action, user.ID, map[string]any{"reason": reason})
+	if err != nil {
+		return err
+	}
+
+	user.Status = status
+	user.UpdatedAt = time.Now()
+	return a.auditRepo.UpdateUser(ctx, user, entry)
+}
+
+// RevokeSessions invalidates every token issued to the user so far, they
+// have to sign in again.
+func (a *Admin) RevokeSessions(ctx context.Context, actor string, user *models.User, reason string) error {
+	entry, err := newEntry(actor, models.AdminActionRevokeSessions, user.ID, map[string]any{"reason": reason})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.TokensRevokedAt = utils.Ptr(now)
+	user.UpdatedAt = now
+	return a.auditRepo.UpdateUser(ctx, user, entry)
+}
+
+// HideQuiz keeps the quiz from being played, new games cannot be started
+// and its open challenges cannot be joined.
+func (a *Admin) HideQuiz(ctx context.Context, actor string, quiz *models.Quiz, reason string) error {
+	if quiz.IsHidden() {
+		return ErrUnchanged
+	}
+
+	entry, err := newEntry(actor, models.AdminActionHideQuiz, quiz.ID, map[string]any{"reason": reason})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	quiz.HiddenAt = utils.Ptr(now)
+	quiz.UpdatedAt = now
+	return a.auditRepo.UpdateQuiz(ctx, quiz, entry)
+}
+
+func (a *Admin) UnhideQuiz(ctx context.Context, actor string, quiz *models.Quiz, reason string) error {
+	if !quiz.IsHidden() {
+		return ErrUnchanged
+	}
+
+	entry, err := newEntry(actor, models.AdminActionUnhideQuiz, quiz.ID, map[string]any{"reason": reason})
+	if err != nil {
+		return err
+	}
+
+	quiz.HiddenAt = nil
+	quiz.UpdatedAt = time.Now()
+	return a.auditRepo.UpdateQuiz(ctx, quiz, entry)
+}
+
+// HideNickname replaces the player's nickname with a neutral one in the
+// session they played.
+func (a *Admin) HideNickname(ctx context.Context, actor string, participant *models.GameParticipant, reason string) error {
+	nickname := "Player-" + participant.ID[:8]
+	if participant.Nickname == nickname {
+		return ErrUnchanged
+	}
+
+	entry, err := newEntry(actor, models.AdminActionHideNickname, participant.ID, map[string]any{
+		"reason": reason,
+		"from":   participant.Nickname,
+	})
+	if err != nil {
+		return err
+	}
+
+	participant.Nickname = nickname
+	participant.UpdatedAt = time.Now()
+	return a.auditRepo.UpdateParticipant(ctx, participant, entry)
+}
+
+// TransferQuiz hands the quiz over to another user, games already
+// played keep the host they had.
+func (a *Admin) TransferQuiz(ctx context.Context, actor string, quiz *models.Quiz, to *models.User, reason string) error {
+	if quiz.OwnerID == to.ID {
+		return ErrUnchanged
+	}
+
+	entry, err := newEntry(actor, models.AdminActionTransferQuiz, quiz.ID, map[string]any{
+		"reason": reason,
+		"from":   quiz.OwnerID,
+		"to":     to.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	quiz.OwnerID = to.ID
+	quiz.UpdatedAt = time.Now()
+	return a.auditRepo.UpdateQuiz(ctx, quiz, entry)
+}
+
+// ResolveReport acts on the report and closes it along with every other
+// open report on the same target. resolverID is the admin deciding.
+func (a *Admin) ResolveReport(ctx context.Context,
+	actor, resolverID string,
+	report *models.ModerationReport,
+	resolution models.ReportResolution,
+	note string,
+) error {
+	if report.Status != models.ReportStatusOpen {
+		return ErrUnchanged
+	}
+
+	reason := "report " + report.ID
+	if note != "" {
+		reason += ": " + note
+	}
+
+	if err := a.applyResolution(ctx, actor, report, resolution, reason); err != nil && !errors.Is(err, ErrUnchanged) {
+		return err
+	}
+
+	now := time.Now()
+	report.Status = models.ReportStatusResolved
+	if resolution == models.ReportResolutionDismiss {
+		report.Status = models.ReportStatusDismissed
+	}
+	report.Resolution = resolution
+	report.ResolvedBy = utils.Ptr(resolverID)
+	report.ResolvedAt = utils.Ptr(now)
+	report.UpdatedAt = now
+
+	return a.reportRepo.Resolve(ctx, report)
+}
+
+func (a *Admin) applyResolution(ctx context.Context,
+	actor string,
+	report *models.ModerationReport,
+	resolution models.ReportResolution,
+	reason string,
+) error {
+	switch resolution {
+	case models.ReportResolutionDismiss:
+		return nil
+	case models.ReportResolutionHide:
+		if report.TargetType == models.ReportTargetParticipant {
+			participant, err := a.participantRepo.FindOne(ctx, &models.FindGameParticipantOptions{ID: report.TargetID})
+			if err != nil {
+				return err
+			}
+			return a.HideNickname(ctx, actor, participant, reason)
+		}
+
+		quiz, err := a.quizRepo.FindOne(ctx, &models.FindQuizOptions{ID: report.TargetID})
+		if err != nil {
+			return err
+		}
+		return a.HideQuiz(ctx, actor, quiz, reason)
+	}
+
+	if report.AuthorID == nil {
+		return ErrNoAuthor
+	}
+
+	author, err := a.userRepo.FindOne(ctx, &models.FindUserOptions{ID: *report.AuthorID})
+	if err != nil {
+		return err
+	}
+
+	switch resolution {
+	case models.ReportResolutionWarn:
+		return a.Warn(ctx, actor, author, reason)
+	case models.ReportResolutionSuspend:
+		return a.Suspend(ctx, actor, author, reason)
+	case models.ReportResolutionBan:
+		return a.Ban(ctx, actor, author, reason)
+	default:
+		return models.ErrInvalidReportResolution
+	}
+}
+
+// AuditLog returns what was done to a user or quiz, oldest first.
+func (a *Admin) AuditLog(ctx context.Context, targetID string) ([]models.AdminAuditLog, error) {
+	if uuid.Validate(targetID) != nil {
+		return []models.AdminAuditLog{}, nil
+	}
+	return a.auditRepo.FindAll(ctx, targetID)
+}
+
+// newEntry builds the audit log entry of an action, it is saved along with
+// the change so an action is never taken without being recorded.
+func newEntry(actor string, action models.AdminAction, targetID string, details map[string]any) (*models.AdminAuditLog, error) {
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AdminAuditLog{
+		ID:        utils.Uuid(),
+		Actor:     actor,
+		Action:    action,
+		TargetID:  targetID,
+		Details:   raw,
+		CreatedAt: time.Now(),
+	}, nil
+}
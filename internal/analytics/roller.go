@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+// batchSize is how many finished sessions are rolled up per query.
+const batchSize = 50
+
+// RollupJob rolls up every finished session that is not rolled up yet,
+// it runs on a schedule.
+var RollupJob = jobs.Kind[RollupArgs]{Name: "analytics.rollup", MaxAttempts: 3}
+
+type RollupArgs struct{}
+
+// Roller rolls finished sessions up into their quiz's statistics, so
+// reading them never scans raw answers.
+type Roller struct {
+	quizRepo        models.QuizRepository
+	participantRepo models.GameParticipantRepository
+	answerRepo      models.GameAnswerRepository
+	analyticsRepo   models.AnalyticsRepository
+}
+
+func NewRoller(quizRepo models.QuizRepository,
+	participantRepo models.GameParticipantRepository,
+	answerRepo models.GameAnswerRepository,
+	analyticsRepo models.AnalyticsRepository,
+) *Roller {
+	return &Roller{
+		quizRepo:        quizRepo,
+		participantRepo: participantRepo,
+		answerRepo:      answerRepo,
+		analyticsRepo:   analyticsRepo,
+	}
+}
+
+// HandleRollup runs RollupJob.
+func (r *Roller) HandleRollup(ctx context.Context, _ RollupArgs) error {
+	return r.RollupPending(ctx)
+}
+
+// RollupPending rolls up every finished session that is not rolled up
+// yet. A session that fails is logged and skipped, it is tried again on
+// the next run.
+func (r *Roller) RollupPending(ctx context.Context) error {
+	failed := make(map[string]struct{})
+	for {
+		// failed sessions are still unrolled, look past them
+		limit := batchSize + len(failed)
+		sessions, err := r.analyticsRepo.FindUnrolled(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		progressed := false
+		for i := range sessions {
+			session := &sessions[i]
+			if _, ok := failed[session.ID]; ok {
+				continue
+			}
+
+			if err := r.rollup(ctx, session); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				slog.Error("[analytics roller]: could not roll up session", slog.String("session_id", session.ID), slog.Any("error", err))
+				failed[session.ID] = struct{}{}
+				continue
+			}
+			progressed = true
+		}
+
+		if !progressed || len(sessions) < limit {
+			return nil
+		}
+	}
+}
+
+func (r *Roller) rollup(ctx context.Context, session *models.GameSession) error {
+	quiz, err := r.quizRepo.FindOne(ctx, &models.FindQuizOptions{ID: session.QuizID})
+	if err != nil {
+		return err
+	}
+
+	leaderboard, err := r.participantRepo.Leaderboard(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+
+	answers, err := r.answerRepo.FindAll(ctx, &models.FindGameAnswerOptions{SessionID: session.ID})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.analyticsRepo.Rollup(ctx, Rollup(session, quiz, leaderboard, answers, time.Now()))
+	return err
+}
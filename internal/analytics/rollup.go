@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+)
+
+const (
+	// groupShare is the share of a session's players in each of the upper
+	// and lower groups, the usual 27% of item analysis.
+	groupShare = 0.27
+	// maxBucketSeconds caps the time buckets, slower answers are counted
+	// in the last one.
+	maxBucketSeconds = 300
+)
+
+// Rollup computes what a finished session adds to its quiz's statistics.
+// Only players on the leaderboard count, so banned players stay out.
+func Rollup(session *models.GameSession,
+	quiz *models.Quiz,
+	leaderboard []models.LeaderboardEntry,
+	answers []models.GameAnswer,
+	now time.Time,
+) *models.QuizRollup {
+	rollup := &models.QuizRollup{
+		SessionID:   session.ID,
+		QuizID:      quiz.ID,
+		Questions:   []models.QuestionStat{},
+		Options:     []models.QuestionOptionStat{},
+		TimeBuckets: []models.QuestionTimeBucket{},
+	}
+
+	if len(leaderboard) == 0 {
+		return rollup
+	}
+
+	upper, lower := groups(leaderboard)
+
+	byQuestion := make(map[string]map[string]*models.GameAnswer)
+	for i := range answers {
+		answer := &answers[i]
+		if answer.AnsweredAt == nil {
+			continue
+		}
+
+		if byQuestion[answer.QuestionID] == nil {
+			byQuestion[answer.QuestionID] = make(map[string]*models.GameAnswer)
+		}
+		byQuestion[answer.QuestionID][answer.ParticipantID] = answer
+	}
+
+	for i := range quiz.Questions {
+		question := &quiz.Questions[i]
+
+		kind, err := questionkind.ForQuestion(question)
+		if err != nil {
+			continue
+		}
+
+		stat := models.QuestionStat{
+			QuestionID: question.ID,
+			QuizID:     quiz.ID,
+			UpdatedAt:  now,
+		}
+
+		options := make(map[string]int, len(question.QuestionOptions))
+		for _, option := range question.QuestionOptions {
+			options[option.ID] = 0
+		}
+		buckets := make(map[int]int)
+
+		for _, entry := range leaderboard {
+			answer, ok := byQuestion[question.ID][entry.ParticipantID]
+			if !ok {
+				continue
+			}
+
+			stat.AnswerCount++
+			if answer.Correct {
+				stat.CorrectCount++
+			}
+
+			if answer.TimedOut {
+				continue
+			}
+			buckets[bucket(answer)]++
+
+			if kind.HasOptions() {
+				var submitted questionkind.Answer
+				if err := json.Unmarshal(answer.Answer, &submitted); err != nil {
+					continue
+				}
+				for _, id := range submitted.OptionIDs {
+					if _, ok := options[id]; ok {
+						options[id]++
+					}
+				}
+			}
+		}
+
+		// the groups only tell questions apart when answers can be wrong,
+		// a player of a group that did not answer got the question wrong
+		if kind.Scored() {
+			for _, id := range upper {
+				stat.UpperCount++
+				if answer, ok := byQuestion[question.ID][id]; ok && answer.Correct {
+					stat.UpperCorrect++
+				}
+			}
+			for _, id := range lower {
+				stat.LowerCount++
+				if answer, ok := byQuestion[question.ID][id]; ok && answer.Correct {
+					stat.LowerCorrect++
+				}
+			}
+		}
+
+		rollup.Questions = append(rollup.Questions, stat)
+
+		for _, option := range question.QuestionOptions {
+			if picks := options[option.ID]; picks > 0 {
+				rollup.Options = append(rollup.Options, models.QuestionOptionStat{
+					OptionID:   option.ID,
+					QuestionID: question.ID,
+					PickCount:  picks,
+					UpdatedAt:  now,
+				})
+			}
+		}
+
+		for seconds := 0; seconds <= maxBucketSeconds; seconds++ {
+			if count := buckets[seconds]; count > 0 {
+				rollup.TimeBuckets = append(rollup.TimeBuckets, models.QuestionTimeBucket{
+					QuestionID: question.ID,
+					Seconds:    seconds,
+					Count:      count,
+				})
+			}
+		}
+	}
+
+	return rollup
+}
+
+// groups returns the participants of the upper and lower groups of a
+// ranked leaderboard. A session needs two players to have both, and the
+// groups never overlap.
+func groups(leaderboard []models.LeaderboardEntry) (upper, lower []string) {
+	if len(leaderboard) < 2 {
+		return nil, nil
+	}
+
+	size := min(int(math.Ceil(float64(len(leaderboard))*groupShare)), len(leaderboard)/2)
+
+	upper = make([]string, 0, size)
+	lower = make([]string, 0, size)
+	for i := range size {
+		upper = append(upper, leaderboard[i].ParticipantID)
+		lower = append(lower, leaderboard[len(leaderboard)-1-i].ParticipantID)
+	}
+
+	return upper, lower
+}
+
+func bucket(answer *models.GameAnswer) int {
+	seconds := int(answer.AnsweredAt.Sub(answer.StartedAt).Seconds())
+	return max(0, min(seconds, maxBucketSeconds))
+}
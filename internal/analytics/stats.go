@@ -0,0 +1,169 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+)
+
+type OptionStats struct {
+	OptionID  string `json:"option_id"`
+	Option    string `json:"option"`
+	IsCorrect bool   `json:"is_correct"`
+	PickCount int    `json:"pick_count"`
+	// PickRate is the share of answers that picked the option, nil until
+	// the question has been answered.
+	PickRate *float64 `json:"pick_rate"`
+	// Unused marks the wrong options of scored questions that nobody
+	// picks, distractors that do not distract.
+	Unused bool `json:"unused"`
+}
+
+type QuestionStats struct {
+	QuestionID  string `json:"question_id"`
+	Position    int    `json:"position"`
+	Question    string `json:"question"`
+	Kind        string `json:"kind"`
+	AnswerCount int    `json:"answer_count"`
+	// Difficulty is the share of answers that were correct, lower is
+	// harder. Discrimination is how much more often the best players got
+	// the question right than the worst, questions near or below zero do
+	// not tell them apart. Both are nil for kinds that are not scored.
+	Difficulty          *float64      `json:"difficulty"`
+	Discrimination      *float64      `json:"discrimination"`
+	MedianAnswerSeconds *int          `json:"median_answer_seconds"`
+	Options             []OptionStats `json:"options"`
+}
+
+// QuizAnalytics is how a quiz's questions performed across every session
+// rolled up so far.
+type QuizAnalytics struct {
+	QuizID       string          `json:"quiz_id"`
+	QuizTitle    string          `json:"quiz_title"`
+	SessionCount int             `json:"session_count"`
+	Questions    []QuestionStats `json:"questions"`
+}
+
+// Build reads the rolled up statistics of a quiz for its current
+// questions, in quiz order.
+func Build(quiz *models.Quiz, stats *models.QuizStats) *QuizAnalytics {
+	questionStats := make(map[string]models.QuestionStat, len(stats.Questions))
+	for _, stat := range stats.Questions {
+		questionStats[stat.QuestionID] = stat
+	}
+
+	picks := make(map[string]int, len(stats.Options))
+	for _, stat := range stats.Options {
+		picks[stat.OptionID] = stat.PickCount
+	}
+
+	buckets := make(map[string][]models.QuestionTimeBucket)
+	for _, bucket := range stats.TimeBuckets {
+		buckets[bucket.QuestionID] = append(buckets[bucket.QuestionID], bucket)
+	}
+
+	analytics := &QuizAnalytics{
+		QuizID:       quiz.ID,
+		QuizTitle:    quiz.Title,
+		SessionCount: stats.SessionCount,
+		Questions:    make([]QuestionStats, 0, len(quiz.Questions)),
+	}
+
+	questions := make([]models.Question, len(quiz.Questions))
+	copy(questions, quiz.Questions)
+	sort.SliceStable(questions, func(i, j int) bool {
+		return questions[i].Position < questions[j].Position
+	})
+
+	for i := range questions {
+		question := &questions[i]
+		stat := questionStats[question.ID]
+
+		qs := QuestionStats{
+			QuestionID:          question.ID,
+			Position:            question.Position,
+			Question:            question.Question,
+			AnswerCount:         stat.AnswerCount,
+			MedianAnswerSeconds: median(buckets[question.ID]),
+			Options:             make([]OptionStats, 0, len(question.QuestionOptions)),
+		}
+
+		kind, err := questionkind.ForQuestion(question)
+		scored := err == nil && kind.Scored()
+		if err == nil {
+			qs.Kind = kind.Slug()
+		}
+
+		if scored {
+			qs.Difficulty = rate(stat.CorrectCount, stat.AnswerCount)
+
+			upper, lower := rate(stat.UpperCorrect, stat.UpperCount), rate(stat.LowerCorrect, stat.LowerCount)
+			if upper != nil && lower != nil {
+				discrimination := *upper - *lower
+				qs.Discrimination = &discrimination
+			}
+		}
+
+		// picks are out of the answers given in time, the ones the time
+		// buckets count
+		answered := 0
+		for _, bucket := range buckets[question.ID] {
+			answered += bucket.Count
+		}
+
+		options := make([]models.QuestionOption, len(question.QuestionOptions))
+		copy(options, question.QuestionOptions)
+		sort.SliceStable(options, func(i, j int) bool {
+			return options[i].Position < options[j].Position
+		})
+
+		for _, option := range options {
+			count := picks[option.ID]
+			qs.Options = append(qs.Options, OptionStats{
+				OptionID:  option.ID,
+				Option:    option.Option,
+				IsCorrect: option.IsCorrect,
+				PickCount: count,
+				PickRate:  rate(count, answered),
+				Unused:    scored && !option.IsCorrect && answered > 0 && count == 0,
+			})
+		}
+
+		analytics.Questions = append(analytics.Questions, qs)
+	}
+
+	return analytics
+}
+
+// median returns the second the middle answer fell in, buckets must be in
+// ascending order.
+func median(buckets []models.QuestionTimeBucket) *int {
+	total := 0
+	for _, bucket := range buckets {
+		total += bucket.Count
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	seen := 0
+	for _, bucket := range buckets {
+		seen += bucket.Count
+		if seen*2 >= total {
+			return &bucket.Seconds
+		}
+	}
+
+	return nil
+}
+
+func rate(n, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+
+	r := float64(n) / float64(total)
+	return &r
+}
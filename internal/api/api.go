@@ -2,68 +2,244 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/admin"
 	"github.com/oxiginedev/sabipass/internal/api/handlers"
 	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/health"
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/live"
+	"github.com/oxiginedev/sabipass/internal/metrics"
 	"github.com/oxiginedev/sabipass/internal/models"
 	"github.com/oxiginedev/sabipass/internal/pkg/jwt"
+	"github.com/oxiginedev/sabipass/internal/ratelimit"
+	"github.com/oxiginedev/sabipass/internal/report"
+	"github.com/oxiginedev/sabipass/internal/storage"
 )
 
 type API struct {
 	cfg              *config.Config
 	tokenManager     jwt.TokenManager
+	blobStore        storage.BlobStore
+	queue            *jobs.Queue
+	engine           *live.Engine
+	reporter         *report.Reporter
+	health           *health.Health
+	limiter          ratelimit.Limiter
+	requestLimiter   ratelimit.Limiter
 	userRepo         models.UserRepository
 	quizRepo         models.QuizRepository
+	questionRepo     models.QuestionRepository
 	questionTypeRepo models.QuestionTypeRepository
+	uploadRepo       models.UploadRepository
+	sessionRepo      models.GameSessionRepository
+	participantRepo  models.GameParticipantRepository
+	answerRepo       models.GameAnswerRepository
+	auditRepo        models.GameAuditLogRepository
+	analyticsRepo    models.AnalyticsRepository
+	reportRepo       models.ModerationReportRepository
+	adminAuditRepo   models.AdminAuditLogRepository
 }
 
 func NewAPI(cfg *config.Config,
 	tokenManager jwt.TokenManager,
+	blobStore storage.BlobStore,
+	queue *jobs.Queue,
+	engine *live.Engine,
+	reporter *report.Reporter,
+	health *health.Health,
+	limiter ratelimit.Limiter,
+	requestLimiter ratelimit.Limiter,
 	userRepo models.UserRepository,
 	quizRepo models.QuizRepository,
+	questionRepo models.QuestionRepository,
 	questionTypeRepo models.QuestionTypeRepository,
+	uploadRepo models.UploadRepository,
+	sessionRepo models.GameSessionRepository,
+	participantRepo models.GameParticipantRepository,
+	answerRepo models.GameAnswerRepository,
+	auditRepo models.GameAuditLogRepository,
+	analyticsRepo models.AnalyticsRepository,
+	reportRepo models.ModerationReportRepository,
+	adminAuditRepo models.AdminAuditLogRepository,
 ) *API {
 	return &API{
 		cfg:              cfg,
 		tokenManager:     tokenManager,
+		blobStore:        blobStore,
+		queue:            queue,
+		engine:           engine,
+		reporter:         reporter,
+		health:           health,
+		limiter:          limiter,
+		requestLimiter:   requestLimiter,
 		userRepo:         userRepo,
 		quizRepo:         quizRepo,
+		questionRepo:     questionRepo,
 		questionTypeRepo: questionTypeRepo,
+		uploadRepo:       uploadRepo,
+		sessionRepo:      sessionRepo,
+		participantRepo:  participantRepo,
+		answerRepo:       answerRepo,
+		auditRepo:        auditRepo,
+		analyticsRepo:    analyticsRepo,
+		reportRepo:       reportRepo,
+		adminAuditRepo:   adminAuditRepo,
 	}
 }
 
-func (a *API) RegisterRoutes() http.Handler {
+func (a *API) RegisterRoutes() (http.Handler, error) {
 	router := gin.New()
 	if a.cfg.Environment == config.EnvironmentProduction {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// without trusted proxies the client address is the peer address, a
+	// forwarded one could be made up to get around rate limits
+	if err := router.SetTrustedProxies(a.cfg.HTTP.TrustedProxies); err != nil {
+		return nil, err
+	}
+
+	cors := middleware.CORSOptions{
+		AllowedOrigins:   a.cfg.CORS.AllowedOrigins,
+		AllowedMethods:   a.cfg.CORS.AllowedMethods,
+		AllowedHeaders:   a.cfg.CORS.AllowedHeaders,
+		AllowCredentials: a.cfg.CORS.AllowCredentials,
+		MaxAge:           a.cfg.CORS.MaxAge,
+	}
+	var hstsMaxAge time.Duration
+	switch a.cfg.Environment {
+	case config.EnvironmentProduction:
+		hstsMaxAge = a.cfg.HTTP.HSTSMaxAge
+	case config.EnvironmentLocal:
+		if len(cors.AllowedOrigins) == 0 {
+			cors.AllowedOrigins = []string{"*"}
+		}
+	}
+
+	ipLimit := ratelimit.PerMinute("ip", a.cfg.RateLimit.IP)
+	userLimit := ratelimit.PerMinute("user", a.cfg.RateLimit.User)
+	authLimit := ratelimit.PerMinute("auth", a.cfg.RateLimit.Auth)
+	joinCodeLimit := ratelimit.PerMinute("join-code", a.cfg.RateLimit.JoinCode)
+	answerLimit := ratelimit.PerMinute("answer", a.cfg.RateLimit.Answers)
+
+	healthHandler := handlers.NewHealthHandler(a.health)
+	jwksHandler := handlers.NewJWKSHandler(a.tokenManager)
 	oauthHandler := handlers.NewOauthHandler(a.cfg, a.tokenManager, a.userRepo)
-	userHandler := handlers.NewUserHandler(a.userRepo)
-	quizHandler := handlers.NewQuizHandler(a.quizRepo)
+	userHandler := handlers.NewUserHandler(a.userRepo, a.uploadRepo)
+	quizHandler := handlers.NewQuizHandler(a.quizRepo, a.uploadRepo, a.queue)
+	questionHandler := handlers.NewQuestionHandler(a.quizRepo, a.questionRepo, a.questionTypeRepo, a.uploadRepo, a.queue)
+	uploadHandler := handlers.NewUploadHandler(a.cfg, a.blobStore, a.queue, a.uploadRepo)
 	questionTypeHandler := handlers.NewQuestionTypeHandler(a.questionTypeRepo)
+	challengeHandler := handlers.NewChallengeHandler(a.quizRepo, a.sessionRepo, a.participantRepo, a.answerRepo)
+	sessionHandler := handlers.NewSessionHandler(a.sessionRepo, a.reporter)
+	analyticsHandler := handlers.NewAnalyticsHandler(a.quizRepo, a.analyticsRepo)
+	liveHandler := handlers.NewLiveHandler(a.userRepo, a.quizRepo, a.sessionRepo, a.auditRepo, a.tokenManager, a.engine, live.NewHub(a.engine),
+		a.limiter, answerLimit)
+	moderationHandler := handlers.NewModerationHandler(
+		admin.NewAdmin(a.userRepo, a.quizRepo, a.participantRepo, a.reportRepo, a.adminAuditRepo),
+		a.quizRepo, a.participantRepo, a.reportRepo)
 
-	router.Use(gin.Recovery())
+	// probes come before the middleware so they do not flood the logs,
+	// traces and request metrics. They are served here too so load
+	// balancers can probe the API port when the admin port is off.
+	router.GET("/healthz", healthHandler.HandleLiveness)
+	router.GET("/readyz", healthHandler.HandleReadiness)
+
+	router.Use(middleware.Tracing(a.cfg.Tracing.ServiceName), middleware.RequestID(), middleware.RequestLogger(a.cfg.Log.SampleRate), middleware.Metrics(), middleware.Recovery(),
+		middleware.SecurityHeaders(hstsMaxAge), middleware.CORS(cors), middleware.RateLimit(a.requestLimiter, ipLimit, middleware.ByIP))
 	router.NoRoute(func(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusNotFound, models.NewErrorResponse("the requested route was not found", nil))
 	})
 
-	router.GET("/oauth/google/redirect", oauthHandler.HandleGoogleLoginRedirect)
-	router.GET("/oauth/google/callback", oauthHandler.HandleGoogleLoginCallback)
+	if a.cfg.Storage.Driver == config.StorageDriverLocal {
+		router.Static("/media", a.cfg.Storage.Local.Path)
+	}
+
+	router.GET("/.well-known/jwks.json", jwksHandler.HandleGetJWKS)
+
+	oauthRouter := router.Group("/oauth", middleware.RateLimit(a.limiter, authLimit, middleware.ByRoute(middleware.ByIP)))
+	{
+		oauthRouter.GET("/google/redirect", oauthHandler.HandleGoogleLoginRedirect)
+		oauthRouter.GET("/google/callback", oauthHandler.HandleGoogleLoginCallback)
+	}
 
-	authRouter := router.Group("/", middleware.RequireAuth(a.tokenManager, a.userRepo))
+	authRouter := router.Group("/", middleware.RequireAuth(a.tokenManager, a.userRepo), middleware.RateLimit(a.requestLimiter, userLimit, middleware.ByUser))
 	{
 		authRouter.GET("/users/me", userHandler.HandleGetCurrentUser)
+		authRouter.PUT("/users/me/avatar", userHandler.HandleUpdateAvatar)
+
+		authRouter.POST("/uploads", uploadHandler.HandleCreateUpload)
 
 		authRouter.GET("/quizzes", quizHandler.HandleGetAllQuizzes)
 		authRouter.POST("/quizzes", quizHandler.HandleCreateQuiz)
 		authRouter.GET("/quizzes/:quizid", quizHandler.HandleGetQuiz)
 		authRouter.PATCH("/quizzes/:quizid", quizHandler.HandleEditQuiz)
+		authRouter.POST("/quizzes/:quizid/publish", quizHandler.HandlePublishQuiz)
+		authRouter.GET("/quizzes/:quizid/analytics", analyticsHandler.HandleGetQuizAnalytics)
+		authRouter.POST("/quizzes/:quizid/challenges", challengeHandler.HandleCreateChallenge)
+		authRouter.POST("/quizzes/:quizid/games", liveHandler.HandleCreateLiveGame)
+		authRouter.GET("/sessions", sessionHandler.HandleListSessions)
+		authRouter.GET("/sessions/:sessionid/report", sessionHandler.HandleGetSessionReport)
+		authRouter.GET("/games/:code/audit", liveHandler.HandleGetAuditLog)
+		authRouter.POST("/games/:code/presenter", liveHandler.HandleCreatePresenterLink)
+		authRouter.PUT("/challenges/:code/participants/:participantid/team", challengeHandler.HandleAssignTeam)
+		authRouter.POST("/challenges/:code/teams/balance", challengeHandler.HandleBalanceTeams)
+
+		authRouter.POST("/quizzes/:quizid/questions/import", questionHandler.HandleImportQuestions)
+		authRouter.PUT("/quizzes/:quizid/questions/:questionid/attachment", questionHandler.HandleAttachUpload)
+		authRouter.DELETE("/quizzes/:quizid/questions/:questionid/attachment", questionHandler.HandleDetachUpload)
 
 		authRouter.GET("/question-types", questionTypeHandler.HandleGetAllQuestionTypes)
+
+		authRouter.POST("/reports", moderationHandler.HandleCreateReport)
 	}
 
+	adminRouter := router.Group("/admin", middleware.RequireAuth(a.tokenManager, a.userRepo), middleware.RequireAdmin(),
+		middleware.RateLimit(a.requestLimiter, userLimit, middleware.ByUser))
+	{
+		adminRouter.GET("/reports", moderationHandler.HandleListReports)
+		adminRouter.POST("/reports/:reportid/resolve", moderationHandler.HandleResolveReport)
+	}
+
+	// codes are short, an address gets a few wrong ones before it has to
+	// wait
+	joinCodeLimiter := middleware.RateLimitFailures(a.limiter, joinCodeLimit, middleware.ByIP, http.StatusNotFound)
+
+	challengeRouter := router.Group("/challenges/:code", joinCodeLimiter, middleware.OptionalAuth(a.tokenManager, a.userRepo))
+	{
+		challengeRouter.GET("", challengeHandler.HandleGetChallenge)
+		challengeRouter.POST("/join", challengeHandler.HandleJoinChallenge)
+		challengeRouter.PUT("/team", challengeHandler.HandleChooseTeam)
+		challengeRouter.GET("/question", challengeHandler.HandleGetCurrentQuestion)
+		challengeRouter.POST("/answers", challengeHandler.HandleSubmitAnswer)
+		challengeRouter.GET("/leaderboard", challengeHandler.HandleGetLeaderboard)
+	}
+
+	gameRouter := router.Group("/games/:code", joinCodeLimiter, middleware.OptionalAuth(a.tokenManager, a.userRepo))
+	{
+		gameRouter.GET("", liveHandler.HandleGetLiveGame)
+		gameRouter.GET("/ws", liveHandler.HandleLiveSocket)
+	}
+
+	return router, nil
+}
+
+// RegisterAdminRoutes serves the metrics and health probes on the admin
+// port, for scrapers and orchestrators that should not go through the
+// public API.
+func (a *API) RegisterAdminRoutes() http.Handler {
+	router := gin.New()
+	router.Use(middleware.Recovery())
+
+	healthHandler := handlers.NewHealthHandler(a.health)
+
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/healthz", healthHandler.HandleLiveness)
+	router.GET("/readyz", healthHandler.HandleReadiness)
+
 	return router
 }
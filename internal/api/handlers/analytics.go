@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/analytics"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+type analyticsHandler struct {
+	quizRepo      models.QuizRepository
+	analyticsRepo models.AnalyticsRepository
+}
+
+func NewAnalyticsHandler(quizRepo models.QuizRepository, analyticsRepo models.AnalyticsRepository) *analyticsHandler {
+	return &analyticsHandler{
+		quizRepo:      quizRepo,
+		analyticsRepo: analyticsRepo,
+	}
+}
+
+// HandleGetQuizAnalytics shows the author of a quiz how its questions
+// performed across the sessions rolled up so far.
+func (h *analyticsHandler) HandleGetQuizAnalytics(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[analytics handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	quiz, err := h.quizRepo.FindOne(c.Request.Context(), &models.FindQuizOptions{
+		ID: c.Param("quizid"),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrQuizNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[analytics handler]: could not get quiz", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get quiz analytics", nil))
+		return
+	}
+
+	if quiz.OwnerID != user.ID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+		return
+	}
+
+	stats, err := h.analyticsRepo.FindQuizStats(c.Request.Context(), quiz.ID)
+	if err != nil {
+		middleware.Logger(c).Error("[analytics handler]: could not get quiz stats", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get quiz analytics", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("quiz analytics retrieved successfully", analytics.Build(quiz, stats)))
+}
@@ -0,0 +1,840 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+const (
+	participantTokenHeader = "X-Participant-Token"
+
+	minChallengeDuration = time.Minute
+	maxChallengeDuration = 30 * 24 * time.Hour
+	joinCodeAttempts     = 5
+	maxTeamNameLength    = 30
+)
+
+type challengeHandler struct {
+	quizRepo        models.QuizRepository
+	sessionRepo     models.GameSessionRepository
+	participantRepo models.GameParticipantRepository
+	answerRepo      models.GameAnswerRepository
+}
+
+func NewChallengeHandler(quizRepo models.QuizRepository,
+	sessionRepo models.GameSessionRepository,
+	participantRepo models.GameParticipantRepository,
+	answerRepo models.GameAnswerRepository,
+) *challengeHandler {
+	return &challengeHandler{
+		quizRepo:        quizRepo,
+		sessionRepo:     sessionRepo,
+		participantRepo: participantRepo,
+		answerRepo:      answerRepo,
+	}
+}
+
+func (h *challengeHandler) HandleCreateChallenge(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[challenge handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	var req models.CreateChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	now := time.Now()
+	if req.ClosesAt.Before(now.Add(minChallengeDuration)) || req.ClosesAt.After(now.Add(maxChallengeDuration)) {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.NewErrorResponse("The given input was invalid", map[string][]string{
+			"closes_at": {"The closes at field must be between one minute and 30 days from now"},
+		}))
+		return
+	}
+
+	session := &models.GameSession{
+		ID:       utils.Uuid(),
+		HostID:   user.ID,
+		Mode:     models.GameModeChallenge,
+		Status:   models.GameSessionStatusOpen,
+		ClosesAt: utils.Ptr(req.ClosesAt),
+	}
+
+	if !createGameSession(c, h.quizRepo, h.sessionRepo, session, &req.TeamOptions) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("challenge created successfully", session))
+}
+
+func (h *challengeHandler) HandleGetChallenge(c *gin.Context) {
+	session, quiz, ok := h.loadChallenge(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("challenge retrieved successfully", gin.H{
+		"code":            session.Code,
+		"status":          session.Status,
+		"is_open":         session.IsOpen(time.Now()),
+		"closes_at":       session.ClosesAt,
+		"team_assignment": session.TeamAssignment,
+		"team_scoring":    session.TeamScoring,
+		"teams":           session.Teams,
+		"quiz": gin.H{
+			"title":          quiz.Title,
+			"description":    quiz.Description,
+			"cover_image":    quiz.CoverImage,
+			"question_count": len(quiz.Questions),
+		},
+	}))
+}
+
+func (h *challengeHandler) HandleJoinChallenge(c *gin.Context) {
+	session, _, ok := h.loadChallenge(c)
+	if !ok {
+		return
+	}
+
+	var req models.JoinGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	req.Nickname = strings.TrimSpace(req.Nickname)
+	err := utils.Validate(req)
+	if err != nil {
+		verr, _ := err.(*utils.ValidatorErrorBag)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+			models.NewErrorResponse(verr.Error(), verr.Errors))
+		return
+	}
+
+	if !session.IsOpen(time.Now()) {
+		c.JSON(http.StatusGone, models.NewErrorResponse("this challenge has closed", nil))
+		return
+	}
+
+	token, tokenHash := game.NewParticipantToken()
+
+	// signed in players keep a single entry per challenge, joining again
+	// from another device hands out a new token for the same entry
+	user, signedIn := middleware.GetUserFromContext(c)
+	if signedIn {
+		participant, err := h.participantRepo.FindOne(c.Request.Context(), &models.FindGameParticipantOptions{
+			SessionID: session.ID,
+			UserID:    user.ID,
+		})
+		if err != nil && !errors.Is(err, database.ErrGameParticipantNotFound) {
+			middleware.Logger(c).Error("[challenge handler]: could not get participant", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to join challenge", nil))
+			return
+		}
+
+		if participant != nil {
+			participant.TokenHash = tokenHash
+			if err := h.participantRepo.Update(c.Request.Context(), participant); err != nil {
+				middleware.Logger(c).Error("[challenge handler]: could not update participant", slog.Any("error", err))
+				c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to join challenge", nil))
+				return
+			}
+
+			c.JSON(http.StatusOK, models.NewSuccessResponse("challenge rejoined successfully", gin.H{
+				"participant": participant,
+				"token":       token,
+			}))
+			return
+		}
+	}
+
+	participant := &models.GameParticipant{
+		ID:        utils.Uuid(),
+		SessionID: session.ID,
+		Nickname:  req.Nickname,
+		TokenHash: tokenHash,
+	}
+
+	if signedIn {
+		participant.UserID = utils.Ptr(user.ID)
+	}
+
+	if session.HasTeams() {
+		participants, err := h.participantRepo.FindAll(c.Request.Context(), session.ID)
+		if err != nil {
+			middleware.Logger(c).Error("[challenge handler]: could not get participants", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to join challenge", nil))
+			return
+		}
+
+		participant.TeamID, err = game.JoiningTeam(session, participants, req.TeamID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.NewErrorResponse("The given input was invalid", map[string][]string{
+				"team_id": {"Choose one of the challenge's teams"},
+			}))
+			return
+		}
+	}
+
+	if err := h.participantRepo.Create(c.Request.Context(), participant); err != nil {
+		if errors.Is(err, database.ErrNicknameTaken) {
+			c.JSON(http.StatusConflict, models.NewErrorResponse("this nickname is already taken", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[challenge handler]: could not create participant", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to join challenge", nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("challenge joined successfully", gin.H{
+		"participant": participant,
+		"token":       token,
+	}))
+}
+
+// HandleGetCurrentQuestion serves the participant's current question and
+// records when it was first shown, which is what the time limit is
+// measured from. Questions left unanswered past their time limit are
+// skipped.
+func (h *challengeHandler) HandleGetCurrentQuestion(c *gin.Context) {
+	session, quiz, ok := h.loadChallenge(c)
+	if !ok {
+		return
+	}
+
+	participant, ok := h.loadParticipant(c, session)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if participant.CurrentPosition < len(quiz.Questions) && !session.IsOpen(now) {
+		c.JSON(http.StatusGone, models.NewErrorResponse("this challenge has closed", nil))
+		return
+	}
+
+	for participant.CurrentPosition < len(quiz.Questions) {
+		question := &quiz.Questions[participant.CurrentPosition]
+
+		kind, err := questionkind.ForQuestion(question)
+		if err != nil {
+			middleware.Logger(c).Error("[challenge handler]: could not resolve question kind", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get question", nil))
+			return
+		}
+
+		answer := &models.GameAnswer{
+			ID:            utils.Uuid(),
+			SessionID:     session.ID,
+			ParticipantID: participant.ID,
+			QuestionID:    question.ID,
+			StartedAt:     now,
+		}
+
+		if err := h.answerRepo.Start(c.Request.Context(), answer); err != nil {
+			middleware.Logger(c).Error("[challenge handler]: could not start question", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get question", nil))
+			return
+		}
+
+		limit := game.TimeLimit(question.TimeLimitDuration)
+		if !game.Expired(answer.StartedAt, now, limit) {
+			var expiresAt *time.Time
+			if limit > 0 {
+				expiresAt = utils.Ptr(answer.StartedAt.Add(limit))
+			}
+
+			c.JSON(http.StatusOK, models.NewSuccessResponse("question retrieved successfully", gin.H{
+				"finished":        false,
+				"question":        kind.Prompt(question),
+				"question_number": participant.CurrentPosition + 1,
+				"total_questions": len(quiz.Questions),
+				"started_at":      answer.StartedAt,
+				"expires_at":      expiresAt,
+				"score":           participant.Score,
+				"streak":          participant.Streak,
+			}))
+			return
+		}
+
+		err = h.recordTimeout(c.Request.Context(), kind, answer, participant, len(quiz.Questions), now)
+		if err != nil {
+			if errors.Is(err, database.ErrGameAnswerRecorded) {
+				c.JSON(http.StatusConflict, models.NewErrorResponse("the question was answered from another request, please retry", nil))
+				return
+			}
+
+			middleware.Logger(c).Error("[challenge handler]: could not record timeout", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get question", nil))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("challenge completed", gin.H{
+		"finished":        true,
+		"total_questions": len(quiz.Questions),
+		"score":           participant.Score,
+		"correct_count":   participant.CorrectCount,
+	}))
+}
+
+func (h *challengeHandler) HandleSubmitAnswer(c *gin.Context) {
+	session, quiz, ok := h.loadChallenge(c)
+	if !ok {
+		return
+	}
+
+	participant, ok := h.loadParticipant(c, session)
+	if !ok {
+		return
+	}
+
+	var req models.SubmitAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		verr, _ := err.(*utils.ValidatorErrorBag)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+			models.NewErrorResponse(verr.Error(), verr.Errors))
+		return
+	}
+
+	now := time.Now()
+	if !session.IsOpen(now) {
+		c.JSON(http.StatusGone, models.NewErrorResponse("this challenge has closed", nil))
+		return
+	}
+
+	if participant.CurrentPosition >= len(quiz.Questions) {
+		c.JSON(http.StatusConflict, models.NewErrorResponse("you have already finished this challenge", nil))
+		return
+	}
+
+	question := &quiz.Questions[participant.CurrentPosition]
+	if question.ID != req.QuestionID {
+		c.JSON(http.StatusConflict, models.NewErrorResponse("this is not your current question", nil))
+		return
+	}
+
+	kind, err := questionkind.ForQuestion(question)
+	if err != nil {
+		middleware.Logger(c).Error("[challenge handler]: could not resolve question kind", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to submit answer", nil))
+		return
+	}
+
+	answer, err := h.answerRepo.FindOne(c.Request.Context(), &models.FindGameAnswerOptions{
+		ParticipantID: participant.ID,
+		QuestionID:    question.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGameAnswerNotFound) {
+			c.JSON(http.StatusConflict, models.NewErrorResponse("this question has not been started", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[challenge handler]: could not get answer", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to submit answer", nil))
+		return
+	}
+
+	limit := game.TimeLimit(question.TimeLimitDuration)
+	if game.Expired(answer.StartedAt, now, limit) {
+		err := h.recordTimeout(c.Request.Context(), kind, answer, participant, len(quiz.Questions), now)
+		if err != nil && !errors.Is(err, database.ErrGameAnswerRecorded) {
+			middleware.Logger(c).Error("[challenge handler]: could not record timeout", slog.Any("error", err))
+		}
+
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("time is up for this question", gin.H{
+			"reveal": kind.Reveal(question),
+		}))
+		return
+	}
+
+	var submitted questionkind.Answer
+	if err := json.Unmarshal(req.Answer, &submitted); err != nil || kind.CheckAnswer(question, &submitted) != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("the answer does not fit this question", nil))
+		return
+	}
+
+	result := kind.Score(question, &submitted)
+	points := game.Points(kind, result, now.Sub(answer.StartedAt), limit)
+
+	participant.Streak = game.Streak(kind, result, participant.Streak)
+	if kind.Scored() && result.Correct {
+		participant.CorrectCount++
+		points += game.StreakPoints(participant.Streak)
+	}
+
+	answer.Answer = req.Answer
+	answer.Correct = result.Correct
+	answer.Credit = result.Credit
+	answer.Points = points
+	answer.AnsweredAt = utils.Ptr(now)
+	answer.UpdatedAt = now
+
+	advance(participant, points, len(quiz.Questions), now)
+
+	if err := h.answerRepo.Record(c.Request.Context(), answer, participant); err != nil {
+		if errors.Is(err, database.ErrGameAnswerRecorded) {
+			c.JSON(http.StatusConflict, models.NewErrorResponse("this question has already been answered", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[challenge handler]: could not record answer", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to submit answer", nil))
+		return
+	}
+	metrics.Answers.WithLabelValues(models.GameModeChallenge.String()).Inc()
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("answer submitted successfully", gin.H{
+		"result":   result,
+		"points":   points,
+		"score":    participant.Score,
+		"streak":   participant.Streak,
+		"reveal":   kind.Reveal(question),
+		"finished": participant.FinishedAt != nil,
+	}))
+}
+
+func (h *challengeHandler) HandleGetLeaderboard(c *gin.Context) {
+	session, _, ok := h.loadChallenge(c)
+	if !ok {
+		return
+	}
+
+	leaderboard, err := h.participantRepo.Leaderboard(c.Request.Context(), session.ID)
+	if err != nil {
+		middleware.Logger(c).Error("[challenge handler]: could not get leaderboard", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get leaderboard", nil))
+		return
+	}
+
+	var teams []models.TeamLeaderboardEntry
+	if session.HasTeams() {
+		teams = game.TeamLeaderboard(session.Teams, leaderboard, session.TeamScoring)
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("leaderboard retrieved successfully", gin.H{
+		"players": leaderboard,
+		"teams":   teams,
+	}))
+}
+
+// HandleChooseTeam lets a player pick their own team in sessions where
+// players choose, as long as they have not answered a question yet.
+func (h *challengeHandler) HandleChooseTeam(c *gin.Context) {
+	session, _, ok := h.loadChallenge(c)
+	if !ok {
+		return
+	}
+
+	participant, ok := h.loadParticipant(c, session)
+	if !ok {
+		return
+	}
+
+	req, ok := bindAssignTeamRequest(c)
+	if !ok {
+		return
+	}
+
+	if session.TeamAssignment != models.TeamAssignmentPlayerChoice {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("teams are assigned by the host in this challenge", nil))
+		return
+	}
+
+	if participant.CurrentPosition > 0 {
+		c.JSON(http.StatusConflict, models.NewErrorResponse("teams cannot be changed once you have started playing", nil))
+		return
+	}
+
+	team := findTeam(session, req.TeamID)
+	if team == nil {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("team not found", nil))
+		return
+	}
+
+	participant.TeamID = utils.Ptr(team.ID)
+	participant.UpdatedAt = time.Now()
+
+	if err := h.participantRepo.AssignTeams(c.Request.Context(), []models.GameParticipant{*participant}); err != nil {
+		middleware.Logger(c).Error("[challenge handler]: could not assign team", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to choose team", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("team chosen successfully", participant))
+}
+
+// HandleAssignTeam lets the host move any player to a team, whichever way
+// teams are assigned in the session.
+func (h *challengeHandler) HandleAssignTeam(c *gin.Context) {
+	session, ok := h.loadHostedChallenge(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindAssignTeamRequest(c)
+	if !ok {
+		return
+	}
+
+	team := findTeam(session, req.TeamID)
+	if team == nil {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("team not found", nil))
+		return
+	}
+
+	participant, err := h.participantRepo.FindOne(c.Request.Context(), &models.FindGameParticipantOptions{
+		ID:        c.Param("participantid"),
+		SessionID: session.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGameParticipantNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("participant not found", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[challenge handler]: could not get participant", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to assign team", nil))
+		return
+	}
+
+	participant.TeamID = utils.Ptr(team.ID)
+	participant.UpdatedAt = time.Now()
+
+	if err := h.participantRepo.AssignTeams(c.Request.Context(), []models.GameParticipant{*participant}); err != nil {
+		middleware.Logger(c).Error("[challenge handler]: could not assign team", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to assign team", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("team assigned successfully", participant))
+}
+
+// HandleBalanceTeams evens out team sizes, placing players without a team
+// and moving as few of the others as possible.
+func (h *challengeHandler) HandleBalanceTeams(c *gin.Context) {
+	session, ok := h.loadHostedChallenge(c)
+	if !ok {
+		return
+	}
+
+	participants, err := h.participantRepo.FindAll(c.Request.Context(), session.ID)
+	if err != nil {
+		middleware.Logger(c).Error("[challenge handler]: could not get participants", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to balance teams", nil))
+		return
+	}
+
+	moved := game.Balance(session.Teams, participants)
+	now := time.Now()
+	for i := range moved {
+		moved[i].UpdatedAt = now
+	}
+
+	if err := h.participantRepo.AssignTeams(c.Request.Context(), moved); err != nil {
+		middleware.Logger(c).Error("[challenge handler]: could not assign teams", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to balance teams", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("teams balanced successfully", gin.H{
+		"moved":        moved,
+		"participants": participants,
+	}))
+}
+
+func (h *challengeHandler) recordTimeout(ctx context.Context,
+	kind questionkind.Kind,
+	answer *models.GameAnswer,
+	participant *models.GameParticipant,
+	totalQuestions int,
+	now time.Time,
+) error {
+	answer.TimedOut = true
+	answer.AnsweredAt = utils.Ptr(now)
+	answer.UpdatedAt = now
+
+	if kind.Scored() {
+		participant.Streak = 0
+	}
+	advance(participant, 0, totalQuestions, now)
+
+	return h.answerRepo.Record(ctx, answer, participant)
+}
+
+func advance(participant *models.GameParticipant, points, totalQuestions int, now time.Time) {
+	participant.Score += points
+	participant.CurrentPosition++
+	participant.UpdatedAt = now
+
+	if participant.CurrentPosition >= totalQuestions {
+		participant.FinishedAt = utils.Ptr(now)
+	}
+}
+
+func (h *challengeHandler) loadChallenge(c *gin.Context) (*models.GameSession, *models.Quiz, bool) {
+	session, err := h.sessionRepo.FindOne(c.Request.Context(), &models.FindGameSessionOptions{
+		Code: c.Param("code"),
+	})
+	if err == nil && session.Mode != models.GameModeChallenge {
+		err = database.ErrGameSessionNotFound
+	}
+
+	if err != nil {
+		if errors.Is(err, database.ErrGameSessionNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("challenge not found", nil))
+			return nil, nil, false
+		}
+
+		middleware.Logger(c).Error("[challenge handler]: could not get challenge", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get challenge", nil))
+		return nil, nil, false
+	}
+
+	quiz, err := h.quizRepo.FindOne(c.Request.Context(), &models.FindQuizOptions{
+		ID: session.QuizID,
+	})
+	if err != nil {
+		middleware.Logger(c).Error("[challenge handler]: could not get challenge quiz", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get challenge", nil))
+		return nil, nil, false
+	}
+
+	if quiz.IsHidden() {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("challenge not found", nil))
+		return nil, nil, false
+	}
+
+	return session, quiz, true
+}
+
+// loadHostedChallenge loads a team challenge for its host, other users
+// are told it does not exist.
+func (h *challengeHandler) loadHostedChallenge(c *gin.Context) (*models.GameSession, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[challenge handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return nil, false
+	}
+
+	session, _, ok := h.loadChallenge(c)
+	if !ok {
+		return nil, false
+	}
+
+	if session.HostID != user.ID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("challenge not found", nil))
+		return nil, false
+	}
+
+	if !session.HasTeams() {
+		c.JSON(http.StatusConflict, models.NewErrorResponse("this challenge is not played in teams", nil))
+		return nil, false
+	}
+
+	return session, true
+}
+
+// createGameSession saves a new session of the user's published quiz from
+// the route, with its teams and a fresh join code, writing the error
+// response and returning false when it cannot.
+func createGameSession(c *gin.Context,
+	quizRepo models.QuizRepository,
+	sessionRepo models.GameSessionRepository,
+	session *models.GameSession,
+	teams *models.TeamOptions,
+) bool {
+	if verrs := validateTeams(teams); len(verrs) > 0 {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.NewErrorResponse("The given input was invalid", verrs))
+		return false
+	}
+
+	quiz, err := quizRepo.FindOne(c.Request.Context(), &models.FindQuizOptions{
+		ID: c.Param("quizid"),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrQuizNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+			return false
+		}
+
+		middleware.Logger(c).Error("[game session]: could not get quiz", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to create game", nil))
+		return false
+	}
+
+	if quiz.OwnerID != session.HostID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+		return false
+	}
+
+	if quiz.PublishedAt == nil {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("only published quizzes can be played", nil))
+		return false
+	}
+
+	if quiz.IsHidden() {
+		c.JSON(http.StatusForbidden, models.NewErrorResponse("this quiz was hidden by a moderator", nil))
+		return false
+	}
+
+	session.QuizID = quiz.ID
+
+	if len(teams.Teams) > 0 {
+		session.TeamAssignment = teams.TeamAssignment
+		session.TeamScoring = teams.TeamScoring
+		for i, name := range teams.Teams {
+			session.Teams = append(session.Teams, models.GameTeam{
+				ID:        utils.Uuid(),
+				SessionID: session.ID,
+				Name:      name,
+				Position:  i,
+			})
+		}
+	}
+
+	for attempt := 0; attempt < joinCodeAttempts; attempt++ {
+		session.Code = game.NewJoinCode()
+		err = sessionRepo.Create(c.Request.Context(), session)
+		if !errors.Is(err, database.ErrGameSessionCodeTaken) {
+			break
+		}
+	}
+
+	if err != nil {
+		middleware.Logger(c).Error("[game session]: could not create game session", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to create game", nil))
+		return false
+	}
+
+	return true
+}
+
+func findTeam(session *models.GameSession, teamID string) *models.GameTeam {
+	for i := range session.Teams {
+		if session.Teams[i].ID == teamID {
+			return &session.Teams[i]
+		}
+	}
+	return nil
+}
+
+func bindAssignTeamRequest(c *gin.Context) (*models.AssignTeamRequest, bool) {
+	var req models.AssignTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return nil, false
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		verr, _ := err.(*utils.ValidatorErrorBag)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+			models.NewErrorResponse(verr.Error(), verr.Errors))
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// validateTeams checks the team options of a new challenge, defaulting to
+// auto balanced teams scored by their sum.
+func validateTeams(req *models.TeamOptions) map[string][]string {
+	verrs := map[string][]string{}
+
+	if len(req.Teams) == 0 {
+		if req.TeamAssignment != "" || req.TeamScoring != "" {
+			verrs["teams"] = []string{"The teams field is required when team options are given"}
+		}
+		return verrs
+	}
+
+	if len(req.Teams) < game.MinTeams || len(req.Teams) > game.MaxTeams {
+		verrs["teams"] = append(verrs["teams"], fmt.Sprintf("Between %d and %d teams are required", game.MinTeams, game.MaxTeams))
+	}
+
+	seen := make(map[string]bool, len(req.Teams))
+	for i, name := range req.Teams {
+		name = strings.TrimSpace(name)
+		req.Teams[i] = name
+
+		switch {
+		case name == "":
+			verrs["teams"] = append(verrs["teams"], "Team names may not be empty")
+		case len(name) > maxTeamNameLength:
+			verrs["teams"] = append(verrs["teams"], fmt.Sprintf("Team names may not be longer than %d characters", maxTeamNameLength))
+		case seen[strings.ToLower(name)]:
+			verrs["teams"] = append(verrs["teams"], fmt.Sprintf("The team name %q is used more than once", name))
+		}
+		seen[strings.ToLower(name)] = true
+	}
+
+	if req.TeamAssignment == "" {
+		req.TeamAssignment = models.TeamAssignmentAuto
+	}
+	if !req.TeamAssignment.IsValid() {
+		verrs["team_assignment"] = []string{"The team assignment must be manual, auto or player_choice"}
+	}
+
+	if req.TeamScoring == "" {
+		req.TeamScoring = models.TeamScoringSum
+	}
+	if !req.TeamScoring.IsValid() {
+		verrs["team_scoring"] = []string{"The team scoring must be sum, average or best"}
+	}
+
+	return verrs
+}
+
+func (h *challengeHandler) loadParticipant(c *gin.Context, session *models.GameSession) (*models.GameParticipant, bool) {
+	token := c.GetHeader(participantTokenHeader)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("join the challenge to play", nil))
+		return nil, false
+	}
+
+	participant, err := h.participantRepo.FindOne(c.Request.Context(), &models.FindGameParticipantOptions{
+		SessionID: session.ID,
+		TokenHash: game.HashToken(token),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGameParticipantNotFound) {
+			c.JSON(http.StatusUnauthorized, models.NewErrorResponse("join the challenge to play", nil))
+			return nil, false
+		}
+
+		middleware.Logger(c).Error("[challenge handler]: could not get participant", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get participant", nil))
+		return nil, false
+	}
+
+	return participant, true
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/health"
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+type healthHandler struct {
+	health *health.Health
+}
+
+func NewHealthHandler(health *health.Health) *healthHandler {
+	return &healthHandler{health: health}
+}
+
+// HandleLiveness only tells that the process is serving requests, it does
+// not look at dependencies so an outage does not get every instance
+// restarted.
+func (h *healthHandler) HandleLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, models.NewSuccessResponse("alive", nil))
+}
+
+func (h *healthHandler) HandleReadiness(c *gin.Context) {
+	statuses, ready := h.health.Ready(c.Request.Context())
+	if !ready {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, models.NewErrorResponse("not ready", statuses))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("ready", statuses))
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/pkg/jwt"
+)
+
+type jwksHandler struct {
+	tokenManager jwt.TokenManager
+}
+
+func NewJWKSHandler(tokenManager jwt.TokenManager) *jwksHandler {
+	return &jwksHandler{tokenManager: tokenManager}
+}
+
+// HandleGetJWKS publishes the keys tokens are verified with. It is served
+// bare, in the format verifiers expect, and can be cached for a while
+// since a new key is published before it signs anything.
+func (j *jwksHandler) HandleGetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, j.tokenManager.JWKS())
+}
@@ -0,0 +1,659 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/live"
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/pkg/jwt"
+	"github.com/oxiginedev/sabipass/internal/pkg/logger"
+	"github.com/oxiginedev/sabipass/internal/ratelimit"
+	"github.com/oxiginedev/sabipass/utils"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	socketMaxMessageSize = 64 << 10
+	socketWriteTimeout   = 10 * time.Second
+	// socketPingInterval must stay well below socketReadTimeout, clients
+	// answer every ping with a pong.
+	socketPingInterval  = 25 * time.Second
+	socketReadTimeout   = 60 * time.Second
+	socketActionTimeout = 10 * time.Second
+
+	presenterTokenQuery = "presenter_token"
+)
+
+type liveHandler struct {
+	userRepo     models.UserRepository
+	quizRepo     models.QuizRepository
+	sessionRepo  models.GameSessionRepository
+	auditRepo    models.GameAuditLogRepository
+	tokenManager jwt.TokenManager
+	engine       *live.Engine
+	hub          *live.Hub
+	limiter      ratelimit.Limiter
+	answerPolicy ratelimit.Policy
+}
+
+func NewLiveHandler(userRepo models.UserRepository,
+	quizRepo models.QuizRepository,
+	sessionRepo models.GameSessionRepository,
+	auditRepo models.GameAuditLogRepository,
+	tokenManager jwt.TokenManager,
+	engine *live.Engine,
+	hub *live.Hub,
+	limiter ratelimit.Limiter,
+	answerPolicy ratelimit.Policy,
+) *liveHandler {
+	return &liveHandler{
+		userRepo:     userRepo,
+		quizRepo:     quizRepo,
+		sessionRepo:  sessionRepo,
+		auditRepo:    auditRepo,
+		tokenManager: tokenManager,
+		engine:       engine,
+		hub:          hub,
+		limiter:      limiter,
+		answerPolicy: answerPolicy,
+	}
+}
+
+func (h *liveHandler) HandleCreateLiveGame(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[live handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	var req models.CreateLiveGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	session := &models.GameSession{
+		ID:     utils.Uuid(),
+		HostID: user.ID,
+		Mode:   models.GameModeLive,
+		Status: models.GameSessionStatusOpen,
+		Phase:  models.GamePhaseLobby,
+	}
+
+	if !createGameSession(c, h.quizRepo, h.sessionRepo, session, &req.TeamOptions) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("game created successfully", session))
+}
+
+func (h *liveHandler) HandleGetLiveGame(c *gin.Context) {
+	session, ok := h.loadLiveGame(c)
+	if !ok {
+		return
+	}
+
+	quiz, err := h.quizRepo.FindOne(c.Request.Context(), &models.FindQuizOptions{
+		ID: session.QuizID,
+	})
+	if err != nil {
+		middleware.Logger(c).Error("[live handler]: could not get game quiz", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get game", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("game retrieved successfully", gin.H{
+		"code":            session.Code,
+		"phase":           session.Phase,
+		"team_assignment": session.TeamAssignment,
+		"team_scoring":    session.TeamScoring,
+		"teams":           session.Teams,
+		"quiz": gin.H{
+			"title":          quiz.Title,
+			"description":    quiz.Description,
+			"cover_image":    quiz.CoverImage,
+			"question_count": len(quiz.Questions),
+		},
+	}))
+}
+
+// HandleGetAuditLog lists what was done to the game, by its host or by the
+// game itself, for the host to review.
+func (h *liveHandler) HandleGetAuditLog(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[live handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	session, ok := h.loadLiveGame(c)
+	if !ok {
+		return
+	}
+
+	if session.HostID != user.ID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("game not found", nil))
+		return
+	}
+
+	entries, err := h.auditRepo.FindAll(c.Request.Context(), session.ID)
+	if err != nil {
+		middleware.Logger(c).Error("[live handler]: could not get audit log", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get audit log", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("audit log retrieved successfully", entries))
+}
+
+// HandleCreatePresenterLink makes a link for a screen to present the game
+// with. The link works without signing in and replaces any earlier one.
+func (h *liveHandler) HandleCreatePresenterLink(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[live handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	session, ok := h.loadLiveGame(c)
+	if !ok {
+		return
+	}
+
+	if session.HostID != user.ID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("game not found", nil))
+		return
+	}
+
+	token, err := h.engine.NewPresenterToken(c.Request.Context(), session.ID, user.ID)
+	if err != nil {
+		middleware.Logger(c).Error("[live handler]: could not create presenter token", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to create presenter link", nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("presenter link created successfully", gin.H{
+		"token": token,
+		"path":  "/games/" + session.Code + "/ws?" + presenterTokenQuery + "=" + token,
+	}))
+}
+
+type socketMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// HandleLiveSocket upgrades to a WebSocket on which the game's state is
+// pushed after every change. Clients send JSON messages of the form
+// {"type": ..., "data": ...}:
+//
+//   - join: {"nickname", "team_id"} to play, answered with "joined"
+//     carrying the participant and its token
+//   - resume: {"token"} to carry on as the player the token was handed to
+//     after the connection dropped, answered with "resumed" and the state
+//   - host: {"access_token"} to control the game, unless the connection
+//     was opened with the host's bearer token
+//   - start and next: host only, to start the game and to move it along
+//   - pause, unpause, skip, extend {"seconds"}, kick and ban
+//     {"participant_id"}, lock, unlock and end: host only, to control the
+//     game, every one of them is kept in the game's audit log
+//   - assign_team {"participant_id", "team_id"} and balance_teams: host
+//     only, to place players in teams, which is how players get a team in
+//     games where the host assigns them; both are audited too
+//   - answer: {"question_id", "answer"}, answered with "answer_result"
+//   - pong: in reply to every "ping" to keep the connection open
+//
+// Connections opened with a presenter_token from a presenter link are
+// presenter screens. They are sent "presenter_state" messages, which add
+// how the room is answering to the state, and cannot take part in the
+// game.
+func (h *liveHandler) HandleLiveSocket(c *gin.Context) {
+	session, ok := h.loadLiveGame(c)
+	if !ok {
+		return
+	}
+
+	user, _ := middleware.GetUserFromContext(c)
+
+	presenter := false
+	if token := c.Query(presenterTokenQuery); token != "" {
+		if !live.IsPresenterToken(session, token) {
+			c.JSON(http.StatusUnauthorized, models.NewErrorResponse("this presenter link is no longer valid", nil))
+			return
+		}
+		presenter = true
+	}
+
+	server := websocket.Server{
+		// players join from the app and from casting screens, origins are
+		// not restricted
+		Handshake: func(*websocket.Config, *http.Request) error {
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			h.serveSocket(ws, session, user, presenter)
+		},
+	}
+
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *liveHandler) serveSocket(ws *websocket.Conn, session *models.GameSession, user *models.User, presenter bool) {
+	defer ws.Close()
+
+	ws.MaxPayloadBytes = socketMaxMessageSize
+	// the server's timeouts are meant for plain requests
+	_ = ws.SetDeadline(time.Time{})
+
+	// the socket outlives the request, only its logger is kept
+	ctx, cancel := context.WithCancel(logger.NewContext(context.Background(), logger.FromContext(ws.Request().Context())))
+	defer cancel()
+
+	client := live.NewClient()
+	if presenter {
+		client.SetPresenter()
+	} else if user != nil && user.ID == session.HostID {
+		client.SetHost(user.ID)
+	}
+
+	h.hub.Register(session.ID, client)
+	defer h.hub.Unregister(session.ID, client)
+
+	go h.writeSocket(ctx, cancel, ws, client)
+	go h.watchHost(ctx, session, client)
+
+	for {
+		_ = ws.SetReadDeadline(time.Now().Add(socketReadTimeout))
+
+		var msg socketMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			return
+		}
+
+		h.handleSocketMessage(ctx, session, user, client, &msg)
+	}
+}
+
+func (h *liveHandler) writeSocket(ctx context.Context, cancel context.CancelFunc, ws *websocket.Conn, client *live.Client) {
+	defer cancel()
+	// unblock the reader once writing fails
+	defer ws.Close()
+
+	ticker := time.NewTicker(socketPingInterval)
+	defer ticker.Stop()
+
+	for {
+		var msg live.Message
+		select {
+		case <-ctx.Done():
+			return
+		case msg = <-client.Replies:
+		case msg = <-client.States:
+		case <-ticker.C:
+			msg = live.Message{Type: "ping"}
+		}
+
+		_ = ws.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+		if err := websocket.JSON.Send(ws, msg); err != nil {
+			return
+		}
+	}
+}
+
+// watchHost keeps telling the game that its host is connected, so it can
+// pause once the host has been gone for too long.
+func (h *liveHandler) watchHost(ctx context.Context, session *models.GameSession, client *live.Client) {
+	ticker := time.NewTicker(live.HostHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		if client.IsHost() {
+			h.hostSeen(ctx, session)
+		}
+
+		select {
+		case <-ctx.Done():
+			if client.IsHost() {
+				// the host was last seen now, ctx is already done
+				h.hostSeen(context.WithoutCancel(ctx), session)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *liveHandler) hostSeen(ctx context.Context, session *models.GameSession) {
+	ctx, cancel := context.WithTimeout(ctx, socketActionTimeout)
+	defer cancel()
+
+	if err := h.engine.HostSeen(ctx, session.ID); err != nil && !errors.Is(err, live.ErrWrongPhase) {
+		logger.FromContext(ctx).Error("[live handler]: could not record host connection", slog.Any("error", err))
+	}
+}
+
+func (h *liveHandler) handleSocketMessage(ctx context.Context, session *models.GameSession, user *models.User, client *live.Client, msg *socketMessage) {
+	ctx, cancel := context.WithTimeout(ctx, socketActionTimeout)
+	defer cancel()
+
+	var err error
+	switch {
+	case msg.Type == "pong":
+		return
+	case client.IsPresenter():
+		err = errPresenter
+	case msg.Type == "join":
+		err = h.handleJoin(ctx, session, user, client, msg.Data)
+	case msg.Type == "resume":
+		err = h.handleResume(ctx, session, client, msg.Data)
+	case msg.Type == "host":
+		err = h.handleHost(ctx, session, client, msg.Data)
+	case isControlMessage(msg.Type):
+		err = h.handleControl(ctx, session, client, msg)
+	case msg.Type == "answer":
+		err = h.handleAnswer(ctx, session, client, msg.Data)
+	default:
+		err = errUnknownMessage
+	}
+
+	if err != nil {
+		client.Reply(live.Message{Type: "error", Data: gin.H{
+			"for":     msg.Type,
+			"message": socketErrorMessage(ctx, err),
+		}})
+	}
+}
+
+func (h *liveHandler) handleJoin(ctx context.Context, session *models.GameSession, user *models.User, client *live.Client, data json.RawMessage) error {
+	if client.Participant() != "" {
+		return errAlreadyJoined
+	}
+
+	var req models.JoinGameRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errMalformedMessage
+	}
+
+	var userID *string
+	if user != nil {
+		userID = utils.Ptr(user.ID)
+	}
+
+	participant, token, err := h.engine.Join(ctx, session.ID, userID, req.Nickname, req.TeamID)
+	if err != nil {
+		return err
+	}
+
+	client.SetParticipant(participant.ID)
+	client.Reply(live.Message{Type: "joined", Data: gin.H{
+		"participant": participant,
+		"token":       token,
+	}})
+	h.hub.Refresh(session.ID, client)
+
+	return nil
+}
+
+func (h *liveHandler) handleResume(ctx context.Context, session *models.GameSession, client *live.Client, data json.RawMessage) error {
+	if client.Participant() != "" {
+		return errAlreadyJoined
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil || req.Token == "" {
+		return errMalformedMessage
+	}
+
+	participant, err := h.engine.Resume(ctx, session.ID, req.Token)
+	if err != nil {
+		return err
+	}
+
+	client.SetParticipant(participant.ID)
+	client.Reply(live.Message{Type: "resumed", Data: gin.H{
+		"participant": participant,
+	}})
+	// the state carries the player's score, streak and whether the current
+	// question can still be answered
+	h.hub.Refresh(session.ID, client)
+
+	return nil
+}
+
+func (h *liveHandler) handleHost(ctx context.Context, session *models.GameSession, client *live.Client, data json.RawMessage) error {
+	var req struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errMalformedMessage
+	}
+
+	token, err := h.tokenManager.ValidateToken(req.AccessToken)
+	if err != nil || token.UserID != session.HostID {
+		return errNotHost
+	}
+
+	// the socket bypasses the auth middleware, so the host is checked the
+	// same way here
+	user, err := h.userRepo.FindOne(ctx, &models.FindUserOptions{ID: token.UserID})
+	if err != nil || user.TokenRevoked(token.IssuedAt) || !user.IsActive() {
+		return errNotHost
+	}
+
+	client.SetHost(token.UserID)
+	client.Reply(live.Message{Type: "hosting"})
+	h.hostSeen(ctx, session)
+	return nil
+}
+
+func isControlMessage(msgType string) bool {
+	switch msgType {
+	case "start", "next", "pause", "unpause", "skip", "extend", "kick", "ban", "lock", "unlock", "end", "assign_team", "balance_teams":
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *liveHandler) handleControl(ctx context.Context, session *models.GameSession, client *live.Client, msg *socketMessage) error {
+	hostID := client.Host()
+	if hostID == "" {
+		return errNotHost
+	}
+
+	switch msg.Type {
+	case "start":
+		return h.engine.Start(ctx, session.ID, hostID)
+	case "next":
+		return h.engine.Next(ctx, session.ID, hostID)
+	case "pause":
+		return h.engine.Pause(ctx, session.ID, hostID)
+	case "unpause":
+		return h.engine.Unpause(ctx, session.ID, hostID)
+	case "skip":
+		return h.engine.Skip(ctx, session.ID, hostID)
+	case "extend":
+		var req struct {
+			Seconds int `json:"seconds"`
+		}
+		if err := json.Unmarshal(msg.Data, &req); err != nil {
+			return errMalformedMessage
+		}
+
+		return h.engine.Extend(ctx, session.ID, hostID, time.Duration(req.Seconds)*time.Second)
+	case "kick", "ban":
+		var req struct {
+			ParticipantID string `json:"participant_id"`
+		}
+		if err := json.Unmarshal(msg.Data, &req); err != nil || req.ParticipantID == "" {
+			return errMalformedMessage
+		}
+
+		if msg.Type == "kick" {
+			return h.engine.Kick(ctx, session.ID, hostID, req.ParticipantID)
+		}
+		return h.engine.Ban(ctx, session.ID, hostID, req.ParticipantID)
+	case "lock", "unlock":
+		return h.engine.Lock(ctx, session.ID, hostID, msg.Type == "lock")
+	case "end":
+		return h.engine.End(ctx, session.ID, hostID)
+	case "assign_team":
+		var req struct {
+			ParticipantID string `json:"participant_id"`
+			TeamID        string `json:"team_id"`
+		}
+		if err := json.Unmarshal(msg.Data, &req); err != nil || req.ParticipantID == "" || req.TeamID == "" {
+			return errMalformedMessage
+		}
+
+		return h.engine.AssignTeam(ctx, session.ID, hostID, req.ParticipantID, req.TeamID)
+	case "balance_teams":
+		return h.engine.BalanceTeams(ctx, session.ID, hostID)
+	default:
+		return errUnknownMessage
+	}
+}
+
+func (h *liveHandler) handleAnswer(ctx context.Context, session *models.GameSession, client *live.Client, data json.RawMessage) error {
+	participantID := client.Participant()
+	if participantID == "" {
+		return errNotJoined
+	}
+
+	if err := h.limitAnswers(ctx, participantID); err != nil {
+		return err
+	}
+
+	var req models.SubmitAnswerRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errMalformedMessage
+	}
+
+	result, err := h.engine.Answer(ctx, session.ID, participantID, req.QuestionID, req.Answer)
+	if err != nil {
+		return err
+	}
+
+	client.Reply(live.Message{Type: "answer_result", Data: result})
+	return nil
+}
+
+// limitAnswers holds back players flooding the game with answers, going
+// by the player so reconnecting does not reset the limit.
+func (h *liveHandler) limitAnswers(ctx context.Context, participantID string) error {
+	if !h.answerPolicy.Enabled() {
+		return nil
+	}
+
+	result, err := h.limiter.Take(ctx, h.answerPolicy, "participant:"+participantID, 1)
+	if err != nil {
+		logger.FromContext(ctx).Error("[live handler]: could not take answer token", slog.Any("error", err))
+		return nil
+	}
+
+	if !result.Allowed {
+		metrics.RateLimited.WithLabelValues(h.answerPolicy.Name).Inc()
+		return errTooManyAnswers
+	}
+	return nil
+}
+
+func (h *liveHandler) loadLiveGame(c *gin.Context) (*models.GameSession, bool) {
+	session, err := h.sessionRepo.FindOne(c.Request.Context(), &models.FindGameSessionOptions{
+		Code: c.Param("code"),
+	})
+	if err == nil && session.Mode != models.GameModeLive {
+		err = database.ErrGameSessionNotFound
+	}
+
+	if err != nil {
+		if errors.Is(err, database.ErrGameSessionNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("game not found", nil))
+			return nil, false
+		}
+
+		middleware.Logger(c).Error("[live handler]: could not get game", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get game", nil))
+		return nil, false
+	}
+
+	return session, true
+}
+
+var (
+	errNotHost          = errors.New("only the host can do that")
+	errPresenter        = errors.New("presenter screens can only watch the game")
+	errNotJoined        = errors.New("join the game to play")
+	errAlreadyJoined    = errors.New("you have already joined this game")
+	errMalformedMessage = errors.New("the message could not be read")
+	errUnknownMessage   = errors.New("unknown message type")
+	errTooManyAnswers   = errors.New("too many answers, slow down")
+)
+
+// socketErrorMessage turns an error from handling a socket message into
+// the message shown to the player.
+func socketErrorMessage(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, errNotHost), errors.Is(err, errPresenter), errors.Is(err, errNotJoined), errors.Is(err, errAlreadyJoined),
+		errors.Is(err, errMalformedMessage), errors.Is(err, errUnknownMessage), errors.Is(err, errTooManyAnswers):
+		return err.Error()
+	case errors.Is(err, live.ErrNotFound):
+		return "game not found"
+	case errors.Is(err, live.ErrWrongPhase):
+		return "the game has moved on"
+	case errors.Is(err, live.ErrLobbyClosed):
+		return "the game has already started"
+	case errors.Is(err, live.ErrInvalidNickname):
+		return "the nickname must be between 1 and 30 characters"
+	case errors.Is(err, database.ErrNicknameTaken):
+		return "this nickname is already taken"
+	case errors.Is(err, game.ErrTeamRequired):
+		return "choose one of the game's teams"
+	case errors.Is(err, live.ErrWrongQuestion):
+		return "this is not the current question"
+	case errors.Is(err, live.ErrTimeUp):
+		return "time is up for this question"
+	case errors.Is(err, live.ErrInvalidAnswer):
+		return "the answer does not fit this question"
+	case errors.Is(err, live.ErrAlreadyAnswered):
+		return "this question has already been answered"
+	case errors.Is(err, live.ErrPaused):
+		return "the game is paused"
+	case errors.Is(err, live.ErrUnknownPlayer):
+		return "join the game to play"
+	case errors.Is(err, live.ErrNotHost):
+		return errNotHost.Error()
+	case errors.Is(err, live.ErrLobbyLocked):
+		return "the lobby is locked"
+	case errors.Is(err, live.ErrBanned):
+		return "you have been removed from this game"
+	case errors.Is(err, live.ErrPlayerNotFound):
+		return "player not found"
+	case errors.Is(err, live.ErrNoTimeLimit):
+		return "this question has no time limit"
+	case errors.Is(err, live.ErrInvalidExtend):
+		return "the time can be extended by 1 to 300 seconds"
+	case errors.Is(err, live.ErrNoTeams):
+		return "this game is not played in teams"
+	case errors.Is(err, live.ErrTeamNotFound):
+		return "team not found"
+	default:
+		logger.FromContext(ctx).Error("[live handler]: could not handle socket message", slog.Any("error", err))
+		return "something went wrong, please try again"
+	}
+}
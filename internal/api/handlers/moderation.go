@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/admin"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+var errReportTargetNotFound = errors.New("report target not found")
+
+type moderationHandler struct {
+	admin           *admin.Admin
+	quizRepo        models.QuizRepository
+	participantRepo models.GameParticipantRepository
+	reportRepo      models.ModerationReportRepository
+}
+
+func NewModerationHandler(admin *admin.Admin,
+	quizRepo models.QuizRepository,
+	participantRepo models.GameParticipantRepository,
+	reportRepo models.ModerationReportRepository,
+) *moderationHandler {
+	return &moderationHandler{
+		admin:           admin,
+		quizRepo:        quizRepo,
+		participantRepo: participantRepo,
+		reportRepo:      reportRepo,
+	}
+}
+
+// HandleCreateReport reports a public quiz or a player's nickname to the
+// moderators.
+func (m *moderationHandler) HandleCreateReport(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[moderation handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	var req models.CreateModerationReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		verr, _ := err.(*utils.ValidatorErrorBag)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+			models.NewErrorResponse(verr.Error(), verr.Errors))
+		return
+	}
+
+	authorID, err := m.reportTargetAuthor(c, req.TargetType, req.TargetID)
+	if err != nil {
+		if errors.Is(err, errReportTargetNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("the reported "+req.TargetType.String()+" was not found", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[moderation handler]: could not get report target", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to create report", nil))
+		return
+	}
+
+	if authorID != nil && *authorID == user.ID {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("you cannot report your own content", nil))
+		return
+	}
+
+	now := time.Now()
+	report := &models.ModerationReport{
+		ID:         utils.Uuid(),
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		AuthorID:   authorID,
+		ReporterID: utils.Ptr(user.ID),
+		Reason:     req.Reason,
+		Status:     models.ReportStatusOpen,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if req.Details != "" {
+		report.Details = utils.Ptr(req.Details)
+	}
+
+	if err := m.reportRepo.Create(c.Request.Context(), report); err != nil {
+		if errors.Is(err, database.ErrReportAlreadyOpen) {
+			c.JSON(http.StatusConflict, models.NewErrorResponse("you already reported this "+req.TargetType.String(), nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[moderation handler]: could not create report", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to create report", nil))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("report created successfully", report))
+}
+
+// reportTargetAuthor returns the user behind the content being reported.
+// Only quizzes anyone can play can be reported, private ones are left to
+// their owners.
+func (m *moderationHandler) reportTargetAuthor(c *gin.Context, targetType models.ReportTarget, targetID string) (*string, error) {
+	ctx := c.Request.Context()
+
+	if targetType == models.ReportTargetParticipant {
+		participant, err := m.participantRepo.FindOne(ctx, &models.FindGameParticipantOptions{ID: targetID})
+		if err != nil {
+			if errors.Is(err, database.ErrGameParticipantNotFound) {
+				return nil, errReportTargetNotFound
+			}
+			return nil, err
+		}
+		return participant.UserID, nil
+	}
+
+	quiz, err := m.quizRepo.FindOne(ctx, &models.FindQuizOptions{ID: targetID})
+	if err != nil {
+		if errors.Is(err, database.ErrQuizNotFound) {
+			return nil, errReportTargetNotFound
+		}
+		return nil, err
+	}
+
+	if quiz.Visibility != models.QuizVisibilityPublic || quiz.PublishedAt == nil || quiz.IsHidden() {
+		return nil, errReportTargetNotFound
+	}
+	return utils.Ptr(quiz.OwnerID), nil
+}
+
+// HandleListReports is the moderation queue, oldest reports first. It
+// shows open reports unless another status is asked for.
+func (m *moderationHandler) HandleListReports(c *gin.Context) {
+	status := models.ReportStatusOpen
+	if c.Query("status") != "" {
+		status = models.ReportStatus(c.Query("status"))
+	}
+
+	paginator := models.PaginatorFromContext(c)
+
+	reports, totalCount, err := m.reportRepo.FindAll(c.Request.Context(), &models.ListModerationReportOptions{
+		Status:     status,
+		TargetType: models.ReportTarget(c.Query("target_type")),
+		Paginator:  paginator,
+	})
+	if err != nil {
+		middleware.Logger(c).Error("[moderation handler]: could not get reports", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get reports", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		models.NewPaginatedResponse("reports retrieved successfully", reports, totalCount, paginator))
+}
+
+// HandleResolveReport settles a report, hiding the content or warning,
+// suspending or banning its author as the moderator decides.
+func (m *moderationHandler) HandleResolveReport(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[moderation handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	var req models.ResolveModerationReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		verr, _ := err.(*utils.ValidatorErrorBag)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+			models.NewErrorResponse(verr.Error(), verr.Errors))
+		return
+	}
+
+	report, err := m.reportRepo.FindOne(c.Request.Context(), c.Param("reportid"))
+	if err != nil {
+		if errors.Is(err, database.ErrReportNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("report not found", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[moderation handler]: could not get report", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to resolve report", nil))
+		return
+	}
+
+	err = m.admin.ResolveReport(c.Request.Context(), "user:"+user.ID, user.ID, report, req.Resolution, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, admin.ErrUnchanged):
+			c.JSON(http.StatusConflict, models.NewErrorResponse("the report was already resolved", nil))
+		case errors.Is(err, admin.ErrNoAuthor):
+			c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("the reported player has no account, hide their nickname instead", nil))
+		default:
+			middleware.Logger(c).Error("[moderation handler]: could not resolve report", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to resolve report", nil))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("report resolved successfully", report))
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -12,10 +13,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
 	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/metrics"
 	"github.com/oxiginedev/sabipass/internal/models"
 	"github.com/oxiginedev/sabipass/internal/pkg/jwt"
 	"github.com/oxiginedev/sabipass/utils"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -23,6 +27,7 @@ import (
 type oauthHandler struct {
 	cfg          *config.Config
 	googleConfig *oauth2.Config
+	httpClient   *http.Client
 	tokenManager jwt.TokenManager
 	userRepo     models.UserRepository
 }
@@ -42,6 +47,8 @@ func NewOauthHandler(cfg *config.Config, tokenManager jwt.TokenManager, userRepo
 	return &oauthHandler{
 		cfg:          cfg,
 		googleConfig: googleConfig,
+		// calls to google show up in the trace of the sign in
+		httpClient:   &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
 		tokenManager: tokenManager,
 		userRepo:     userRepo,
 	}
@@ -50,7 +57,7 @@ func NewOauthHandler(cfg *config.Config, tokenManager jwt.TokenManager, userRepo
 func (o *oauthHandler) HandleGoogleLoginRedirect(c *gin.Context) {
 	state, err := generateOauthStateCookie(c)
 	if err != nil {
-		slog.Error("could not generate oauth state cookie", slog.Any("error", err))
+		middleware.Logger(c).Error("could not generate oauth state cookie", slog.Any("error", err))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 		return
 	}
@@ -60,34 +67,51 @@ func (o *oauthHandler) HandleGoogleLoginRedirect(c *gin.Context) {
 }
 
 func (o *oauthHandler) HandleGoogleLoginCallback(c *gin.Context) {
+	outcome := metrics.OAuthError
+	defer func() {
+		metrics.OAuthLogins.WithLabelValues("google", outcome).Inc()
+	}()
+
 	state := c.Query("state")
 	oauthState, err := c.Cookie("oauth_state")
 	if err != nil {
-		slog.Error("could not get oauth state cookie", slog.Any("error", err))
+		middleware.Logger(c).Error("could not get oauth state cookie", slog.Any("error", err))
+		outcome = metrics.OAuthInvalidState
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 		return
 	}
 
 	if state != oauthState {
-		slog.Error("oauth state does not match", slog.String("state", state), slog.String("oauthState", oauthState))
+		middleware.Logger(c).Error("oauth state does not match", slog.String("state", state), slog.String("oauthState", oauthState))
+		outcome = metrics.OAuthInvalidState
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse("invalid oauth state", nil))
 		return
 	}
 
 	c.SetCookie("oauth_state", "", -1, "/", "", false, true)
 
+	ctx := context.WithValue(c.Request.Context(), oauth2.HTTPClient, o.httpClient)
+
 	code := c.Query("code")
-	token, err := o.googleConfig.Exchange(c.Request.Context(), code)
+	token, err := o.googleConfig.Exchange(ctx, code)
 	if err != nil {
-		slog.Error("could not exchange oauth code", slog.Any("error", err))
+		middleware.Logger(c).Error("could not exchange oauth code", slog.Any("error", err))
+		outcome = metrics.OAuthDenied
 		c.AbortWithStatusJSON(http.StatusBadRequest, models.NewErrorResponse("unable to verify sign in with google", nil))
 		return
 	}
 
-	client := o.googleConfig.Client(c.Request.Context(), token)
-	res, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
+	client := o.googleConfig.Client(ctx, token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.googleapis.com/oauth2/v2/userinfo", nil)
+	if err != nil {
+		middleware.Logger(c).Error("could not create userinfo request", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
+		return
+	}
+
+	res, err := client.Do(req)
 	if err != nil {
-		slog.Error("could not get userinfo", slog.Any("error", err))
+		middleware.Logger(c).Error("could not get userinfo", slog.Any("error", err))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 		return
 	}
@@ -95,7 +119,7 @@ func (o *oauthHandler) HandleGoogleLoginCallback(c *gin.Context) {
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		slog.Error("could not get google userinfo", slog.Int("status", res.StatusCode))
+		middleware.Logger(c).Error("could not get google userinfo", slog.Int("status", res.StatusCode))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 		return
 	}
@@ -103,7 +127,7 @@ func (o *oauthHandler) HandleGoogleLoginCallback(c *gin.Context) {
 	googleUser := models.GoogleUser{}
 	err = json.NewDecoder(res.Body).Decode(&googleUser)
 	if err != nil {
-		slog.Error("could not decode userinfo", slog.Any("error", err))
+		middleware.Logger(c).Error("could not decode userinfo", slog.Any("error", err))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 		return
 	}
@@ -112,7 +136,7 @@ func (o *oauthHandler) HandleGoogleLoginCallback(c *gin.Context) {
 		Email: googleUser.Email,
 	})
 	if err != nil && !errors.Is(err, database.ErrUserNotFound) {
-		slog.Error("could not find user", slog.Any("error", err))
+		middleware.Logger(c).Error("could not find user", slog.Any("error", err))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 		return
 	}
@@ -126,19 +150,27 @@ func (o *oauthHandler) HandleGoogleLoginCallback(c *gin.Context) {
 			EmailVerifiedAt: utils.Ptr(time.Now()),
 			GoogleID:        utils.Ptr(googleUser.ID),
 			Avatar:          utils.Ptr(googleUser.Picture),
+			Role:            models.UserRoleUser,
+			Status:          models.UserStatusActive,
 		}
 
 		err = o.userRepo.Create(c.Request.Context(), user)
 		if err != nil {
-			slog.Error("could not create user", slog.Any("error", err))
+			middleware.Logger(c).Error("could not create user", slog.Any("error", err))
 			c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 			return
 		}
 	}
 
+	if !user.IsActive() {
+		outcome = metrics.OAuthSuspended
+		c.AbortWithStatusJSON(http.StatusForbidden, models.NewErrorResponse("account "+user.Status.String(), nil))
+		return
+	}
+
 	accessToken, err := o.tokenManager.GenerateToken(user)
 	if err != nil {
-		slog.Error("could not generate access token", slog.Any("error", err))
+		middleware.Logger(c).Error("could not generate access token", slog.Any("error", err))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", nil))
 		return
 	}
@@ -148,6 +180,7 @@ func (o *oauthHandler) HandleGoogleLoginCallback(c *gin.Context) {
 		"token": accessToken,
 	}
 
+	outcome = metrics.OAuthSuccess
 	c.JSON(http.StatusOK, models.NewSuccessResponse("user auth successful", resp))
 }
 
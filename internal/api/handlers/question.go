@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/importer"
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/pkg/spreadsheet"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+const maxImportFileSize = 5 << 20
+
+type questionHandler struct {
+	quizRepo         models.QuizRepository
+	questionRepo     models.QuestionRepository
+	questionTypeRepo models.QuestionTypeRepository
+	uploadRepo       models.UploadRepository
+	queue            *jobs.Queue
+}
+
+func NewQuestionHandler(quizRepo models.QuizRepository,
+	questionRepo models.QuestionRepository,
+	questionTypeRepo models.QuestionTypeRepository,
+	uploadRepo models.UploadRepository,
+	queue *jobs.Queue,
+) *questionHandler {
+	return &questionHandler{
+		quizRepo:         quizRepo,
+		questionRepo:     questionRepo,
+		questionTypeRepo: questionTypeRepo,
+		uploadRepo:       uploadRepo,
+		queue:            queue,
+	}
+}
+
+// HandleImportQuestions accepts a csv, tsv or xlsx table either as an
+// uploaded "file" or pasted into the "content" form field. With dry_run
+// set it only returns the preview, otherwise every row is saved or none.
+func (q *questionHandler) HandleImportQuestions(c *gin.Context) {
+	_, quiz, ok := q.findOwnedQuiz(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	filename, content, err := readImportContent(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("a file or pasted content is required", nil))
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", c.PostForm("dry_run")))
+
+	// the header row comes on top of the questions
+	rows, err := spreadsheet.Read(filename, content, importer.MaxQuestionRows+1)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("could not read the uploaded table", err.Error()))
+		return
+	}
+
+	questionTypes, err := q.questionTypeRepo.FindAll(c.Request.Context())
+	if err != nil {
+		middleware.Logger(c).Error("[question handler]: could not get question types", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to import questions", nil))
+		return
+	}
+
+	preview, err := importer.ParseQuestions(rows, questionTypes)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("could not read the uploaded table", err.Error()))
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, models.NewSuccessResponse("import preview generated", preview))
+		return
+	}
+
+	if preview.HasErrors() {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("some rows could not be imported", preview))
+		return
+	}
+
+	questions := preview.Questions(quiz.ID, len(quiz.Questions))
+	if err := q.questionRepo.CreateMany(c.Request.Context(), questions); err != nil {
+		middleware.Logger(c).Error("[question handler]: could not import questions", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to import questions", nil))
+		return
+	}
+
+	// imported questions go live in a public quiz straight away, so they
+	// are classified like a newly published quiz
+	enqueueClassification(c, q.queue, quiz)
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("questions imported successfully", questions))
+}
+
+func (q *questionHandler) HandleAttachUpload(c *gin.Context) {
+	user, quiz, ok := q.findOwnedQuiz(c)
+	if !ok {
+		return
+	}
+
+	question, ok := q.findQuestion(c, quiz)
+	if !ok {
+		return
+	}
+
+	var req models.AttachUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		verr, _ := err.(*utils.ValidatorErrorBag)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+			models.NewErrorResponse(verr.Error(), verr.Errors))
+		return
+	}
+
+	upload, err := findOwnedUpload(c, q.uploadRepo, user, req.UploadID, models.UploadKindImage, models.UploadKindAudio)
+	if err != nil {
+		if errors.Is(err, database.ErrUploadNotFound) || errors.Is(err, errUploadKindNotAllowed) {
+			c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("the upload must be an image or audio file you uploaded", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[question handler]: could not get upload", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to attach upload", nil))
+		return
+	}
+
+	question.AttachmentID = utils.Ptr(upload.ID)
+	question.Attachment = upload
+
+	if err := q.questionRepo.Update(c.Request.Context(), question); err != nil {
+		middleware.Logger(c).Error("[question handler]: could not update question", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to attach upload", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("attachment added successfully", question))
+}
+
+func (q *questionHandler) HandleDetachUpload(c *gin.Context) {
+	_, quiz, ok := q.findOwnedQuiz(c)
+	if !ok {
+		return
+	}
+
+	question, ok := q.findQuestion(c, quiz)
+	if !ok {
+		return
+	}
+
+	question.AttachmentID = nil
+	question.Attachment = nil
+
+	if err := q.questionRepo.Update(c.Request.Context(), question); err != nil {
+		middleware.Logger(c).Error("[question handler]: could not update question", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to remove attachment", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("attachment removed successfully", question))
+}
+
+// findOwnedQuiz loads the quiz from the route, writing the error response
+// and returning false when it does not exist or belongs to another user.
+func (q *questionHandler) findOwnedQuiz(c *gin.Context) (*models.User, *models.Quiz, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[question handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return nil, nil, false
+	}
+
+	quiz, err := q.quizRepo.FindOne(c.Request.Context(), &models.FindQuizOptions{
+		ID: c.Param("quizid"),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrQuizNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+			return nil, nil, false
+		}
+
+		middleware.Logger(c).Error("[question handler]: could not get quiz", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get quiz", nil))
+		return nil, nil, false
+	}
+
+	if quiz.OwnerID != user.ID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+		return nil, nil, false
+	}
+
+	return user, quiz, true
+}
+
+func (q *questionHandler) findQuestion(c *gin.Context, quiz *models.Quiz) (*models.Question, bool) {
+	question, err := q.questionRepo.FindOne(c.Request.Context(), &models.FindQuestionOptions{
+		ID:     c.Param("questionid"),
+		QuizID: quiz.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrQuestionNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("question not found", nil))
+			return nil, false
+		}
+
+		middleware.Logger(c).Error("[question handler]: could not get question", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get question", nil))
+		return nil, false
+	}
+
+	return question, true
+}
+
+func readImportContent(c *gin.Context) (string, []byte, error) {
+	fileHeader, err := c.FormFile("file")
+	if err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		defer file.Close()
+
+		content, err := io.ReadAll(file)
+		return fileHeader.Filename, content, err
+	}
+
+	if content := c.PostForm("content"); content != "" {
+		return "", []byte(content), nil
+	}
+
+	return "", nil, http.ErrMissingFile
+}
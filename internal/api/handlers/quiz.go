@@ -4,28 +4,36 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oxiginedev/sabipass/internal/api/middleware"
 	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/jobs"
 	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/moderation"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
 	"github.com/oxiginedev/sabipass/utils"
 )
 
 type quizHandler struct {
-	quizRepo models.QuizRepository
+	quizRepo   models.QuizRepository
+	uploadRepo models.UploadRepository
+	queue      *jobs.Queue
 }
 
-func NewQuizHandler(quizRepo models.QuizRepository) *quizHandler {
+func NewQuizHandler(quizRepo models.QuizRepository, uploadRepo models.UploadRepository, queue *jobs.Queue) *quizHandler {
 	return &quizHandler{
-		quizRepo: quizRepo,
+		quizRepo:   quizRepo,
+		uploadRepo: uploadRepo,
+		queue:      queue,
 	}
 }
 
 func (q *quizHandler) HandleCreateQuiz(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		slog.Error("[quiz handler]: could not get user from context")
+		middleware.Logger(c).Error("[quiz handler]: could not get user from context")
 		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
 		return
 	}
@@ -44,21 +52,47 @@ func (q *quizHandler) HandleCreateQuiz(c *gin.Context) {
 		return
 	}
 
+	if req.CoverImage != nil {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.NewErrorResponse("The given input was invalid", map[string][]string{
+			"cover_image": {"Cover image urls are no longer accepted, upload the image and send its cover_image_id"},
+		}))
+		return
+	}
+
 	quiz := &models.Quiz{
 		ID:          utils.Uuid(),
 		OwnerID:     user.ID,
 		Title:       req.Title,
 		Description: utils.Ptr(req.Description),
 		Visibility:  models.QuizVisibility(req.Visibility),
-		CoverImage:  utils.Ptr(req.CoverImage),
+	}
+
+	if req.CoverImageID != "" {
+		upload, err := findOwnedUpload(c, q.uploadRepo, user, req.CoverImageID, models.UploadKindImage)
+		if err != nil {
+			if errors.Is(err, database.ErrUploadNotFound) || errors.Is(err, errUploadKindNotAllowed) {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.NewErrorResponse("The given input was invalid", map[string][]string{
+					"cover_image_id": {"The cover image must be an image you uploaded"},
+				}))
+				return
+			}
+
+			middleware.Logger(c).Error("[quiz handler]: could not get cover image upload", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to create quiz", nil))
+			return
+		}
+
+		quiz.CoverImage = utils.Ptr(upload.URL)
 	}
 
 	if err := q.quizRepo.Create(c.Request.Context(), quiz); err != nil {
-		slog.Error("[quiz handler]: could not create quiz", slog.Any("error", err))
+		middleware.Logger(c).Error("[quiz handler]: could not create quiz", slog.Any("error", err))
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to create quiz", nil))
 		return
 	}
 
+	enqueueClassification(c, q.queue, quiz)
+
 	c.JSON(http.StatusCreated, models.NewSuccessResponse("quiz created successfully", quiz))
 }
 
@@ -74,14 +108,14 @@ func (q *quizHandler) HandleGetQuiz(c *gin.Context) {
 			return
 		}
 
-		slog.Error("[quiz handler]: could not get quiz", slog.Any("error", err))
+		middleware.Logger(c).Error("[quiz handler]: could not get quiz", slog.Any("error", err))
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get quiz", nil))
 		return
 	}
 
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		slog.Error("[quiz handler]: could not get user from context")
+		middleware.Logger(c).Error("[quiz handler]: could not get user from context")
 		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
 		return
 	}
@@ -100,7 +134,7 @@ func (q *quizHandler) HandleGetAllQuizzes(c *gin.Context) {
 
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		slog.Error("[quiz handler]: could not get user from context")
+		middleware.Logger(c).Error("[quiz handler]: could not get user from context")
 		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
 		return
 	}
@@ -114,7 +148,7 @@ func (q *quizHandler) HandleGetAllQuizzes(c *gin.Context) {
 		Paginator:  paginator,
 	})
 	if err != nil {
-		slog.Error("[quiz handler]: could not get quizzes", slog.Any("error", err))
+		middleware.Logger(c).Error("[quiz handler]: could not get quizzes", slog.Any("error", err))
 		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get quizzes", nil))
 		return
 	}
@@ -124,3 +158,80 @@ func (q *quizHandler) HandleGetAllQuizzes(c *gin.Context) {
 }
 
 func (q *quizHandler) HandleEditQuiz(c *gin.Context) {}
+
+// HandlePublishQuiz makes a quiz playable once every question passes the
+// validation of its question kind.
+func (q *quizHandler) HandlePublishQuiz(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[quiz handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	quiz, err := q.quizRepo.FindOne(c.Request.Context(), &models.FindQuizOptions{
+		ID: c.Param("quizid"),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrQuizNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[quiz handler]: could not get quiz", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to publish quiz", nil))
+		return
+	}
+
+	if quiz.OwnerID != user.ID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("quiz not found", nil))
+		return
+	}
+
+	if len(quiz.Questions) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("a quiz needs at least one question to be published", nil))
+		return
+	}
+
+	verrs := make(map[string][]string)
+	for _, question := range quiz.Questions {
+		kind, err := questionkind.ForQuestion(&question)
+		if err == nil {
+			err = kind.Validate(&question)
+		}
+
+		if err != nil {
+			verrs[question.ID] = append(verrs[question.ID], err.Error())
+		}
+	}
+
+	if len(verrs) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("some questions are not ready to be played", verrs))
+		return
+	}
+
+	quiz.PublishedAt = utils.Ptr(time.Now())
+	if err := q.quizRepo.Update(c.Request.Context(), quiz); err != nil {
+		middleware.Logger(c).Error("[quiz handler]: could not publish quiz", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to publish quiz", nil))
+		return
+	}
+
+	enqueueClassification(c, q.queue, quiz)
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("quiz published successfully", quiz))
+}
+
+// enqueueClassification queues a public quiz for the content classifier,
+// once it is published or its questions change. The quiz stays up while it
+// waits and moderators decide on anything it flags.
+func enqueueClassification(c *gin.Context, queue *jobs.Queue, quiz *models.Quiz) {
+	if quiz.Visibility != models.QuizVisibilityPublic {
+		return
+	}
+
+	err := moderation.ClassifyJob.Enqueue(c.Request.Context(), queue, moderation.ClassifyArgs{QuizID: quiz.ID})
+	if err != nil {
+		middleware.Logger(c).Error("[quiz classification]: could not enqueue classification", slog.String("quiz_id", quiz.ID), slog.Any("error", err))
+	}
+}
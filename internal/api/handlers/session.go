@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/report"
+)
+
+type sessionHandler struct {
+	sessionRepo models.GameSessionRepository
+	reporter    *report.Reporter
+}
+
+func NewSessionHandler(sessionRepo models.GameSessionRepository, reporter *report.Reporter) *sessionHandler {
+	return &sessionHandler{
+		sessionRepo: sessionRepo,
+		reporter:    reporter,
+	}
+}
+
+type sessionHistoryEntry struct {
+	*models.GameSession
+	// Role is host for the games the user hosted and player for the ones
+	// they played.
+	Role      string `json:"role"`
+	QuizTitle string `json:"quiz_title"`
+	Finished  bool   `json:"finished"`
+}
+
+// HandleListSessions lists the games the user hosted or played, newest
+// first, with the summary of the ones that are over.
+func (h *sessionHandler) HandleListSessions(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[session handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	paginator := models.PaginatorFromContext(c)
+
+	sessions, totalCount, err := h.sessionRepo.FindAll(c.Request.Context(), &models.ListGameSessionOptions{
+		UserID:    user.ID,
+		Paginator: paginator,
+	})
+	if err != nil {
+		middleware.Logger(c).Error("[session handler]: could not get sessions", slog.Any("error", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get sessions", nil))
+		return
+	}
+
+	now := time.Now()
+	entries := make([]sessionHistoryEntry, 0, len(sessions))
+	for i := range sessions {
+		session := &sessions[i]
+
+		entry := sessionHistoryEntry{GameSession: session, Role: "player"}
+		if session.HostID == user.ID {
+			entry.Role = "host"
+		}
+		if session.Quiz != nil {
+			entry.QuizTitle = session.Quiz.Title
+		}
+		_, entry.Finished = report.FinishedAt(session, now)
+
+		entries = append(entries, entry)
+	}
+
+	c.JSON(http.StatusOK,
+		models.NewPaginatedResponse("sessions retrieved successfully", entries, totalCount, paginator))
+}
+
+// HandleGetSessionReport reviews a finished game for its host, as JSON or,
+// with ?format=csv, as a spreadsheet of every player's answers.
+func (h *sessionHandler) HandleGetSessionReport(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[session handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	session, err := h.sessionRepo.FindOne(c.Request.Context(), &models.FindGameSessionOptions{
+		ID: c.Param("sessionid"),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGameSessionNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse("session not found", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[session handler]: could not get session", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get session", nil))
+		return
+	}
+
+	if session.HostID != user.ID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse("session not found", nil))
+		return
+	}
+
+	rep, err := h.reporter.Summarize(c.Request.Context(), session)
+	if err != nil {
+		if errors.Is(err, report.ErrNotFinished) {
+			c.JSON(http.StatusConflict, models.NewErrorResponse("the session has not finished yet", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[session handler]: could not build session report", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get session report", nil))
+		return
+	}
+
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, models.NewSuccessResponse("session report retrieved successfully", rep))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := rep.WriteCSV(&buf); err != nil {
+		middleware.Logger(c).Error("[session handler]: could not write session report", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to get session report", nil))
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="sabipass-`+session.Code+`.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/media"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/storage"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+var uploadPurposeKinds = map[models.UploadPurpose][]models.UploadKind{
+	models.UploadPurposeAvatar:             {models.UploadKindImage},
+	models.UploadPurposeQuizCover:          {models.UploadKindImage},
+	models.UploadPurposeQuestionAttachment: {models.UploadKindImage, models.UploadKindAudio},
+}
+
+type uploadHandler struct {
+	limits     media.Limits
+	blobStore  storage.BlobStore
+	queue      *jobs.Queue
+	uploadRepo models.UploadRepository
+}
+
+func NewUploadHandler(cfg *config.Config, blobStore storage.BlobStore, queue *jobs.Queue, uploadRepo models.UploadRepository) *uploadHandler {
+	return &uploadHandler{
+		limits: media.Limits{
+			MaxImageSize: cfg.Storage.MaxImageSize,
+			MaxAudioSize: cfg.Storage.MaxAudioSize,
+		},
+		blobStore:  blobStore,
+		queue:      queue,
+		uploadRepo: uploadRepo,
+	}
+}
+
+func (u *uploadHandler) HandleCreateUpload(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		middleware.Logger(c).Error("[upload handler]: could not get user from context")
+		c.JSON(http.StatusUnauthorized, models.NewErrorResponse("unauthorized", nil))
+		return
+	}
+
+	// leave room for the multipart envelope around the file itself
+	maxSize := max(u.limits.MaxImageSize, u.limits.MaxAudioSize)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	purpose, err := models.ParseUploadPurpose(c.PostForm("purpose"))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("The given input was invalid", map[string][]string{
+			"purpose": {"The purpose must be avatar, quiz_cover or question_attachment"},
+		}))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("a file is required", nil))
+		return
+	}
+
+	if fileHeader.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.NewErrorResponse("the uploaded file is too large", nil))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		middleware.Logger(c).Error("[upload handler]: could not open uploaded file", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("could not read the uploaded file", nil))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		middleware.Logger(c).Error("[upload handler]: could not read uploaded file", slog.Any("error", err))
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("could not read the uploaded file", nil))
+		return
+	}
+
+	processed, err := media.Process(data, uploadPurposeKinds[purpose], u.limits)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, models.NewErrorResponse("the uploaded file is too large", nil))
+		case errors.Is(err, media.ErrUnsupportedType), errors.Is(err, media.ErrInvalidImage):
+			c.JSON(http.StatusUnsupportedMediaType, models.NewErrorResponse("the uploaded file type is not supported", nil))
+		default:
+			middleware.Logger(c).Error("[upload handler]: could not process uploaded file", slog.Any("error", err))
+			c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to upload file", nil))
+		}
+		return
+	}
+
+	upload := &models.Upload{
+		ID:          utils.Uuid(),
+		OwnerID:     user.ID,
+		Purpose:     purpose,
+		Kind:        processed.Kind,
+		ContentType: processed.ContentType,
+		Size:        int64(len(processed.Data)),
+	}
+
+	prefix := fmt.Sprintf("uploads/%s/%s", time.Now().UTC().Format("2006/01"), upload.ID)
+	upload.Key = prefix + "." + processed.Extension
+	upload.URL = u.blobStore.URL(upload.Key)
+
+	if processed.Kind == models.UploadKindImage {
+		upload.Width = utils.Ptr(processed.Width)
+		upload.Height = utils.Ptr(processed.Height)
+	}
+
+	ctx := c.Request.Context()
+	if err := u.blobStore.Put(ctx, upload.Key, bytes.NewReader(processed.Data), upload.Size, upload.ContentType); err != nil {
+		middleware.Logger(c).Error("[upload handler]: could not store upload", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to upload file", nil))
+		return
+	}
+
+	if err := u.uploadRepo.Create(ctx, upload); err != nil {
+		middleware.Logger(c).Error("[upload handler]: could not create upload", slog.Any("error", err))
+		u.deleteBlobs(c, upload)
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to upload file", nil))
+		return
+	}
+
+	// the upload is usable without its thumbnail, thumbnail_url stays
+	// empty until the job has run
+	if upload.Kind == models.UploadKindImage {
+		err := media.ThumbnailJob.Enqueue(ctx, u.queue, media.ThumbnailArgs{UploadID: upload.ID})
+		if err != nil {
+			middleware.Logger(c).Error("[upload handler]: could not enqueue thumbnail", slog.String("upload_id", upload.ID), slog.Any("error", err))
+		}
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("file uploaded successfully", upload))
+}
+
+func (u *uploadHandler) deleteBlobs(c *gin.Context, upload *models.Upload) {
+	keys := []string{upload.Key}
+	if upload.ThumbnailKey != nil {
+		keys = append(keys, *upload.ThumbnailKey)
+	}
+
+	for _, key := range keys {
+		if err := u.blobStore.Delete(c.Request.Context(), key); err != nil {
+			middleware.Logger(c).Error("[upload handler]: could not delete orphaned blob", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
+
+// findOwnedUpload resolves an upload id from a request body to an upload
+// of the given kind owned by user.
+func findOwnedUpload(c *gin.Context, uploadRepo models.UploadRepository, user *models.User, uploadID string, kinds ...models.UploadKind) (*models.Upload, error) {
+	upload, err := uploadRepo.FindOne(c.Request.Context(), &models.FindUploadOptions{
+		ID:      uploadID,
+		OwnerID: user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, kind := range kinds {
+		if upload.Kind == kind {
+			return upload, nil
+		}
+	}
+
+	return nil, errUploadKindNotAllowed
+}
+
+var errUploadKindNotAllowed = errors.New("upload kind not allowed")
@@ -1,20 +1,26 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oxiginedev/sabipass/internal/api/middleware"
+	"github.com/oxiginedev/sabipass/internal/database"
 	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
 )
 
 type userHandler struct {
-	userRepo models.UserRepository
+	userRepo   models.UserRepository
+	uploadRepo models.UploadRepository
 }
 
-func NewUserHandler(userRepo models.UserRepository) *userHandler {
+func NewUserHandler(userRepo models.UserRepository, uploadRepo models.UploadRepository) *userHandler {
 	return &userHandler{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		uploadRepo: uploadRepo,
 	}
 }
 
@@ -27,3 +33,50 @@ func (u *userHandler) HandleGetCurrentUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse("user profile retrieved", user))
 }
+
+func (u *userHandler) HandleUpdateAvatar(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewErrorResponse("unauthenticated", nil))
+		return
+	}
+
+	var req models.AttachUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse("invalid request body", nil))
+		return
+	}
+
+	err := utils.Validate(req)
+	if err != nil {
+		verr, _ := err.(*utils.ValidatorErrorBag)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+			models.NewErrorResponse(verr.Error(), verr.Errors))
+		return
+	}
+
+	upload, err := findOwnedUpload(c, u.uploadRepo, user, req.UploadID, models.UploadKindImage)
+	if err != nil {
+		if errors.Is(err, database.ErrUploadNotFound) || errors.Is(err, errUploadKindNotAllowed) {
+			c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse("the upload must be an image you uploaded", nil))
+			return
+		}
+
+		middleware.Logger(c).Error("[user handler]: could not get upload", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to update avatar", nil))
+		return
+	}
+
+	user.Avatar = utils.Ptr(upload.URL)
+	if upload.ThumbnailURL != nil {
+		user.Avatar = upload.ThumbnailURL
+	}
+
+	if err := u.userRepo.Update(c.Request.Context(), user); err != nil {
+		middleware.Logger(c).Error("[user handler]: could not update user", slog.Any("error", err))
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse("failed to update avatar", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("avatar updated successfully", user))
+}
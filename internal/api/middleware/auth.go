@@ -22,14 +22,14 @@ func RequireAuth(tokenManager jwt.TokenManager, userRepo models.UserRepository)
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if sidekik.IsStringEmpty(authHeader) {
-			slog.Info("[middleware]: empty authorization header")
+			Logger(c).Info("[middleware]: empty authorization header")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewErrorResponse("unauthenticated", nil))
 			return
 		}
 
 		parts := strings.Fields(authHeader)
-		if len(parts) != 2 && strings.ToLower(parts[0]) != "bearer" {
-			slog.Error("[middleware]: malformed authorization header", slog.String("authHeader", authHeader))
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			Logger(c).Error("[middleware]: malformed authorization header")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewErrorResponse("unauthenticated", nil))
 			return
 		}
@@ -43,7 +43,7 @@ func RequireAuth(tokenManager jwt.TokenManager, userRepo models.UserRepository)
 				return
 			}
 
-			slog.Error("[middleware]: invalid token", slog.Any("error", err))
+			Logger(c).Error("[middleware]: invalid token", slog.Any("error", err))
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewErrorResponse("unauthenticated", nil))
 			return
 		}
@@ -52,12 +52,78 @@ func RequireAuth(tokenManager jwt.TokenManager, userRepo models.UserRepository)
 			ID: validatedToken.UserID,
 		})
 		if err != nil {
-			slog.Error("[middleware]: could not find user", slog.Any("error", err))
+			Logger(c).Error("[middleware]: could not find user", slog.Any("error", err))
 			c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewErrorResponse("unauthenticated", nil))
 			return
 		}
 
-		c.Set(userKey, user)
+		if user.TokenRevoked(validatedToken.IssuedAt) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewErrorResponse("unauthenticated", nil))
+			return
+		}
+
+		if !user.IsActive() {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewErrorResponse("account "+user.Status.String(), nil))
+			return
+		}
+
+		setUser(c, user)
+		c.Next()
+	}
+}
+
+// OptionalAuth loads the user when a valid bearer token is sent but lets
+// anonymous requests through, for routes guests can use too.
+func OptionalAuth(tokenManager jwt.TokenManager, userRepo models.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Fields(c.GetHeader("Authorization"))
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			c.Next()
+			return
+		}
+
+		validatedToken, err := tokenManager.ValidateToken(parts[1])
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		user, err := userRepo.FindOne(c.Request.Context(), &models.FindUserOptions{
+			ID: validatedToken.UserID,
+		})
+		if err != nil {
+			Logger(c).Error("[middleware]: could not find user", slog.Any("error", err))
+			c.Next()
+			return
+		}
+
+		// revoked tokens and suspended users carry on as guests
+		if user.TokenRevoked(validatedToken.IssuedAt) || !user.IsActive() {
+			c.Next()
+			return
+		}
+
+		setUser(c, user)
+		c.Next()
+	}
+}
+
+// setUser makes the user available to the handlers and adds them to the
+// request logger.
+func setUser(c *gin.Context, user *models.User) {
+	c.Set(userKey, user)
+	setLogger(c, Logger(c).With(slog.String("user_id", user.ID)))
+}
+
+// RequireAdmin lets admins through, it runs after RequireAuth.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := GetUserFromContext(c)
+		if !ok || !user.IsAdmin() {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.NewErrorResponse("forbidden", nil))
+			return
+		}
+
 		c.Next()
 	}
 }
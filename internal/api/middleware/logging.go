@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/pkg/logger"
+	"github.com/oxiginedev/sabipass/utils"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDKey contextKey = "request_id"
+
+	// maxRequestIDLength keeps clients from filling the logs through the
+	// request id header.
+	maxRequestIDLength = 128
+)
+
+// RequestID keeps the request id sent by a proxy or client, or assigns a
+// new one, and echoes it back in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = utils.Uuid()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Logger returns the logger of the request, it carries the request id,
+// route and, once authenticated, the user id.
+func Logger(c *gin.Context) *slog.Logger {
+	return logger.FromContext(c.Request.Context())
+}
+
+func setLogger(c *gin.Context, l *slog.Logger) {
+	c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), l))
+}
+
+// RequestLogger puts a request logger in the request context and logs the
+// request once it is done. Only sampleRate of the successful requests are
+// logged, failed ones always are.
+func RequestLogger(sampleRate float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		attrs := []any{
+			slog.String("request_id", GetRequestID(c)),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+		}
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+		}
+		setLogger(c, slog.Default().With(attrs...))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && rand.Float64() >= sampleRate {
+			return
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		Logger(c).LogAttrs(c.Request.Context(), level, "request",
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("size", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// Recovery turns a panic in a handler into a 500 with the request id, so
+// the failure can be found in the logs.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err any) {
+		Logger(c).Error("[middleware]: recovered from panic",
+			slog.String("panic", fmt.Sprint(err)),
+			slog.String("stack", string(debug.Stack())),
+		)
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, models.NewErrorResponse("something went wrong", map[string]string{
+			"request_id": GetRequestID(c),
+		}))
+	})
+}
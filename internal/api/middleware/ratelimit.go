@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/ratelimit"
+)
+
+// RateLimitKey picks the bucket a request takes from.
+type RateLimitKey func(c *gin.Context) string
+
+// ByIP gives every client address its own bucket.
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser gives every signed in user their own bucket, guests are limited
+// by address.
+func ByUser(c *gin.Context) string {
+	user, ok := GetUserFromContext(c)
+	if !ok {
+		return ByIP(c)
+	}
+	return "user:" + user.ID
+}
+
+// ByRoute gives every route its own bucket for each key.
+func ByRoute(key RateLimitKey) RateLimitKey {
+	return func(c *gin.Context) string {
+		return c.FullPath() + "|" + key(c)
+	}
+}
+
+// RateLimit takes a token for every request and answers 429 once the
+// bucket is empty. Requests go through when the limiter fails, an outage
+// of the limiter should not take the API down with it.
+func RateLimit(limiter ratelimit.Limiter, policy ratelimit.Policy, key RateLimitKey) gin.HandlerFunc {
+	if !policy.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		result, err := limiter.Take(c.Request.Context(), policy, key(c), 1)
+		if err != nil {
+			Logger(c).Error("[rate limit]: could not take token", slog.String("policy", policy.Name), slog.Any("error", err))
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, policy, result)
+		if !result.Allowed {
+			tooManyRequests(c, policy, result)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitFailures only counts requests answered with status, and
+// answers 429 once the bucket is empty. It holds back guessing without
+// getting in the way of the clients that get it right. The token is taken
+// up front and given back when the request did not fail, so parallel
+// guesses cannot all get through before the first one is counted.
+func RateLimitFailures(limiter ratelimit.Limiter, policy ratelimit.Policy, key RateLimitKey, status int) gin.HandlerFunc {
+	if !policy.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		k := key(c)
+
+		result, err := limiter.Take(c.Request.Context(), policy, k, 1)
+		if err != nil {
+			Logger(c).Error("[rate limit]: could not take token", slog.String("policy", policy.Name), slog.Any("error", err))
+			c.Next()
+			return
+		}
+
+		if !result.Allowed {
+			setRateLimitHeaders(c, policy, result)
+			tooManyRequests(c, policy, result)
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == status {
+			return
+		}
+
+		if err := limiter.Refund(c.Request.Context(), policy, k, 1); err != nil {
+			Logger(c).Error("[rate limit]: could not refund token", slog.String("policy", policy.Name), slog.Any("error", err))
+		}
+	}
+}
+
+// setRateLimitHeaders sets the RateLimit headers of the IETF draft, the
+// last limit applied to a request sets them.
+func setRateLimitHeaders(c *gin.Context, policy ratelimit.Policy, result ratelimit.Result) {
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Period)))
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func tooManyRequests(c *gin.Context, policy ratelimit.Policy, result ratelimit.Result) {
+	metrics.RateLimited.WithLabelValues(policy.Name).Inc()
+
+	c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, models.NewErrorResponse("too many requests, try again later", nil))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exposedHeaders are the response headers the web app may read.
+var exposedHeaders = []string{
+	RequestIDHeader,
+	"Retry-After",
+	"RateLimit-Policy",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+}
+
+type CORSOptions struct {
+	// AllowedOrigins are compared to the Origin header exactly, "*"
+	// allows every origin.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS lets browsers on the allowed origins call the API and answers
+// their preflight requests. Requests from other origins are served as
+// usual, without the headers the browser needs to hand them the response.
+func CORS(opts CORSOptions) gin.HandlerFunc {
+	allowAll := slices.Contains(opts.AllowedOrigins, "*")
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(exposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !allowAll && !slices.Contains(opts.AllowedOrigins, origin) {
+			c.Next()
+			return
+		}
+
+		// the origin is echoed rather than "*" so credentials work
+		// with every allowed origin
+		c.Header("Access-Control-Allow-Origin", origin)
+		if opts.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if c.Request.Method != http.MethodOptions || c.GetHeader("Access-Control-Request-Method") == "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		c.Header("Access-Control-Allow-Methods", methods)
+		c.Header("Access-Control-Allow-Headers", headers)
+		c.Header("Access-Control-Max-Age", maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// SecurityHeaders keeps browsers from sniffing content types, framing the
+// API and leaking it in referrers. hstsMaxAge, when set, tells them to
+// only ever use https.
+func SecurityHeaders(hstsMaxAge time.Duration) gin.HandlerFunc {
+	hsts := ""
+	if hstsMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int(hstsMaxAge.Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Content-Security-Policy", "frame-ancestors 'none'")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+
+		c.Next()
+	}
+}
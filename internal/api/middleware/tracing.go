@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Tracing starts a span for every request, continuing the trace of the
+// caller when it sent one. Game sockets are left out, a span as long as a
+// whole game says nothing useful.
+func Tracing(service string) gin.HandlerFunc {
+	return otelgin.Middleware(service, otelgin.WithFilter(func(r *http.Request) bool {
+		return !strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+	}))
+}
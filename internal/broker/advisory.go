@@ -0,0 +1,188 @@
+package broker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+const leaseCheckInterval = 5 * time.Second
+
+type advisoryElector struct {
+	db *bun.DB
+
+	mu     sync.Mutex
+	conn   *bun.Conn
+	stop   chan struct{}
+	leases map[int64]*advisoryLease
+}
+
+// NewAdvisoryElector returns an elector backed by postgres session level
+// advisory locks. The locks belong to the connection that took them, so
+// the elector pins a single connection from the pool for as long as this
+// node holds any lease, and every lease is lost if that connection breaks.
+func NewAdvisoryElector(db *bun.DB) Elector {
+	return &advisoryElector{
+		db:     db,
+		leases: make(map[int64]*advisoryLease),
+	}
+}
+
+func (a *advisoryElector) TryAcquire(ctx context.Context, key string) (Lease, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lockID := advisoryLockID(key)
+	if _, ok := a.leases[lockID]; ok {
+		return nil, ErrNotLeader
+	}
+
+	if a.conn == nil {
+		conn, err := a.db.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		a.conn = &conn
+		a.stop = make(chan struct{})
+		go a.watch(a.conn, a.stop)
+	}
+
+	var acquired bool
+	err := a.conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(?)", lockID).Scan(&acquired)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			a.dropConn(err)
+		}
+		return nil, err
+	}
+
+	if !acquired {
+		a.closeIdleConn()
+		return nil, ErrNotLeader
+	}
+
+	lease := &advisoryLease{
+		elector: a,
+		lockID:  lockID,
+		lost:    make(chan struct{}),
+	}
+	a.leases[lockID] = lease
+
+	return lease, nil
+}
+
+func (a *advisoryElector) release(ctx context.Context, lease *advisoryLease) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.leases[lease.lockID] != lease {
+		// lost along with the connection already
+		return nil
+	}
+
+	delete(a.leases, lease.lockID)
+	close(lease.lost)
+
+	_, err := a.conn.ExecContext(ctx, "SELECT pg_advisory_unlock(?)", lease.lockID)
+	if err != nil {
+		// the lock would otherwise outlive the lease
+		a.dropConn(err)
+		return err
+	}
+
+	a.closeIdleConn()
+	return nil
+}
+
+// watch pings the pinned connection, the locks are gone with it if the
+// connection breaks.
+func (a *advisoryElector) watch(conn *bun.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(leaseCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			if a.conn != conn {
+				a.mu.Unlock()
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), leaseCheckInterval)
+			err := conn.PingContext(ctx)
+			cancel()
+
+			if err != nil {
+				a.dropConn(err)
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+// dropConn discards the pinned connection and loses every lease held on
+// it. The caller must hold a.mu.
+func (a *advisoryElector) dropConn(reason error) {
+	if a.conn == nil {
+		return
+	}
+
+	slog.Error("[broker]: lost advisory lock connection", slog.Any("error", reason))
+
+	// make the pool close the connection rather than reuse it with the
+	// locks still held
+	_ = a.conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	_ = a.conn.Close()
+
+	for lockID, lease := range a.leases {
+		close(lease.lost)
+		delete(a.leases, lockID)
+	}
+
+	close(a.stop)
+	a.conn = nil
+}
+
+// closeIdleConn hands the pinned connection back to the pool once no
+// lease is held on it. The caller must hold a.mu.
+func (a *advisoryElector) closeIdleConn() {
+	if a.conn == nil || len(a.leases) > 0 {
+		return
+	}
+
+	_ = a.conn.Close()
+	close(a.stop)
+	a.conn = nil
+}
+
+type advisoryLease struct {
+	elector *advisoryElector
+	lockID  int64
+	lost    chan struct{}
+}
+
+func (l *advisoryLease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *advisoryLease) Release(ctx context.Context) error {
+	return l.elector.release(ctx, l)
+}
+
+func advisoryLockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,140 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/uptrace/bun"
+)
+
+const subscriptionBuffer = 64
+
+var (
+	ErrClosed          = errors.New("broker: closed")
+	ErrPayloadTooLarge = errors.New("broker: payload too large")
+)
+
+// Broker fans messages out to every subscriber of a topic, on this node
+// and, depending on the implementation, on every other node.
+type Broker interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	Subscribe(topic string) *Subscription
+	// Ping reports whether messages can currently be published and
+	// received.
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Subscription receives the messages published on a topic. Messages are
+// dropped for subscribers that fall too far behind rather than holding up
+// everyone else.
+type Subscription struct {
+	C <-chan []byte
+
+	topic string
+	ch    chan []byte
+	hub   *hub
+	once  sync.Once
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+	})
+}
+
+// hub delivers messages to the subscriptions of this node.
+type hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+func newHub() *hub {
+	return &hub{topics: make(map[string]map[*Subscription]struct{})}
+}
+
+func (h *hub) subscribe(topic string) *Subscription {
+	ch := make(chan []byte, subscriptionBuffer)
+	sub := &Subscription{C: ch, topic: topic, ch: ch, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(ch)
+		return sub
+	}
+
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[*Subscription]struct{})
+	}
+	h.topics[topic][sub] = struct{}{}
+
+	return sub
+}
+
+func (h *hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.topics[sub.topic]
+	if !ok {
+		return
+	}
+
+	if _, ok := subs[sub]; ok {
+		delete(subs, sub)
+		close(sub.ch)
+	}
+
+	if len(subs) == 0 {
+		delete(h.topics, sub.topic)
+	}
+}
+
+func (h *hub) deliver(topic string, payload []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.topics[topic] {
+		select {
+		case sub.ch <- payload:
+		default:
+			slog.Warn("[broker]: subscriber is too slow, dropping message", slog.String("topic", topic))
+		}
+	}
+}
+
+func (h *hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for topic, subs := range h.topics {
+		for sub := range subs {
+			close(sub.ch)
+		}
+		delete(h.topics, topic)
+	}
+}
+
+// New returns the broker and elector for the configured driver.
+func New(ctx context.Context, cfg *config.Config, db *bun.DB) (Broker, Elector, error) {
+	switch cfg.Broker.Driver {
+	case config.BrokerDriverMemory:
+		return NewMemoryBroker(), NewMemoryElector(), nil
+	case config.BrokerDriverPostgres:
+		b, err := NewPostgresBroker(ctx, db)
+		if err != nil {
+			return nil, nil, err
+		}
+		return b, NewAdvisoryElector(db), nil
+	default:
+		return nil, nil, fmt.Errorf("broker: unknown driver %q", cfg.Broker.Driver)
+	}
+}
@@ -0,0 +1,68 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrNotLeader = errors.New("broker: another node holds the lease")
+
+// Elector hands out leases on keys so that a single node at a time acts
+// on a key, such as driving the timer of a game session.
+type Elector interface {
+	// TryAcquire returns ErrNotLeader without waiting when another node
+	// holds the key.
+	TryAcquire(ctx context.Context, key string) (Lease, error)
+}
+
+type Lease interface {
+	// Lost is closed when the lease can no longer be trusted, for
+	// example because the connection holding it broke.
+	Lost() <-chan struct{}
+	Release(ctx context.Context) error
+}
+
+type memoryElector struct {
+	mu   sync.Mutex
+	held map[string]struct{}
+}
+
+// NewMemoryElector returns an elector for running a single instance,
+// leases are only exclusive within this process.
+func NewMemoryElector() Elector {
+	return &memoryElector{held: make(map[string]struct{})}
+}
+
+func (m *memoryElector) TryAcquire(ctx context.Context, key string) (Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.held[key]; ok {
+		return nil, ErrNotLeader
+	}
+
+	m.held[key] = struct{}{}
+	return &memoryLease{elector: m, key: key, lost: make(chan struct{})}, nil
+}
+
+type memoryLease struct {
+	elector *memoryElector
+	key     string
+	lost    chan struct{}
+	once    sync.Once
+}
+
+func (l *memoryLease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *memoryLease) Release(ctx context.Context) error {
+	l.once.Do(func() {
+		l.elector.mu.Lock()
+		delete(l.elector.held, l.key)
+		l.elector.mu.Unlock()
+		close(l.lost)
+	})
+	return nil
+}
@@ -0,0 +1,44 @@
+package broker
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type memoryBroker struct {
+	hub    *hub
+	closed atomic.Bool
+}
+
+// NewMemoryBroker returns a broker that only reaches subscribers in this
+// process, for running a single instance.
+func NewMemoryBroker() Broker {
+	return &memoryBroker{hub: newHub()}
+}
+
+func (m *memoryBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	if m.closed.Load() {
+		return ErrClosed
+	}
+
+	m.hub.deliver(topic, payload)
+	return nil
+}
+
+func (m *memoryBroker) Subscribe(topic string) *Subscription {
+	return m.hub.subscribe(topic)
+}
+
+func (m *memoryBroker) Ping(context.Context) error {
+	if m.closed.Load() {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (m *memoryBroker) Close() error {
+	if m.closed.CompareAndSwap(false, true) {
+		m.hub.close()
+	}
+	return nil
+}
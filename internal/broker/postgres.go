@@ -0,0 +1,104 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+const (
+	notifyChannel = "sabipass_broker"
+	// maxNotifyPayload is the size postgres accepts in a single NOTIFY.
+	maxNotifyPayload = 8000
+)
+
+type envelope struct {
+	Topic   string          `json:"t"`
+	Payload json.RawMessage `json:"p"`
+}
+
+type postgresBroker struct {
+	db       *bun.DB
+	listener *pgdriver.Listener
+	hub      *hub
+	closed   atomic.Bool
+}
+
+// NewPostgresBroker returns a broker that reaches subscribers on every
+// instance connected to the same database, using LISTEN/NOTIFY on a
+// single channel. Payloads must be JSON and, with the topic, fit in a
+// NOTIFY, so they should carry ids rather than whole documents.
+func NewPostgresBroker(ctx context.Context, db *bun.DB) (Broker, error) {
+	listener := pgdriver.NewListener(db)
+	if err := listener.Listen(ctx, notifyChannel); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	b := &postgresBroker{
+		db:       db,
+		listener: listener,
+		hub:      newHub(),
+	}
+
+	go b.receive()
+	return b, nil
+}
+
+func (p *postgresBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	if p.closed.Load() {
+		return ErrClosed
+	}
+
+	message, err := json.Marshal(envelope{Topic: topic, Payload: payload})
+	if err != nil {
+		return err
+	}
+
+	if len(message) > maxNotifyPayload {
+		return ErrPayloadTooLarge
+	}
+
+	return pgdriver.Notify(ctx, p.db, notifyChannel, string(message))
+}
+
+func (p *postgresBroker) Subscribe(topic string) *Subscription {
+	return p.hub.subscribe(topic)
+}
+
+// Ping checks the database notifications go through, the listener
+// reconnects by itself when its connection drops.
+func (p *postgresBroker) Ping(ctx context.Context) error {
+	if p.closed.Load() {
+		return ErrClosed
+	}
+	return p.db.PingContext(ctx)
+}
+
+func (p *postgresBroker) Close() error {
+	if !p.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	err := p.listener.Close()
+	p.hub.close()
+	return err
+}
+
+// receive hands notifications to the subscribers of this node, including
+// the ones published from it, until the listener is closed.
+func (p *postgresBroker) receive() {
+	for notification := range p.listener.Channel() {
+		var message envelope
+		if err := json.Unmarshal([]byte(notification.Payload), &message); err != nil {
+			slog.Error("[broker]: could not decode notification", slog.Any("error", err))
+			continue
+		}
+
+		p.hub.deliver(message.Topic, message.Payload)
+	}
+}
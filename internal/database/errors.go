@@ -7,4 +7,20 @@ var (
 	ErrUserNotFound      = errors.New("user not found")
 
 	ErrQuizNotFound = errors.New("quiz not found")
+
+	ErrQuestionNotFound = errors.New("question not found")
+
+	ErrUploadNotFound = errors.New("upload not found")
+
+	ErrGameSessionNotFound     = errors.New("game session not found")
+	ErrGameSessionCodeTaken    = errors.New("game session code already taken")
+	ErrGameSessionMoved        = errors.New("game session moved on to another phase")
+	ErrGameParticipantNotFound = errors.New("game participant not found")
+	ErrNicknameTaken           = errors.New("nickname already taken")
+	ErrGameAnswerNotFound      = errors.New("game answer not found")
+	ErrGameAnswerRecorded      = errors.New("game answer already recorded")
+	ErrTeamNameTaken           = errors.New("team name already taken")
+
+	ErrReportNotFound    = errors.New("moderation report not found")
+	ErrReportAlreadyOpen = errors.New("moderation report already open")
 )
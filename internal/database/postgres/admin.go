@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/uptrace/bun"
+)
+
+type adminAuditLogRepo struct {
+	db *DB
+}
+
+func NewAdminAuditLogRepository(db *DB) models.AdminAuditLogRepository {
+	return &adminAuditLogRepo{db: db}
+}
+
+func (a *adminAuditLogRepo) Create(ctx context.Context, entry *models.AdminAuditLog) error {
+	ctx, cancel := a.db.WithContext(ctx)
+	defer cancel()
+
+	return a.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(entry).Exec(ctx)
+		return err
+	})
+}
+
+func (a *adminAuditLogRepo) UpdateUser(ctx context.Context, user *models.User, entry *models.AdminAuditLog) error {
+	return a.update(ctx, user, user.ID, entry)
+}
+
+func (a *adminAuditLogRepo) UpdateQuiz(ctx context.Context, quiz *models.Quiz, entry *models.AdminAuditLog) error {
+	return a.update(ctx, quiz, quiz.ID, entry)
+}
+
+func (a *adminAuditLogRepo) UpdateParticipant(ctx context.Context, participant *models.GameParticipant, entry *models.AdminAuditLog) error {
+	return a.update(ctx, participant, participant.ID, entry)
+}
+
+// update saves model, the row with id, and records entry in the same
+// transaction.
+func (a *adminAuditLogRepo) update(ctx context.Context, model any, id string, entry *models.AdminAuditLog) error {
+	ctx, cancel := a.db.WithContext(ctx)
+	defer cancel()
+
+	return a.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(model).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewInsert().Model(entry).Exec(ctx)
+		return err
+	})
+}
+
+func (a *adminAuditLogRepo) FindAll(ctx context.Context, targetID string) ([]models.AdminAuditLog, error) {
+	ctx, cancel := a.db.WithContext(ctx)
+	defer cancel()
+
+	entries := []models.AdminAuditLog{}
+	err := a.db.NewSelect().
+		Model(&entries).
+		Where("target_id = ?", targetID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/uptrace/bun"
+)
+
+type analyticsRepo struct {
+	db *DB
+}
+
+func NewAnalyticsRepository(db *DB) models.AnalyticsRepository {
+	return &analyticsRepo{db: db}
+}
+
+func (a *analyticsRepo) FindUnrolled(ctx context.Context, limit int) ([]models.GameSession, error) {
+	ctx, cancel := a.db.WithContext(ctx)
+	defer cancel()
+
+	sessions := []models.GameSession{}
+	err := a.db.NewSelect().
+		Model(&sessions).
+		Where("NOT EXISTS (SELECT 1 FROM analytics_rollups r WHERE r.session_id = game_session.id)").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				WhereOr("game_session.mode = ? AND game_session.phase = ?", models.GameModeLive, models.GamePhaseFinished).
+				WhereOr("game_session.mode = ? AND (game_session.status = ? OR game_session.closes_at < CURRENT_TIMESTAMP)",
+					models.GameModeChallenge, models.GameSessionStatusClosed)
+		}).
+		Order("game_session.created_at ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (a *analyticsRepo) Rollup(ctx context.Context, rollup *models.QuizRollup) (bool, error) {
+	ctx, cancel := a.db.WithContext(ctx)
+	defer cancel()
+
+	rolled := false
+	err := a.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// the marker makes sure a session only ever counts once, even with
+		// several workers rolling up at the same time
+		res, err := tx.NewRaw(
+			"INSERT INTO analytics_rollups (session_id, quiz_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			rollup.SessionID, rollup.QuizID,
+		).Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return nil
+		}
+
+		if len(rollup.Questions) > 0 {
+			_, err := tx.NewInsert().
+				Model(&rollup.Questions).
+				On("CONFLICT (question_id) DO UPDATE").
+				Set("answer_count = question_stat.answer_count + EXCLUDED.answer_count").
+				Set("correct_count = question_stat.correct_count + EXCLUDED.correct_count").
+				Set("upper_count = question_stat.upper_count + EXCLUDED.upper_count").
+				Set("upper_correct = question_stat.upper_correct + EXCLUDED.upper_correct").
+				Set("lower_count = question_stat.lower_count + EXCLUDED.lower_count").
+				Set("lower_correct = question_stat.lower_correct + EXCLUDED.lower_correct").
+				Set("updated_at = EXCLUDED.updated_at").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rollup.Options) > 0 {
+			_, err := tx.NewInsert().
+				Model(&rollup.Options).
+				On("CONFLICT (option_id) DO UPDATE").
+				Set("pick_count = question_option_stat.pick_count + EXCLUDED.pick_count").
+				Set("updated_at = EXCLUDED.updated_at").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rollup.TimeBuckets) > 0 {
+			_, err := tx.NewInsert().
+				Model(&rollup.TimeBuckets).
+				On("CONFLICT (question_id, seconds) DO UPDATE").
+				Set("count = question_time_bucket.count + EXCLUDED.count").
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		rolled = true
+		return nil
+	})
+
+	return rolled, err
+}
+
+func (a *analyticsRepo) FindQuizStats(ctx context.Context, quizID string) (*models.QuizStats, error) {
+	ctx, cancel := a.db.WithContext(ctx)
+	defer cancel()
+
+	stats := &models.QuizStats{
+		Questions:   []models.QuestionStat{},
+		Options:     []models.QuestionOptionStat{},
+		TimeBuckets: []models.QuestionTimeBucket{},
+	}
+
+	sessionCount, err := a.db.NewSelect().
+		Table("analytics_rollups").
+		Where("quiz_id = ?", quizID).
+		Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.SessionCount = sessionCount
+
+	err = a.db.NewSelect().
+		Model(&stats.Questions).
+		Where("quiz_id = ?", quizID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.db.NewSelect().
+		Model(&stats.Options).
+		Where("question_id IN (SELECT question_id FROM question_stats WHERE quiz_id = ?)", quizID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.db.NewSelect().
+		Model(&stats.TimeBuckets).
+		Where("question_id IN (SELECT question_id FROM question_stats WHERE quiz_id = ?)", quizID).
+		Order("question_id ASC", "seconds ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,596 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sidekik"
+	"github.com/uptrace/bun"
+)
+
+type gameSessionRepo struct {
+	db *DB
+}
+
+func NewGameSessionRepository(db *DB) models.GameSessionRepository {
+	return &gameSessionRepo{db: db}
+}
+
+func (g *gameSessionRepo) Create(ctx context.Context, session *models.GameSession) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(session).Exec(ctx)
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return database.ErrGameSessionCodeTaken
+			}
+			return err
+		}
+
+		if len(session.Teams) > 0 {
+			_, err = tx.NewInsert().Model(&session.Teams).Exec(ctx)
+			if err != nil {
+				if strings.Contains(err.Error(), "duplicate key") {
+					return database.ErrTeamNameTaken
+				}
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (g *gameSessionRepo) Update(ctx context.Context, session *models.GameSession) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(session).
+			Where("id = ?", session.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func (g *gameSessionRepo) FindOne(ctx context.Context, opts *models.FindGameSessionOptions) (*models.GameSession, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	var session models.GameSession
+	query := g.db.NewSelect().
+		Model(&session).
+		Relation("Teams", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("position ASC")
+		})
+
+	if !sidekik.IsStringEmpty(opts.ID) {
+		query.Where("id = ?", opts.ID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.Code) {
+		query.Where("code = ?", strings.ToUpper(opts.Code))
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = database.ErrGameSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (g *gameSessionRepo) FindAll(ctx context.Context, opts *models.ListGameSessionOptions) ([]models.GameSession, int64, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	sessions := []models.GameSession{}
+	query := g.db.NewSelect().
+		Model(&sessions).
+		Relation("Quiz").
+		Relation("Summary")
+
+	if !sidekik.IsStringEmpty(opts.UserID) {
+		query.Where(`game_session.host_id = ? OR EXISTS (
+			SELECT 1 FROM game_participants p
+			WHERE p.session_id = game_session.id AND p.user_id = ? AND p.banned_at IS NULL
+		)`, opts.UserID, opts.UserID)
+	}
+
+	sessionCount, err := query.Clone().Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("game_session.created_at DESC").
+		Limit(int(opts.Paginator.PerPage)).
+		Offset(int(opts.Paginator.Offset())).
+		Scan(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, int64(sessionCount), nil
+}
+
+func (g *gameSessionRepo) FindLive(ctx context.Context) ([]models.GameSession, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	sessions := []models.GameSession{}
+	err := g.db.NewSelect().
+		Model(&sessions).
+		Where("mode = ?", models.GameModeLive).
+		Where("phase != ?", models.GamePhaseFinished).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (g *gameSessionRepo) FindAbandoned(ctx context.Context, before time.Time) ([]models.GameSession, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	sessions := []models.GameSession{}
+	err := g.db.NewSelect().
+		Model(&sessions).
+		Where("mode = ?", models.GameModeLive).
+		Where("phase != ?", models.GamePhaseFinished).
+		Where("COALESCE(host_seen_at, created_at) < ?", before).
+		Where("updated_at < ?", before).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (g *gameSessionRepo) Transition(ctx context.Context, session *models.GameSession, from *models.GameSession) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model(session).
+			Column("phase", "question_index", "phase_started_at", "phase_ends_at", "paused_at", "pause_reason",
+				"locked_at", "status", "updated_at").
+			Where("id = ?", session.ID).
+			Where("phase = ?", from.Phase).
+			Where("question_index = ?", from.QuestionIndex).
+			Where("phase_ends_at IS NOT DISTINCT FROM ?", from.PhaseEndsAt).
+			Where("paused_at IS NOT DISTINCT FROM ?", from.PausedAt).
+			Where("locked_at IS NOT DISTINCT FROM ?", from.LockedAt).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return database.ErrGameSessionMoved
+		}
+		return nil
+	})
+}
+
+func (g *gameSessionRepo) TouchHost(ctx context.Context, sessionID string, seenAt time.Time) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := g.db.NewUpdate().
+		Model((*models.GameSession)(nil)).
+		Set("host_seen_at = ?", seenAt).
+		Where("id = ?", sessionID).
+		Exec(ctx)
+	return err
+}
+
+func (g *gameSessionRepo) SetPresenterToken(ctx context.Context, sessionID, tokenHash string) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := g.db.NewUpdate().
+		Model((*models.GameSession)(nil)).
+		Set("presenter_token_hash = ?", tokenHash).
+		Set("updated_at = CURRENT_TIMESTAMP").
+		Where("id = ?", sessionID).
+		Exec(ctx)
+	return err
+}
+
+type gameParticipantRepo struct {
+	db *DB
+}
+
+func NewGameParticipantRepository(db *DB) models.GameParticipantRepository {
+	return &gameParticipantRepo{db: db}
+}
+
+func (g *gameParticipantRepo) Create(ctx context.Context, participant *models.GameParticipant) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(participant).Exec(ctx)
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return database.ErrNicknameTaken
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (g *gameParticipantRepo) Update(ctx context.Context, participant *models.GameParticipant) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(participant).
+			Where("id = ?", participant.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func (g *gameParticipantRepo) FindOne(ctx context.Context, opts *models.FindGameParticipantOptions) (*models.GameParticipant, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	var participant models.GameParticipant
+	query := g.db.NewSelect().Model(&participant)
+
+	if !sidekik.IsStringEmpty(opts.ID) {
+		query.Where("id = ?", opts.ID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.SessionID) {
+		query.Where("session_id = ?", opts.SessionID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.UserID) {
+		query.Where("user_id = ?", opts.UserID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.TokenHash) {
+		query.Where("token_hash = ?", opts.TokenHash)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = database.ErrGameParticipantNotFound
+		}
+		return nil, err
+	}
+
+	return &participant, nil
+}
+
+func (g *gameParticipantRepo) FindAll(ctx context.Context, sessionID string) ([]models.GameParticipant, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	participants := []models.GameParticipant{}
+	err := g.db.NewSelect().
+		Model(&participants).
+		Where("session_id = ?", sessionID).
+		Where("banned_at IS NULL").
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return participants, nil
+}
+
+func (g *gameParticipantRepo) AssignTeams(ctx context.Context, participants []models.GameParticipant) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		for i := range participants {
+			_, err := tx.NewUpdate().
+				Model(&participants[i]).
+				Column("team_id", "updated_at").
+				Where("id = ?", participants[i].ID).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (g *gameParticipantRepo) Delete(ctx context.Context, participant *models.GameParticipant) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().
+			Model(participant).
+			Where("id = ?", participant.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func (g *gameParticipantRepo) Ban(ctx context.Context, participant *models.GameParticipant) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(participant).
+			Column("banned_at", "updated_at").
+			Where("id = ?", participant.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func (g *gameParticipantRepo) ResetStreaks(ctx context.Context, sessionID, questionID string) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*models.GameParticipant)(nil)).
+			Set("streak = 0").
+			Set("updated_at = CURRENT_TIMESTAMP").
+			Where("session_id = ?", sessionID).
+			Where("streak > 0").
+			Where(`NOT EXISTS (
+				SELECT 1 FROM game_answers a
+				WHERE a.participant_id = game_participant.id
+				AND a.question_id = ?
+				AND a.answered_at IS NOT NULL
+				AND NOT a.timed_out
+			)`, questionID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func (g *gameParticipantRepo) Leaderboard(ctx context.Context, sessionID string) ([]models.LeaderboardEntry, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	entries := []models.LeaderboardEntry{}
+	err := g.db.NewRaw(`
+		SELECT
+			p.id AS participant_id,
+			p.team_id,
+			p.nickname,
+			p.score,
+			p.correct_count,
+			p.finished_at,
+			(
+				SELECT COUNT(*) FROM game_answers a
+				WHERE a.participant_id = p.id AND a.answered_at IS NOT NULL AND NOT a.timed_out
+			) AS answered_count
+		FROM game_participants p
+		WHERE p.session_id = ? AND p.banned_at IS NULL
+		ORDER BY p.score DESC, p.finished_at ASC NULLS LAST, p.created_at ASC`, sessionID).
+		Scan(ctx, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// players on the same score share a rank
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+
+	return entries, nil
+}
+
+type gameAnswerRepo struct {
+	db *DB
+}
+
+func NewGameAnswerRepository(db *DB) models.GameAnswerRepository {
+	return &gameAnswerRepo{db: db}
+}
+
+func (g *gameAnswerRepo) Start(ctx context.Context, answer *models.GameAnswer) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(answer).
+			On("CONFLICT (participant_id, question_id) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		// a concurrent request may have started the question first, in
+		// which case its start time wins
+		return tx.NewSelect().
+			Model(answer).
+			Where("participant_id = ?", answer.ParticipantID).
+			Where("question_id = ?", answer.QuestionID).
+			Scan(ctx)
+	})
+}
+
+func (g *gameAnswerRepo) FindOne(ctx context.Context, opts *models.FindGameAnswerOptions) (*models.GameAnswer, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	var answer models.GameAnswer
+	query := g.db.NewSelect().Model(&answer)
+
+	if !sidekik.IsStringEmpty(opts.SessionID) {
+		query.Where("session_id = ?", opts.SessionID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.ParticipantID) {
+		query.Where("participant_id = ?", opts.ParticipantID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.QuestionID) {
+		query.Where("question_id = ?", opts.QuestionID)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = database.ErrGameAnswerNotFound
+		}
+		return nil, err
+	}
+
+	return &answer, nil
+}
+
+func (g *gameAnswerRepo) FindAll(ctx context.Context, opts *models.FindGameAnswerOptions) ([]models.GameAnswer, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	answers := []models.GameAnswer{}
+	query := g.db.NewSelect().Model(&answers)
+
+	if !sidekik.IsStringEmpty(opts.SessionID) {
+		query.Where("session_id = ?", opts.SessionID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.ParticipantID) {
+		query.Where("participant_id = ?", opts.ParticipantID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.QuestionID) {
+		query.Where("question_id = ?", opts.QuestionID)
+	}
+
+	if err := query.Order("started_at ASC").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return answers, nil
+}
+
+func (g *gameAnswerRepo) Record(ctx context.Context, answer *models.GameAnswer, participant *models.GameParticipant) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model(answer).
+			Column("answer", "correct", "credit", "points", "timed_out", "answered_at", "updated_at").
+			Where("id = ?", answer.ID).
+			Where("answered_at IS NULL").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return database.ErrGameAnswerRecorded
+		}
+
+		_, err = tx.NewUpdate().
+			Model(participant).
+			Column("score", "correct_count", "streak", "current_position", "finished_at", "updated_at").
+			Where("id = ?", participant.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+type gameSummaryRepo struct {
+	db *DB
+}
+
+func NewGameSummaryRepository(db *DB) models.GameSummaryRepository {
+	return &gameSummaryRepo{db: db}
+}
+
+func (g *gameSummaryRepo) Save(ctx context.Context, summary *models.GameSummary) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(summary).
+			On("CONFLICT (session_id) DO UPDATE").
+			Set("quiz_title = EXCLUDED.quiz_title").
+			Set("player_count = EXCLUDED.player_count").
+			Set("question_count = EXCLUDED.question_count").
+			Set("answer_count = EXCLUDED.answer_count").
+			Set("correct_rate = EXCLUDED.correct_rate").
+			Set("average_score = EXCLUDED.average_score").
+			Set("winner_id = EXCLUDED.winner_id").
+			Set("winner_nickname = EXCLUDED.winner_nickname").
+			Set("winner_score = EXCLUDED.winner_score").
+			Set("finished_at = EXCLUDED.finished_at").
+			Set("updated_at = EXCLUDED.updated_at").
+			Exec(ctx)
+		return err
+	})
+}
+
+type gameAuditLogRepo struct {
+	db *DB
+}
+
+func NewGameAuditLogRepository(db *DB) models.GameAuditLogRepository {
+	return &gameAuditLogRepo{db: db}
+}
+
+func (g *gameAuditLogRepo) Create(ctx context.Context, entry *models.GameAuditLog) error {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(entry).Exec(ctx)
+		return err
+	})
+}
+
+func (g *gameAuditLogRepo) FindAll(ctx context.Context, sessionID string) ([]models.GameAuditLog, error) {
+	ctx, cancel := g.db.WithContext(ctx)
+	defer cancel()
+
+	entries := []models.GameAuditLog{}
+	err := g.db.NewSelect().
+		Model(&entries).
+		Where("session_id = ?", sessionID).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/tracing"
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const packagePath = "github.com/oxiginedev/sabipass/internal/database/postgres."
+
+type (
+	methodKey struct{}
+	spanKey   struct{}
+)
+
+// queryHook records how long every query takes and traces it, labelled
+// with the repository method that ran it, such as quizRepo.FindOne.
+// Queries are only traced as part of a trace that is already going, so
+// background polling does not start traces of its own. Spans carry the
+// operation and table but never the query text, bun inlines the arguments
+// so it holds the data being read and written.
+type queryHook struct{}
+
+var _ bun.QueryHook = (*queryHook)(nil)
+
+func (h *queryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	method := callerMethod()
+	event.Stash = map[any]any{methodKey{}: method}
+
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.operation.name", event.Operation()),
+	}
+	if event.IQuery != nil {
+		if table := event.IQuery.GetTableName(); table != "" {
+			attrs = append(attrs, attribute.String("db.collection.name", table))
+		}
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	event.Stash[spanKey{}] = span
+	return ctx
+}
+
+func (h *queryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	method, _ := event.Stash[methodKey{}].(string)
+
+	// finding nothing is an answer, not a failure
+	err := event.Err
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+
+	metrics.QueryDuration.
+		WithLabelValues(method, event.Operation(), strconv.FormatBool(err != nil)).
+		Observe(time.Since(event.StartTime).Seconds())
+
+	if span, ok := event.Stash[spanKey{}].(trace.Span); ok {
+		tracing.End(span, err)
+	}
+}
+
+// callerMethod returns the repository method up the stack, or unknown for
+// queries run from outside the repositories, such as migrations.
+func callerMethod() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, packagePath); ok && strings.Contains(name, "Repo).") {
+			return repositoryMethod(name)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// repositoryMethod turns (*quizRepo).FindOne.func1 into quizRepo.FindOne.
+func repositoryMethod(name string) string {
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) < 2 {
+		return name
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/uptrace/bun"
+)
+
+type jobRepo struct {
+	db *DB
+}
+
+func NewJobRepository(db *DB) models.JobRepository {
+	return &jobRepo{db: db}
+}
+
+func (j *jobRepo) Enqueue(ctx context.Context, job *models.Job) error {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := j.db.NewInsert().Model(job).Exec(ctx)
+	return err
+}
+
+func (j *jobRepo) Claim(ctx context.Context, workerID string, kinds []string, limit int, lockFor time.Duration) ([]models.Job, error) {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	now := time.Now()
+	jobs := []models.Job{}
+	// SKIP LOCKED lets workers claim side by side, each one passing over
+	// the rows another is claiming instead of waiting for them
+	err := j.db.NewRaw(`
+		UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE kind IN (?)
+			AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?))
+			ORDER BY run_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.JobStatusRunning, workerID, now.Add(lockFor), now,
+		bun.In(kinds),
+		models.JobStatusPending, now, models.JobStatusRunning, now,
+		limit,
+	).Scan(ctx, &jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (j *jobRepo) Complete(ctx context.Context, job *models.Job) error {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := j.db.NewDelete().
+		Model((*models.Job)(nil)).
+		Where("id = ?", job.ID).
+		Where("locked_by = ?", job.LockedBy).
+		Exec(ctx)
+	return err
+}
+
+func (j *jobRepo) Retry(ctx context.Context, job *models.Job, runAt time.Time, lastError string) error {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := j.db.NewUpdate().
+		Model((*models.Job)(nil)).
+		Set("status = ?", models.JobStatusPending).
+		Set("run_at = ?", runAt).
+		Set("last_error = ?", lastError).
+		Set("locked_by = NULL").
+		Set("locked_until = NULL").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", job.ID).
+		Where("locked_by = ?", job.LockedBy).
+		Exec(ctx)
+	return err
+}
+
+func (j *jobRepo) Kill(ctx context.Context, job *models.Job, lastError string) error {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := j.db.NewUpdate().
+		Model((*models.Job)(nil)).
+		Set("status = ?", models.JobStatusDead).
+		Set("last_error = ?", lastError).
+		Set("locked_by = NULL").
+		Set("locked_until = NULL").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", job.ID).
+		Where("locked_by = ?", job.LockedBy).
+		Exec(ctx)
+	return err
+}
+
+func (j *jobRepo) Release(ctx context.Context, job *models.Job) error {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := j.db.NewUpdate().
+		Model((*models.Job)(nil)).
+		Set("status = ?", models.JobStatusPending).
+		Set("attempts = attempts - 1").
+		Set("locked_by = NULL").
+		Set("locked_until = NULL").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", job.ID).
+		Where("locked_by = ?", job.LockedBy).
+		Exec(ctx)
+	return err
+}
+
+func (j *jobRepo) SaveSchedule(ctx context.Context, schedule *models.JobSchedule) error {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	_, err := j.db.NewInsert().
+		Model(schedule).
+		On("CONFLICT (name) DO UPDATE").
+		Set("spec = EXCLUDED.spec").
+		Set("next_run_at = EXCLUDED.next_run_at").
+		Set("updated_at = EXCLUDED.updated_at").
+		Where("job_schedule.spec <> EXCLUDED.spec").
+		Exec(ctx)
+	return err
+}
+
+func (j *jobRepo) FindDueSchedules(ctx context.Context, names []string, now time.Time) ([]models.JobSchedule, error) {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	schedules := []models.JobSchedule{}
+	if len(names) == 0 {
+		return schedules, nil
+	}
+
+	err := j.db.NewSelect().
+		Model(&schedules).
+		Where("name IN (?)", bun.In(names)).
+		Where("next_run_at <= ?", now).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (j *jobRepo) AdvanceSchedule(ctx context.Context, schedule *models.JobSchedule, from time.Time, job *models.Job) (bool, error) {
+	ctx, cancel := j.db.WithContext(ctx)
+	defer cancel()
+
+	advanced := false
+	err := j.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model(schedule).
+			Column("next_run_at", "updated_at").
+			Where("name = ?", schedule.Name).
+			Where("next_run_at = ?", from).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return nil
+		}
+
+		if _, err := tx.NewInsert().Model(job).Exec(ctx); err != nil {
+			return err
+		}
+
+		advanced = true
+		return nil
+	})
+
+	return advanced, err
+}
@@ -0,0 +1,382 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID is the advisory lock held while migrating, so instances
+// starting together apply each migration once.
+const migrationLockID = 7_303_271_922
+
+var (
+	ErrDirtyDatabase    = errors.New("postgres: a migration failed half way, fix the schema and force a version")
+	ErrUnknownMigration = errors.New("postgres: no migration has that version")
+	ErrNoDownMigration  = errors.New("postgres: the migration cannot be rolled back")
+)
+
+var (
+	migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	migrationNameRe = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator applies the migrations embedded in the binary. It keeps its
+// version in the schema_migrations table the migrate CLI uses, so
+// databases migrated with either one carry on with the other.
+type Migrator struct {
+	db         *DB
+	migrations []Migration
+}
+
+func NewMigrator(db *DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies up to steps pending migrations in order, all of them when
+// steps is 0, and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	applied := []Migration{}
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		version, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirtyDatabase
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+
+			if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("postgres: migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back up to steps applied migrations, newest first, and
+// returns the ones it rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	rolledBack := []Migration{}
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		version, dirty, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirtyDatabase
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version > version {
+				continue
+			}
+			if steps > 0 && len(rolledBack) == steps {
+				break
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, migration.Version, migration.Name)
+			}
+
+			previous := uint64(0)
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			if err := m.apply(ctx, conn, migration.Down, previous); err != nil {
+				return fmt.Errorf("postgres: rolling back migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Force sets the version without running any migration and clears the
+// dirty flag, once a failed migration has been cleaned up by hand. Version
+// 0 means no migration is applied.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && !m.has(version) {
+		return fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+	}
+
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, &sql.TxOptions{})
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := setVersion(ctx, tx, version); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// Status lists every migration and whether it is applied, along with the
+// database's version.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, uint64, bool, error) {
+	var (
+		version uint64
+		dirty   bool
+	)
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		var err error
+		version, dirty, err = m.version(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   migration.Version <= version,
+		})
+	}
+
+	return statuses, version, dirty, nil
+}
+
+// Current reports whether every embedded migration is applied and none
+// failed half way. It does not wait for the migration lock, so it can be
+// polled while another instance is migrating.
+func (m *Migrator) Current(ctx context.Context) (bool, error) {
+	conn, err := m.db.DB.DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	version, dirty, err := m.version(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+
+	var latest uint64
+	if len(m.migrations) > 0 {
+		latest = m.migrations[len(m.migrations)-1].Version
+	}
+
+	return !dirty && version >= latest, nil
+}
+
+// locked runs fn on a connection holding the migration lock, with the
+// version table in place.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	// the advisory lock belongs to the connection, so everything runs on
+	// the one that took it
+	conn, err := m.db.DB.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("postgres: could not take the migration lock: %w", err)
+	}
+	defer func() {
+		// unlock with a fresh context, ctx may be done already
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}()
+
+	_, err = conn.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)")
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) version(ctx context.Context, conn *sql.Conn) (uint64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	// the migrate CLI records -1 once everything is rolled back
+	if version < 0 {
+		return 0, dirty, nil
+	}
+	return uint64(version), dirty, nil
+}
+
+// apply runs a migration and records the version it leads to in one
+// transaction, a migration that fails leaves nothing behind.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, query string, version uint64) error {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	if err := setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) has(version uint64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func setVersion(ctx context.Context, tx *sql.Tx, version uint64) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", int64(version))
+	return err
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: bad migration version in %s: %w", entry.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("postgres: migrations %s and %s share version %d", migration.Name, match[2], version)
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("postgres: migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// CreateMigration writes the empty up and down files of a new migration
+// to dir, versioned by now, and returns their paths.
+func CreateMigration(dir, name string, now time.Time) (string, string, error) {
+	name = strings.Trim(migrationNameRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("postgres: the migration needs a name")
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%s_%s", now.UTC().Format("20060102150405"), name))
+	up, down := base+".up.sql", base+".down.sql"
+
+	for _, path := range []string{up, down} {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return "", "", err
+		}
+		if err := file.Close(); err != nil {
+			return "", "", err
+		}
+	}
+
+	return up, down, nil
+}
@@ -0,0 +1,6 @@
+ALTER TABLE quizzes ALTER COLUMN cover_image TYPE VARCHAR(255);
+ALTER TABLE users ALTER COLUMN avatar TYPE VARCHAR(255);
+
+ALTER TABLE questions DROP COLUMN IF EXISTS attachment_id;
+
+DROP TABLE IF EXISTS uploads;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS uploads (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id),
+    purpose VARCHAR(255) NOT NULL,
+    kind VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    width INT,
+    height INT,
+    key VARCHAR(255) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    thumbnail_key VARCHAR(255),
+    thumbnail_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS attachment_id UUID REFERENCES uploads(id);
+
+ALTER TABLE users ALTER COLUMN avatar TYPE TEXT;
+ALTER TABLE quizzes ALTER COLUMN cover_image TYPE TEXT;
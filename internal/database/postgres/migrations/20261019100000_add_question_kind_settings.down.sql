@@ -0,0 +1,3 @@
+ALTER TABLE question_options DROP COLUMN IF EXISTS position;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS settings;
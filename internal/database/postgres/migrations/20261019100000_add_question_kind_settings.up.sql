@@ -0,0 +1,3 @@
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS settings JSONB;
+
+ALTER TABLE question_options ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0 CHECK (position >= 0);
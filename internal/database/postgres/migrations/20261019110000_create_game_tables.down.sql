@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS game_answers;
+DROP TABLE IF EXISTS game_participants;
+DROP TABLE IF EXISTS game_sessions;
\ No newline at end of file
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS game_sessions (
+    id UUID PRIMARY KEY,
+    quiz_id UUID NOT NULL REFERENCES quizzes(id),
+    host_id UUID NOT NULL REFERENCES users(id),
+    mode VARCHAR(255) NOT NULL,
+    code VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(255) NOT NULL,
+    closes_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS game_sessions_host_id_idx ON game_sessions (host_id);
+
+CREATE TABLE IF NOT EXISTS game_participants (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id),
+    nickname VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    score INT NOT NULL DEFAULT 0,
+    correct_count INT NOT NULL DEFAULT 0 CHECK (correct_count >= 0),
+    streak INT NOT NULL DEFAULT 0 CHECK (streak >= 0),
+    current_position INT NOT NULL DEFAULT 0 CHECK (current_position >= 0),
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS game_participants_session_nickname_idx ON game_participants (session_id, LOWER(nickname));
+CREATE INDEX IF NOT EXISTS game_participants_user_id_idx ON game_participants (user_id);
+
+CREATE TABLE IF NOT EXISTS game_answers (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    participant_id UUID NOT NULL REFERENCES game_participants(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id),
+    answer JSONB,
+    correct BOOLEAN NOT NULL DEFAULT FALSE,
+    credit DOUBLE PRECISION NOT NULL DEFAULT 0,
+    points INT NOT NULL DEFAULT 0,
+    timed_out BOOLEAN NOT NULL DEFAULT FALSE,
+    started_at TIMESTAMPTZ NOT NULL,
+    answered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (participant_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS game_answers_session_id_idx ON game_answers (session_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS game_participants_team_id_idx;
+
+ALTER TABLE game_participants DROP COLUMN IF EXISTS team_id;
+
+DROP TABLE IF EXISTS game_teams;
+
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS team_scoring;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS team_assignment;
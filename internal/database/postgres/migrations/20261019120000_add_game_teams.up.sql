@@ -0,0 +1,16 @@
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS team_assignment VARCHAR(255);
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS team_scoring VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS game_teams (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS game_teams_session_name_idx ON game_teams (session_id, LOWER(name));
+
+ALTER TABLE game_participants ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES game_teams(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS game_participants_team_id_idx ON game_participants (team_id);
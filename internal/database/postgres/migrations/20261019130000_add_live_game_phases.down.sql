@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS game_sessions_live_idx;
+
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS phase_ends_at;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS phase_started_at;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS question_index;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS phase;
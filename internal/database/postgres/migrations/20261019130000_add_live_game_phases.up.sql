@@ -0,0 +1,6 @@
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS phase VARCHAR(255);
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS question_index INT NOT NULL DEFAULT 0;
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS phase_started_at TIMESTAMPTZ;
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS phase_ends_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS game_sessions_live_idx ON game_sessions (mode, phase);
@@ -0,0 +1,3 @@
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS host_seen_at;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS pause_reason;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS paused_at;
@@ -0,0 +1,3 @@
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS paused_at TIMESTAMPTZ;
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS pause_reason VARCHAR(255);
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS host_seen_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS game_audit_logs;
+
+ALTER TABLE game_participants DROP COLUMN IF EXISTS banned_at;
+ALTER TABLE game_sessions DROP COLUMN IF EXISTS locked_at;
@@ -0,0 +1,13 @@
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ;
+ALTER TABLE game_participants ADD COLUMN IF NOT EXISTS banned_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS game_audit_logs (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id),
+    action VARCHAR(255) NOT NULL,
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS game_audit_logs_session_id_idx ON game_audit_logs (session_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE game_sessions ADD COLUMN IF NOT EXISTS presenter_token_hash VARCHAR(255);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS game_participants_session_user_idx;
+
+DROP TABLE IF EXISTS game_summaries;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS game_summaries (
+    session_id UUID PRIMARY KEY REFERENCES game_sessions(id) ON DELETE CASCADE,
+    quiz_title VARCHAR(255) NOT NULL,
+    player_count INT NOT NULL DEFAULT 0,
+    question_count INT NOT NULL DEFAULT 0,
+    answer_count INT NOT NULL DEFAULT 0,
+    correct_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    average_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    winner_id UUID REFERENCES game_participants(id) ON DELETE SET NULL,
+    winner_nickname VARCHAR(255) NOT NULL DEFAULT '',
+    winner_score INT NOT NULL DEFAULT 0,
+    finished_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS game_participants_session_user_idx ON game_participants (session_id, user_id);
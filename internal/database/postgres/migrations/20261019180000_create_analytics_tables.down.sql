@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS question_time_buckets;
+DROP TABLE IF EXISTS question_option_stats;
+DROP TABLE IF EXISTS question_stats;
+DROP TABLE IF EXISTS analytics_rollups;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS analytics_rollups (
+    session_id UUID PRIMARY KEY REFERENCES game_sessions(id) ON DELETE CASCADE,
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    rolled_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS analytics_rollups_quiz_id_idx ON analytics_rollups (quiz_id);
+
+CREATE TABLE IF NOT EXISTS question_stats (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    quiz_id UUID NOT NULL REFERENCES quizzes(id) ON DELETE CASCADE,
+    answer_count INT NOT NULL DEFAULT 0,
+    correct_count INT NOT NULL DEFAULT 0,
+    upper_count INT NOT NULL DEFAULT 0,
+    upper_correct INT NOT NULL DEFAULT 0,
+    lower_count INT NOT NULL DEFAULT 0,
+    lower_correct INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS question_stats_quiz_id_idx ON question_stats (quiz_id);
+
+CREATE TABLE IF NOT EXISTS question_option_stats (
+    option_id UUID PRIMARY KEY REFERENCES question_options(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    pick_count INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS question_option_stats_question_id_idx ON question_option_stats (question_id);
+
+CREATE TABLE IF NOT EXISTS question_time_buckets (
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    seconds INT NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (question_id, seconds)
+);
+
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS admin_audit_logs;
+
+ALTER TABLE quizzes
+    DROP COLUMN IF EXISTS hidden_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS tokens_revoked_at,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,18 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(255) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS status VARCHAR(255) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS tokens_revoked_at TIMESTAMPTZ;
+
+ALTER TABLE quizzes
+    ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id UUID PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    target_id UUID NOT NULL,
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_logs_target_id_idx ON admin_audit_logs (target_id, created_at);
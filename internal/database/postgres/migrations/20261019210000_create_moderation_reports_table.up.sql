@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS moderation_reports (
+    id UUID PRIMARY KEY,
+    target_type VARCHAR(255) NOT NULL,
+    target_id UUID NOT NULL,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    reporter_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason VARCHAR(255) NOT NULL,
+    details TEXT,
+    status VARCHAR(255) NOT NULL,
+    resolution VARCHAR(255),
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS moderation_reports_queue_idx ON moderation_reports (status, created_at);
+
+-- a reporter, or the classifier, keeps a single open report per target
+CREATE UNIQUE INDEX IF NOT EXISTS moderation_reports_open_reporter_idx
+    ON moderation_reports (target_id, reporter_id) WHERE status = 'open';
+CREATE UNIQUE INDEX IF NOT EXISTS moderation_reports_open_classifier_idx
+    ON moderation_reports (target_id) WHERE status = 'open' AND reporter_id IS NULL;
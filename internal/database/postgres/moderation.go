@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/uptrace/bun"
+)
+
+type moderationReportRepo struct {
+	db *DB
+}
+
+func NewModerationReportRepository(db *DB) models.ModerationReportRepository {
+	return &moderationReportRepo{db: db}
+}
+
+func (m *moderationReportRepo) Create(ctx context.Context, report *models.ModerationReport) error {
+	ctx, cancel := m.db.WithContext(ctx)
+	defer cancel()
+
+	return m.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(report).Exec(ctx)
+		if err != nil && strings.Contains(err.Error(), "duplicate key") {
+			return database.ErrReportAlreadyOpen
+		}
+		return err
+	})
+}
+
+func (m *moderationReportRepo) FindOne(ctx context.Context, id string) (*models.ModerationReport, error) {
+	ctx, cancel := m.db.WithContext(ctx)
+	defer cancel()
+
+	var report models.ModerationReport
+	err := m.db.NewSelect().
+		Model(&report).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = database.ErrReportNotFound
+		}
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+func (m *moderationReportRepo) FindAll(ctx context.Context, opts *models.ListModerationReportOptions) ([]models.ModerationReport, int64, error) {
+	ctx, cancel := m.db.WithContext(ctx)
+	defer cancel()
+
+	reports := []models.ModerationReport{}
+	query := m.db.NewSelect().Model(&reports)
+
+	if opts.Status.IsValid() {
+		query.Where("status = ?", opts.Status.String())
+	}
+
+	if opts.TargetType.IsValid() {
+		query.Where("target_type = ?", opts.TargetType.String())
+	}
+
+	count, err := query.Clone().Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("created_at ASC").
+		Limit(int(opts.Paginator.PerPage)).
+		Offset(int(opts.Paginator.Offset())).
+		Scan(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	return reports, int64(count), nil
+}
+
+func (m *moderationReportRepo) Resolve(ctx context.Context, report *models.ModerationReport) error {
+	ctx, cancel := m.db.WithContext(ctx)
+	defer cancel()
+
+	return m.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*models.ModerationReport)(nil)).
+			Set("status = ?", report.Status).
+			Set("resolution = ?", report.Resolution).
+			Set("resolved_by = ?", report.ResolvedBy).
+			Set("resolved_at = ?", report.ResolvedAt).
+			Set("updated_at = ?", report.UpdatedAt).
+			Where("target_type = ?", report.TargetType).
+			Where("target_id = ?", report.TargetID).
+			Where("status = ?", models.ReportStatusOpen).
+			Exec(ctx)
+		return err
+	})
+}
@@ -21,6 +21,8 @@ func NewDB(cfg *config.Config) (*DB, error) {
 	sqldb := sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(cfg.Database.Postgres.DSN)))
 
 	db := bun.NewDB(sqldb, pgdialect.New())
+	db.AddQueryHook(&queryHook{})
+
 	err := db.Ping()
 	if err != nil {
 		return nil, fmt.Errorf("postgres: failed to ping database: %w", err)
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sidekik"
+	"github.com/uptrace/bun"
+)
+
+type questionRepo struct {
+	db *DB
+}
+
+func NewQuestionRepository(db *DB) models.QuestionRepository {
+	return &questionRepo{db: db}
+}
+
+func (q *questionRepo) CreateMany(ctx context.Context, questions []models.Question) error {
+	if len(questions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := q.db.WithContext(ctx)
+	defer cancel()
+
+	return q.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(&questions).Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		var options []models.QuestionOption
+		for _, question := range questions {
+			options = append(options, question.QuestionOptions...)
+		}
+
+		if len(options) == 0 {
+			return nil
+		}
+
+		_, err = tx.NewInsert().Model(&options).Exec(ctx)
+		return err
+	})
+}
+
+func (q *questionRepo) Update(ctx context.Context, question *models.Question) error {
+	ctx, cancel := q.db.WithContext(ctx)
+	defer cancel()
+
+	return q.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(question).
+			Where("id = ?", question.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func (q *questionRepo) FindOne(ctx context.Context, opts *models.FindQuestionOptions) (*models.Question, error) {
+	ctx, cancel := q.db.WithContext(ctx)
+	defer cancel()
+
+	var question models.Question
+	query := q.db.NewSelect().Model(&question)
+
+	if !sidekik.IsStringEmpty(opts.ID) {
+		query.Where("question.id = ?", opts.ID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.QuizID) {
+		query.Where("question.quiz_id = ?", opts.QuizID)
+	}
+
+	if err := query.
+		Relation("QuestionOptions", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("position ASC")
+		}).
+		Relation("QuestionType").
+		Relation("Attachment").
+		Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = database.ErrQuestionNotFound
+		}
+		return nil, err
+	}
+
+	return &question, nil
+}
@@ -63,9 +63,14 @@ func (q *quizRepo) FindOne(ctx context.Context, opts *models.FindQuizOptions) (*
 	}
 
 	if err := query.
-		Relation("Questions").
-		Relation("Questions.QuestionOptions").
+		Relation("Questions", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("position ASC")
+		}).
+		Relation("Questions.QuestionOptions", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("position ASC")
+		}).
 		Relation("Questions.QuestionType").
+		Relation("Questions.Attachment").
 		Scan(ctx); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			err = database.ErrQuizNotFound
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sidekik"
+	"github.com/uptrace/bun"
+)
+
+type uploadRepo struct {
+	db *DB
+}
+
+func NewUploadRepository(db *DB) models.UploadRepository {
+	return &uploadRepo{db: db}
+}
+
+func (u *uploadRepo) Create(ctx context.Context, upload *models.Upload) error {
+	ctx, cancel := u.db.WithContext(ctx)
+	defer cancel()
+
+	return u.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(upload).Exec(ctx)
+		return err
+	})
+}
+
+func (u *uploadRepo) Update(ctx context.Context, upload *models.Upload) error {
+	ctx, cancel := u.db.WithContext(ctx)
+	defer cancel()
+
+	return u.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(upload).
+			Where("id = ?", upload.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func (u *uploadRepo) FindOne(ctx context.Context, opts *models.FindUploadOptions) (*models.Upload, error) {
+	ctx, cancel := u.db.WithContext(ctx)
+	defer cancel()
+
+	var upload models.Upload
+	query := u.db.NewSelect().Model(&upload)
+
+	if !sidekik.IsStringEmpty(opts.ID) {
+		query.Where("id = ?", opts.ID)
+	}
+
+	if !sidekik.IsStringEmpty(opts.OwnerID) {
+		query.Where("owner_id = ?", opts.OwnerID)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = database.ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	return &upload, nil
+}
@@ -36,6 +36,19 @@ func (u *userRepo) Create(ctx context.Context, user *models.User) error {
 	})
 }
 
+func (u *userRepo) Update(ctx context.Context, user *models.User) error {
+	ctx, cancel := u.db.WithContext(ctx)
+	defer cancel()
+
+	return u.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(user).
+			Where("id = ?", user.ID).
+			Exec(ctx)
+		return err
+	})
+}
+
 func (u *userRepo) FindOne(ctx context.Context, opts *models.FindUserOptions) (*models.User, error) {
 	ctx, cancel := u.db.WithContext(ctx)
 	defer cancel()
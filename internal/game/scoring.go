@@ -0,0 +1,68 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+)
+
+const (
+	MaxPoints      = 1000
+	StreakBonus    = 100
+	MaxStreakBonus = 500
+
+	// AnswerGracePeriod absorbs network latency between the moment a
+	// player answers and the moment the server records it.
+	AnswerGracePeriod = 2 * time.Second
+)
+
+// TimeLimit converts a question's time limit in seconds to a duration, zero
+// meaning the question is untimed.
+func TimeLimit(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+// Expired reports whether a question started at startedAt can no longer be
+// answered at now.
+func Expired(startedAt, now time.Time, limit time.Duration) bool {
+	return limit > 0 && now.Sub(startedAt) > limit+AnswerGracePeriod
+}
+
+// Points scores an answer. Fast answers earn up to MaxPoints, dropping
+// linearly to half as the time limit runs out, scaled by the answer's
+// credit.
+func Points(kind questionkind.Kind, result questionkind.Result, elapsed, limit time.Duration) int {
+	if !kind.Scored() || result.Credit <= 0 {
+		return 0
+	}
+
+	speed := 1.0
+	if limit > 0 {
+		speed = 1 - min(float64(elapsed)/float64(limit), 1)/2
+	}
+
+	return int(math.Round(MaxPoints * result.Credit * speed))
+}
+
+// Streak returns the streak after an answer. Unscored questions leave the
+// streak untouched.
+func Streak(kind questionkind.Kind, result questionkind.Result, streak int) int {
+	switch {
+	case !kind.Scored():
+		return streak
+	case result.Correct:
+		return streak + 1
+	default:
+		return 0
+	}
+}
+
+// StreakPoints is the bonus for answering correctly with a streak of
+// consecutive correct answers, starting from the second in a row.
+func StreakPoints(streak int) int {
+	if streak < 2 {
+		return 0
+	}
+	return min((streak-1)*StreakBonus, MaxStreakBonus)
+}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+const (
+	MinTeams = 2
+	MaxTeams = 10
+)
+
+var ErrTeamRequired = errors.New("game: choose one of the session's teams")
+
+// JoiningTeam picks the team for a player joining session. Players choose
+// their own team in player choice sessions, auto balanced sessions place
+// them in the smallest team and the host places them in manual sessions.
+func JoiningTeam(session *models.GameSession, participants []models.GameParticipant, teamID string) (*string, error) {
+	switch session.TeamAssignment {
+	case models.TeamAssignmentPlayerChoice:
+		for _, team := range session.Teams {
+			if team.ID == teamID {
+				return &team.ID, nil
+			}
+		}
+		return nil, ErrTeamRequired
+	case models.TeamAssignmentAuto:
+		if team := SmallestTeam(session.Teams, participants); team != nil {
+			return &team.ID, nil
+		}
+		return nil, nil
+	default:
+		return nil, nil
+	}
+}
+
+// SmallestTeam returns the team with the fewest members, preferring the
+// earlier team on a tie, for auto balanced sessions to place a new player
+// in.
+func SmallestTeam(teams []models.GameTeam, participants []models.GameParticipant) *models.GameTeam {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	sizes := teamSizes(participants)
+	smallest := &teams[0]
+	for i := range teams[1:] {
+		team := &teams[i+1]
+		if sizes[team.ID] < sizes[smallest.ID] {
+			smallest = team
+		}
+	}
+
+	return smallest
+}
+
+// Balance spreads participants across teams so that team sizes differ by
+// at most one. Players without a team are placed first, then players are
+// moved from the largest teams, latest joiners first, so as few players
+// as possible change team. It returns the participants whose team
+// changed.
+func Balance(teams []models.GameTeam, participants []models.GameParticipant) []models.GameParticipant {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	members := make(map[string][]int, len(teams))
+	var unassigned []int
+	for i, participant := range participants {
+		if participant.TeamID == nil || !hasTeam(teams, *participant.TeamID) {
+			unassigned = append(unassigned, i)
+			continue
+		}
+		members[*participant.TeamID] = append(members[*participant.TeamID], i)
+	}
+
+	changed := make(map[int]bool)
+	move := func(idx int, team *models.GameTeam) {
+		teamID := team.ID
+		participants[idx].TeamID = &teamID
+		members[teamID] = append(members[teamID], idx)
+		changed[idx] = true
+	}
+
+	for _, idx := range unassigned {
+		move(idx, smallestOf(teams, members))
+	}
+
+	for {
+		smallest := smallestOf(teams, members)
+		largest := largestOf(teams, members)
+		if len(members[largest.ID])-len(members[smallest.ID]) <= 1 {
+			break
+		}
+
+		last := len(members[largest.ID]) - 1
+		idx := members[largest.ID][last]
+		members[largest.ID] = members[largest.ID][:last]
+		move(idx, smallest)
+	}
+
+	moved := make([]models.GameParticipant, 0, len(changed))
+	for i := range participants {
+		if changed[i] {
+			moved = append(moved, participants[i])
+		}
+	}
+
+	return moved
+}
+
+// TeamLeaderboard ranks teams from the individual leaderboard, scoring
+// each team as the sum, average or best of its members' scores. Teams on
+// the same score share a rank.
+func TeamLeaderboard(teams []models.GameTeam, entries []models.LeaderboardEntry, scoring models.TeamScoring) []models.TeamLeaderboardEntry {
+	scores := make(map[string][]int, len(teams))
+	for _, entry := range entries {
+		if entry.TeamID != nil {
+			scores[*entry.TeamID] = append(scores[*entry.TeamID], entry.Score)
+		}
+	}
+
+	board := make([]models.TeamLeaderboardEntry, 0, len(teams))
+	for _, team := range teams {
+		board = append(board, models.TeamLeaderboardEntry{
+			TeamID:      team.ID,
+			Name:        team.Name,
+			Score:       TeamScore(scores[team.ID], scoring),
+			MemberCount: len(scores[team.ID]),
+		})
+	}
+
+	sort.SliceStable(board, func(i, j int) bool {
+		return board[i].Score > board[j].Score
+	})
+
+	for i := range board {
+		board[i].Rank = i + 1
+		if i > 0 && board[i].Score == board[i-1].Score {
+			board[i].Rank = board[i-1].Rank
+		}
+	}
+
+	return board
+}
+
+// TeamScore combines member scores, a team without members scores zero.
+func TeamScore(scores []int, scoring models.TeamScoring) int {
+	if len(scores) == 0 {
+		return 0
+	}
+
+	switch scoring {
+	case models.TeamScoringBest:
+		best := scores[0]
+		for _, score := range scores[1:] {
+			best = max(best, score)
+		}
+		return best
+	case models.TeamScoringAverage:
+		return int(math.Round(float64(sum(scores)) / float64(len(scores))))
+	default:
+		return sum(scores)
+	}
+}
+
+func sum(scores []int) int {
+	total := 0
+	for _, score := range scores {
+		total += score
+	}
+	return total
+}
+
+func teamSizes(participants []models.GameParticipant) map[string]int {
+	sizes := make(map[string]int)
+	for _, participant := range participants {
+		if participant.TeamID != nil {
+			sizes[*participant.TeamID]++
+		}
+	}
+	return sizes
+}
+
+func hasTeam(teams []models.GameTeam, teamID string) bool {
+	for _, team := range teams {
+		if team.ID == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+func smallestOf(teams []models.GameTeam, members map[string][]int) *models.GameTeam {
+	smallest := &teams[0]
+	for i := range teams {
+		if len(members[teams[i].ID]) < len(members[smallest.ID]) {
+			smallest = &teams[i]
+		}
+	}
+	return smallest
+}
+
+func largestOf(teams []models.GameTeam, members map[string][]int) *models.GameTeam {
+	largest := &teams[0]
+	for i := range teams {
+		if len(members[teams[i].ID]) > len(members[largest.ID]) {
+			largest = &teams[i]
+		}
+	}
+	return largest
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	joinCodeLength = 8
+	// joinCodeAlphabet leaves out characters that are easily confused when
+	// read aloud or typed from a TV screen, such as 0/O and 1/I/L.
+	joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+)
+
+func NewJoinCode() string {
+	b := make([]byte, joinCodeLength)
+	_, _ = rand.Read(b)
+
+	for i := range b {
+		b[i] = joinCodeAlphabet[int(b[i])%len(joinCodeAlphabet)]
+	}
+	return string(b)
+}
+
+// NewParticipantToken returns a token for a player to identify with and
+// the hash of it that is stored.
+func NewParticipantToken() (string, string) {
+	return newToken()
+}
+
+// NewPresenterToken returns a token for a screen to present a game with
+// and the hash of it that is stored.
+func NewPresenterToken() (string, string) {
+	return newToken()
+}
+
+func newToken() (string, string) {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token)
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+const (
+	// StatusOK is reported for a check that passed.
+	StatusOK = "ok"
+	// StatusFailing is reported for a check that failed, why is only
+	// logged so probes do not give away the internals.
+	StatusFailing = "failing"
+)
+
+// Check reports why a dependency cannot serve traffic, nil when it can.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health tells load balancers whether this instance should receive
+// traffic.
+type Health struct {
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+func New() *Health {
+	return &Health{}
+}
+
+// Add registers check under name, checks run in the order they were added.
+func (h *Health) Add(name string, check Check) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes the instance report not ready from now on, so load balancers
+// stop routing to it while it shuts down.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready runs every check and returns the status of each, keyed by name.
+// ready is false when any check failed or the instance is draining.
+func (h *Health) Ready(ctx context.Context) (statuses map[string]string, ready bool) {
+	statuses = make(map[string]string, len(h.checks)+1)
+	ready = !h.draining.Load()
+	if !ready {
+		statuses["shutdown"] = "draining"
+	}
+
+	for _, c := range h.checks {
+		if err := c.check(ctx); err != nil {
+			slog.Warn("[health]: readiness check failed", slog.String("check", c.name), slog.Any("error", err))
+			statuses[c.name] = StatusFailing
+			ready = false
+			continue
+		}
+		statuses[c.name] = StatusOK
+	}
+
+	return statuses, ready
+}
@@ -0,0 +1,368 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/pkg/spreadsheet"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+const (
+	MaxQuestionRows          = 1000
+	DefaultTimeLimitDuration = 30
+
+	correctMarker = "*"
+)
+
+var (
+	ErrMissingQuestionColumn = errors.New("importer: the header row must contain a question column")
+	ErrTooManyRows           = fmt.Errorf("importer: a maximum of %d questions can be imported at once", MaxQuestionRows)
+)
+
+type OptionPreview struct {
+	Cell      string `json:"cell"`
+	Option    string `json:"option"`
+	IsCorrect bool   `json:"is_correct"`
+	Position  int    `json:"position"`
+	// Marker records where the correct answer was flagged, either the
+	// option cell itself or the cell of the answer column.
+	Marker string `json:"marker,omitempty"`
+}
+
+type QuestionPreview struct {
+	Row               int                  `json:"row"`
+	Question          string               `json:"question"`
+	QuestionType      *models.QuestionType `json:"question_type"`
+	OptionType        models.OptionType    `json:"option_type"`
+	TimeLimitDuration int                  `json:"time_limit_duration"`
+	Options           []OptionPreview      `json:"options"`
+	Settings          json.RawMessage      `json:"settings,omitempty"`
+	Errors            []string             `json:"errors,omitempty"`
+}
+
+type QuestionsPreview struct {
+	TotalRows   int               `json:"total_rows"`
+	ValidRows   int               `json:"valid_rows"`
+	InvalidRows int               `json:"invalid_rows"`
+	Rows        []QuestionPreview `json:"rows"`
+}
+
+func (p *QuestionsPreview) HasErrors() bool {
+	return p.InvalidRows > 0
+}
+
+// Questions converts the previewed rows into questions for quizID, with
+// positions continuing from startPosition.
+func (p *QuestionsPreview) Questions(quizID string, startPosition int) []models.Question {
+	questions := make([]models.Question, 0, len(p.Rows))
+	for i, row := range p.Rows {
+		questions = append(questions, row.question(quizID, startPosition+i))
+	}
+
+	return questions
+}
+
+func (r *QuestionPreview) question(quizID string, position int) models.Question {
+	question := models.Question{
+		ID:                utils.Uuid(),
+		QuizID:            quizID,
+		Question:          r.Question,
+		TimeLimitDuration: r.TimeLimitDuration,
+		Position:          position,
+		OptionType:        r.OptionType,
+		Settings:          r.Settings,
+		QuestionType:      r.QuestionType,
+	}
+
+	if r.QuestionType != nil {
+		question.QuestionTypeID = r.QuestionType.ID
+	}
+
+	for _, option := range r.Options {
+		question.QuestionOptions = append(question.QuestionOptions, models.QuestionOption{
+			ID:         utils.Uuid(),
+			QuestionID: question.ID,
+			Option:     option.Option,
+			IsCorrect:  option.IsCorrect,
+			Position:   option.Position,
+		})
+	}
+
+	return question
+}
+
+type columns struct {
+	question     int
+	questionType int
+	optionType   int
+	timeLimit    int
+	answer       int
+	options      []int
+}
+
+// ParseQuestions maps spreadsheet rows to questions. The first row is the
+// header; recognised columns are question, type, option_type, time_limit,
+// answer and any number of option columns. A correct option is flagged by
+// prefixing its cell with "*" or by listing it in the answer column as an
+// option number, letter or the option text itself. Question kinds without
+// options, such as true_false or slider, read their answer key from the
+// answer column instead. Each row is validated by its question kind.
+func ParseQuestions(rows [][]string, questionTypes []models.QuestionType) (*QuestionsPreview, error) {
+	if len(rows) == 0 {
+		return nil, spreadsheet.ErrEmptySheet
+	}
+
+	cols, err := parseHeader(rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	typesByKey := make(map[string]*models.QuestionType)
+	var activeTypes []*models.QuestionType
+	for i := range questionTypes {
+		questionType := &questionTypes[i]
+		if questionType.Status != models.QuestionTypeStatusActive {
+			continue
+		}
+		typesByKey[normalize(questionType.Slug)] = questionType
+		typesByKey[normalize(questionType.Name)] = questionType
+		activeTypes = append(activeTypes, questionType)
+	}
+
+	preview := &QuestionsPreview{Rows: []QuestionPreview{}}
+	for i, row := range rows[1:] {
+		if isBlank(row) {
+			continue
+		}
+
+		if len(preview.Rows) == MaxQuestionRows {
+			return nil, ErrTooManyRows
+		}
+
+		// header is row 1, so data starts at row 2
+		question := parseRow(i+2, row, cols, typesByKey, activeTypes)
+		if len(question.Errors) > 0 {
+			preview.InvalidRows++
+		} else {
+			preview.ValidRows++
+		}
+
+		preview.Rows = append(preview.Rows, question)
+	}
+
+	preview.TotalRows = len(preview.Rows)
+	return preview, nil
+}
+
+func parseHeader(header []string) (*columns, error) {
+	cols := &columns{question: -1, questionType: -1, optionType: -1, timeLimit: -1, answer: -1}
+
+	for i, cell := range header {
+		switch name := normalize(cell); {
+		case name == "question":
+			cols.question = i
+		case name == "type" || name == "question_type":
+			cols.questionType = i
+		case name == "option_type":
+			cols.optionType = i
+		case name == "time_limit" || name == "time_limit_duration":
+			cols.timeLimit = i
+		case name == "answer" || name == "answers" || name == "correct" || name == "correct_answer":
+			cols.answer = i
+		case strings.HasPrefix(name, "option"):
+			cols.options = append(cols.options, i)
+		}
+	}
+
+	if cols.question < 0 {
+		return nil, ErrMissingQuestionColumn
+	}
+
+	return cols, nil
+}
+
+func parseRow(rowNum int, row []string, cols *columns, typesByKey map[string]*models.QuestionType, activeTypes []*models.QuestionType) QuestionPreview {
+	preview := QuestionPreview{
+		Row:               rowNum,
+		Question:          cell(row, cols.question),
+		TimeLimitDuration: DefaultTimeLimitDuration,
+		Options:           []OptionPreview{},
+	}
+
+	addError := func(format string, args ...any) {
+		preview.Errors = append(preview.Errors, fmt.Sprintf(format, args...))
+	}
+
+	if preview.Question == "" {
+		addError("the question cell is empty")
+	}
+
+	typeName := cell(row, cols.questionType)
+	switch {
+	case typeName != "":
+		preview.QuestionType = typesByKey[normalize(typeName)]
+		if preview.QuestionType == nil {
+			addError("unknown question type %q", typeName)
+		}
+	case len(activeTypes) == 1:
+		preview.QuestionType = activeTypes[0]
+	default:
+		addError("the question type is required")
+	}
+
+	if timeLimit := cell(row, cols.timeLimit); timeLimit != "" {
+		duration, err := strconv.Atoi(timeLimit)
+		if err != nil || duration < 0 {
+			addError("time limit %q must be a whole number of seconds, 0 for no limit", timeLimit)
+		} else {
+			preview.TimeLimitDuration = duration
+		}
+	}
+
+	var kind questionkind.Kind
+	if preview.QuestionType != nil {
+		var err error
+		kind, err = questionkind.Lookup(preview.QuestionType.Slug)
+		if err != nil {
+			addError("question type %q is not supported", preview.QuestionType.Name)
+		}
+	}
+
+	for _, col := range cols.options {
+		value := cell(row, col)
+		if value == "" {
+			continue
+		}
+
+		option := OptionPreview{
+			Cell:     cellRef(col, rowNum),
+			Option:   value,
+			Position: len(preview.Options),
+		}
+
+		if strings.HasPrefix(value, correctMarker) {
+			option.Option = strings.TrimSpace(strings.TrimPrefix(value, correctMarker))
+			option.IsCorrect = true
+			option.Marker = option.Cell
+		}
+
+		preview.Options = append(preview.Options, option)
+	}
+
+	answer := cell(row, cols.answer)
+	if parser, ok := kind.(questionkind.AnswerKeyParser); ok {
+		// kinds without options keep their answer key in the settings
+		settings, err := parser.ParseAnswerKey(answer)
+		if err != nil {
+			addError("%s", err.Error())
+		}
+		preview.Settings = settings
+	} else if answer != "" {
+		for _, token := range strings.FieldsFunc(answer, isAnswerSeparator) {
+			token = strings.TrimSpace(token)
+			idx := answerIndex(token, preview.Options)
+			if idx < 0 {
+				addError("answer %q does not match any option", token)
+				continue
+			}
+
+			preview.Options[idx].IsCorrect = true
+			if preview.Options[idx].Marker == "" {
+				preview.Options[idx].Marker = cellRef(cols.answer, rowNum)
+			}
+		}
+	}
+
+	correctCount := 0
+	for _, option := range preview.Options {
+		if option.IsCorrect {
+			correctCount++
+		}
+	}
+
+	optionType := cell(row, cols.optionType)
+	switch {
+	case optionType != "":
+		parsed, err := models.ParseOptionType(normalize(optionType))
+		if err != nil {
+			addError("option type %q must be single_choice or multiple_choice", optionType)
+		}
+		preview.OptionType = parsed
+	case correctCount > 1:
+		preview.OptionType = models.OptionTypeMultipleChoice
+	default:
+		preview.OptionType = models.OptionTypeSingleChoice
+	}
+
+	if kind != nil && len(preview.Errors) == 0 {
+		question := preview.question("", 0)
+		if err := kind.Validate(&question); err != nil {
+			addError("%s", err.Error())
+		}
+	}
+
+	return preview
+}
+
+// answerIndex resolves an answer token to an option by its 1-based number,
+// its letter (A for the first option) or its text.
+func answerIndex(token string, options []OptionPreview) int {
+	if n, err := strconv.Atoi(token); err == nil {
+		if n >= 1 && n <= len(options) {
+			return n - 1
+		}
+		return -1
+	}
+
+	if len(token) == 1 {
+		letter := strings.ToUpper(token)[0]
+		if letter >= 'A' && int(letter-'A') < len(options) {
+			return int(letter - 'A')
+		}
+	}
+
+	for i, option := range options {
+		if strings.EqualFold(option.Option, token) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isAnswerSeparator(r rune) bool {
+	return r == ',' || r == ';' || r == '|'
+}
+
+func cell(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+func cellRef(col, row int) string {
+	return spreadsheet.ColumnName(col) + strconv.Itoa(row)
+}
+
+func normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+func isBlank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+const (
+	defaultMaxAttempts = 10
+	defaultTimeout     = 5 * time.Minute
+)
+
+var errPermanent = errors.New("jobs: permanent failure")
+
+// Permanent marks err as a failure that trying again cannot fix, the job
+// is dead-lettered right away instead of being retried.
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", errPermanent, err)
+}
+
+// Kind names a job and the arguments it runs with. Jobs are enqueued and
+// handled through their kind, so both sides agree on the arguments.
+type Kind[T any] struct {
+	Name string
+	// MaxAttempts is how often a failing job runs before it is dead,
+	// 10 when left zero.
+	MaxAttempts int
+	// Timeout bounds a single run, 5 minutes when left zero.
+	Timeout time.Duration
+}
+
+// Enqueue adds a job to run as soon as a worker is free.
+func (k Kind[T]) Enqueue(ctx context.Context, q *Queue, args T) error {
+	return k.EnqueueAt(ctx, q, args, time.Now())
+}
+
+// EnqueueAt adds a job to run once runAt has passed.
+func (k Kind[T]) EnqueueAt(ctx context.Context, q *Queue, args T, runAt time.Time) error {
+	job, err := k.job(args, runAt)
+	if err != nil {
+		return err
+	}
+
+	return q.repo.Enqueue(ctx, job)
+}
+
+// Handle runs the kind's jobs on w with fn. A job whose arguments no
+// longer decode is dead-lettered.
+func (k Kind[T]) Handle(w *Worker, fn func(context.Context, T) error) {
+	w.handle(k.Name, k.timeout(), func(ctx context.Context, payload json.RawMessage) error {
+		var args T
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return Permanent(err)
+		}
+		return fn(ctx, args)
+	})
+}
+
+// Schedule enqueues a job of the kind with args every time the cron spec
+// matches. Workers share schedules, each run is enqueued once however
+// many workers schedule it, and runs missed while no worker was up are
+// made up for by a single one.
+func (k Kind[T]) Schedule(w *Worker, spec string, args T) error {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	if cron.Next(time.Now()).IsZero() {
+		return fmt.Errorf("%w: %q never runs", ErrInvalidCron, spec)
+	}
+
+	if _, err := k.job(args, time.Now()); err != nil {
+		return err
+	}
+
+	w.schedule(k.Name, spec, cron, func(runAt time.Time) (*models.Job, error) {
+		return k.job(args, runAt)
+	})
+	return nil
+}
+
+func (k Kind[T]) job(args T, runAt time.Time) (*models.Job, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts := k.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	now := time.Now()
+	return &models.Job{
+		ID:          utils.Uuid(),
+		Kind:        k.Name,
+		Payload:     payload,
+		Status:      models.JobStatusPending,
+		MaxAttempts: maxAttempts,
+		RunAt:       runAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+func (k Kind[T]) timeout() time.Duration {
+	if k.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return k.Timeout
+}
+
+// Queue enqueues jobs for workers to run, it is what the request path
+// hands work off with.
+type Queue struct {
+	repo models.JobRepository
+}
+
+func NewQueue(repo models.JobRepository) *Queue {
+	return &Queue{repo: repo}
+}
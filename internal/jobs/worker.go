@@ -0,0 +1,304 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+const (
+	// scheduleInterval is how often due cron schedules are looked for,
+	// well within their one minute resolution.
+	scheduleInterval = 15 * time.Second
+	// leaseSlack keeps a job locked a little past its timeout, so a job
+	// that is still wrapping up is not claimed by another worker.
+	leaseSlack = time.Minute
+
+	minBackoff = 10 * time.Second
+	maxBackoff = time.Hour
+
+	// finishTimeout bounds recording how a job went, which happens after
+	// the job's own context may be done.
+	finishTimeout = 10 * time.Second
+)
+
+type handler struct {
+	timeout time.Duration
+	run     func(ctx context.Context, payload json.RawMessage) error
+}
+
+type schedule struct {
+	name string
+	spec string
+	cron *Cron
+	job  func(runAt time.Time) (*models.Job, error)
+}
+
+// Worker claims jobs from the queue and runs them with their handlers.
+// Register handlers and schedules before calling Start.
+type Worker struct {
+	id           string
+	repo         models.JobRepository
+	concurrency  int
+	pollInterval time.Duration
+
+	handlers  map[string]handler
+	schedules []schedule
+	lockFor   time.Duration
+
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	// ctx stops claiming, jobCtx stops the jobs already running, which are
+	// given until the drain deadline to finish
+	ctx        context.Context
+	stop       context.CancelFunc
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	done       chan struct{}
+}
+
+func NewWorker(cfg *config.Config, repo models.JobRepository) *Worker {
+	hostname, _ := os.Hostname()
+
+	ctx, stop := context.WithCancel(context.Background())
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+
+	return &Worker{
+		// the id only has to tell workers apart in the jobs they lock
+		id:           fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), utils.Uuid()[:8]),
+		repo:         repo,
+		concurrency:  max(cfg.Jobs.Concurrency, 1),
+		pollInterval: cfg.Jobs.PollInterval,
+		handlers:     make(map[string]handler),
+		slots:        make(chan struct{}, max(cfg.Jobs.Concurrency, 1)),
+		ctx:          ctx,
+		stop:         stop,
+		jobCtx:       jobCtx,
+		cancelJobs:   cancelJobs,
+		done:         make(chan struct{}),
+	}
+}
+
+func (w *Worker) handle(kind string, timeout time.Duration, run func(context.Context, json.RawMessage) error) {
+	w.handlers[kind] = handler{timeout: timeout, run: run}
+	w.lockFor = max(w.lockFor, timeout+leaseSlack)
+}
+
+func (w *Worker) schedule(name, spec string, cron *Cron, job func(time.Time) (*models.Job, error)) {
+	w.schedules = append(w.schedules, schedule{name: name, spec: spec, cron: cron, job: job})
+}
+
+// Start claims and runs jobs in the background until Drain is called.
+func (w *Worker) Start() {
+	go w.run()
+}
+
+// Drain stops claiming jobs and waits for the running ones to finish.
+// Jobs still running when ctx is done are stopped and put back in the
+// queue for another worker, without counting the attempt.
+func (w *Worker) Drain(ctx context.Context) error {
+	w.stop()
+	<-w.done
+
+	finished := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		w.cancelJobs()
+		<-finished
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) run() {
+	defer close(w.done)
+
+	w.saveSchedules()
+
+	poll := time.NewTicker(w.pollInterval)
+	defer poll.Stop()
+
+	scheduleTicker := time.NewTicker(scheduleInterval)
+	defer scheduleTicker.Stop()
+
+	for {
+		w.runDueSchedules()
+		w.claim()
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-poll.C:
+		case <-scheduleTicker.C:
+		}
+	}
+}
+
+func (w *Worker) claim() {
+	kinds := make([]string, 0, len(w.handlers))
+	for kind := range w.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	free := w.concurrency - len(w.slots)
+	if len(kinds) == 0 || free == 0 {
+		return
+	}
+
+	jobs, err := w.repo.Claim(w.ctx, w.id, kinds, free, w.lockFor)
+	if err != nil {
+		if w.ctx.Err() == nil {
+			slog.Error("[jobs worker]: could not claim jobs", slog.Any("error", err))
+		}
+		return
+	}
+
+	for i := range jobs {
+		w.slots <- struct{}{}
+		w.wg.Add(1)
+		go func(job *models.Job) {
+			defer func() {
+				<-w.slots
+				w.wg.Done()
+			}()
+			w.execute(job)
+		}(&jobs[i])
+	}
+}
+
+func (w *Worker) execute(job *models.Job) {
+	logger := slog.With(slog.String("job_id", job.ID), slog.String("kind", job.Kind), slog.Int("attempt", job.Attempts))
+
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// its last worker died while running it
+		err = Permanent(errors.New("lock expired on the last attempt"))
+	} else {
+		err = w.runJob(job)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	switch {
+	case err == nil:
+		err = w.repo.Complete(ctx, job)
+	case w.jobCtx.Err() != nil:
+		logger.Info("[jobs worker]: job stopped by drain, releasing it")
+		err = w.repo.Release(ctx, job)
+	case errors.Is(err, errPermanent) || job.Attempts >= job.MaxAttempts:
+		logger.Error("[jobs worker]: job failed for good", slog.Any("error", err))
+		err = w.repo.Kill(ctx, job, err.Error())
+	default:
+		logger.Warn("[jobs worker]: job failed, retrying", slog.Any("error", err))
+		err = w.repo.Retry(ctx, job, time.Now().Add(backoff(job.Attempts)), err.Error())
+	}
+
+	if err != nil {
+		logger.Error("[jobs worker]: could not record job result", slog.Any("error", err))
+	}
+}
+
+func (w *Worker) runJob(job *models.Job) (err error) {
+	h, ok := w.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for %s", job.Kind))
+	}
+
+	ctx, cancel := context.WithTimeout(w.jobCtx, h.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return h.run(ctx, job.Payload)
+}
+
+func (w *Worker) saveSchedules() {
+	now := time.Now()
+	for _, s := range w.schedules {
+		err := w.repo.SaveSchedule(w.ctx, &models.JobSchedule{
+			Name:      s.name,
+			Spec:      s.spec,
+			NextRunAt: s.cron.Next(now),
+			UpdatedAt: now,
+		})
+		if err != nil && w.ctx.Err() == nil {
+			slog.Error("[jobs worker]: could not save schedule", slog.String("name", s.name), slog.Any("error", err))
+		}
+	}
+}
+
+func (w *Worker) runDueSchedules() {
+	if len(w.schedules) == 0 {
+		return
+	}
+
+	bySchedule := make(map[string]schedule, len(w.schedules))
+	names := make([]string, 0, len(w.schedules))
+	for _, s := range w.schedules {
+		bySchedule[s.name] = s
+		names = append(names, s.name)
+	}
+
+	now := time.Now()
+	due, err := w.repo.FindDueSchedules(w.ctx, names, now)
+	if err != nil {
+		if w.ctx.Err() == nil {
+			slog.Error("[jobs worker]: could not find due schedules", slog.Any("error", err))
+		}
+		return
+	}
+
+	for i := range due {
+		due := &due[i]
+		s := bySchedule[due.Name]
+
+		// a schedule saved by a worker running another spec is left to
+		// that worker
+		if due.Spec != s.spec {
+			continue
+		}
+
+		job, err := s.job(due.NextRunAt)
+		if err != nil {
+			slog.Error("[jobs worker]: could not build scheduled job", slog.String("name", s.name), slog.Any("error", err))
+			continue
+		}
+
+		from := due.NextRunAt
+		due.NextRunAt = s.cron.Next(now)
+		due.UpdatedAt = now
+
+		if _, err := w.repo.AdvanceSchedule(w.ctx, due, from, job); err != nil && w.ctx.Err() == nil {
+			slog.Error("[jobs worker]: could not enqueue scheduled job", slog.String("name", s.name), slog.Any("error", err))
+		}
+	}
+}
+
+// backoff spaces out retries, doubling with every attempt up to an hour.
+// The jitter keeps jobs that failed together from retrying together.
+func backoff(attempt int) time.Duration {
+	d := min(minBackoff<<min(attempt-1, 16), maxBackoff)
+	return d/2 + rand.N(d/2)
+}
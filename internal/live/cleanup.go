@@ -0,0 +1,57 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+// CleanupJob ends the live games their hosts abandoned, it runs on a
+// schedule.
+var CleanupJob = jobs.Kind[CleanupArgs]{Name: "live.cleanup", MaxAttempts: 3}
+
+type CleanupArgs struct{}
+
+// HandleCleanup runs CleanupJob.
+func (e *Engine) HandleCleanup(ctx context.Context, _ CleanupArgs) error {
+	_, err := e.EndAbandoned(ctx, time.Now().Add(-e.abandonAfter))
+	return err
+}
+
+// EndAbandoned ends the live games that have neither seen their host nor
+// changed since before, so they stop being driven and can be reported on.
+// It returns how many games it ended.
+func (e *Engine) EndAbandoned(ctx context.Context, before time.Time) (int, error) {
+	sessions, err := e.sessionRepo.FindAbandoned(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	ended := 0
+	for i := range sessions {
+		session := &sessions[i]
+
+		from := session.Phase
+		if err := e.finish(ctx, session); err != nil {
+			// the host came back in the meantime
+			if errors.Is(err, ErrWrongPhase) {
+				continue
+			}
+			return ended, err
+		}
+		ended++
+
+		e.audit(ctx, session.ID, nil, models.GameAuditActionEnd, map[string]any{
+			"phase":           from,
+			"question_number": session.QuestionIndex + 1,
+			"reason":          "abandoned",
+		})
+		slog.Info("[live engine]: ended abandoned game", slog.String("session_id", session.ID))
+	}
+
+	return ended, nil
+}
@@ -0,0 +1,357 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+// MaxExtension is the most time a host can add to a question at once.
+const MaxExtension = 300 * time.Second
+
+// Pause stops the clock of the current question until the host unpauses
+// the game.
+func (e *Engine) Pause(ctx context.Context, sessionID, hostID string) error {
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if session.Phase != models.GamePhaseQuestion || session.IsPaused() {
+		return ErrWrongPhase
+	}
+
+	if err := e.pause(ctx, session, models.GamePauseReasonHost); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionPause, map[string]any{
+		"question_number": session.QuestionIndex + 1,
+	})
+	return nil
+}
+
+// Unpause restarts the clock, whatever paused the game.
+func (e *Engine) Unpause(ctx context.Context, sessionID, hostID string) error {
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if !session.IsPaused() {
+		return ErrWrongPhase
+	}
+
+	reason := session.PauseReason
+	if err := e.resume(ctx, session); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionUnpause, map[string]any{
+		"question_number": session.QuestionIndex + 1,
+		"reason":          reason,
+	})
+	return nil
+}
+
+// Skip moves on from the current question without revealing it, points
+// already scored on it stand.
+func (e *Engine) Skip(ctx context.Context, sessionID, hostID string) error {
+	session, quiz, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if session.Phase != models.GamePhaseQuestion {
+		return ErrWrongPhase
+	}
+
+	skipped := session.QuestionIndex + 1
+	if err := e.startQuestion(ctx, session, quiz, session.QuestionIndex+1); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionSkip, map[string]any{
+		"question_number": skipped,
+	})
+	return nil
+}
+
+// Extend gives players more time to answer the current question.
+func (e *Engine) Extend(ctx context.Context, sessionID, hostID string, by time.Duration) error {
+	if by < time.Second || by > MaxExtension {
+		return ErrInvalidExtend
+	}
+
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if session.Phase != models.GamePhaseQuestion {
+		return ErrWrongPhase
+	}
+
+	if session.PhaseEndsAt == nil {
+		return ErrNoTimeLimit
+	}
+
+	from := *session
+
+	session.PhaseEndsAt = utils.Ptr(session.PhaseEndsAt.Add(by))
+	session.UpdatedAt = time.Now()
+
+	if err := e.transition(ctx, session, &from); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionExtend, map[string]any{
+		"question_number": session.QuestionIndex + 1,
+		"seconds":         int(by / time.Second),
+	})
+	return nil
+}
+
+// Kick removes a player from the game along with their answers. The
+// player can join again while the lobby is open.
+func (e *Engine) Kick(ctx context.Context, sessionID, hostID, participantID string) error {
+	session, participant, err := e.hostedPlayer(ctx, sessionID, hostID, participantID)
+	if err != nil {
+		return err
+	}
+
+	if err := e.participantRepo.Delete(ctx, participant); err != nil {
+		return err
+	}
+
+	e.removed(ctx, session.ID, participant.ID)
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionKick, map[string]any{
+		"participant_id": participant.ID,
+		"nickname":       participant.Nickname,
+	})
+	return nil
+}
+
+// Ban removes a player from the game for good. Signed in players cannot
+// join again, and the nickname stays taken.
+func (e *Engine) Ban(ctx context.Context, sessionID, hostID, participantID string) error {
+	session, participant, err := e.hostedPlayer(ctx, sessionID, hostID, participantID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	participant.BannedAt = utils.Ptr(now)
+	participant.UpdatedAt = now
+
+	if err := e.participantRepo.Ban(ctx, participant); err != nil {
+		return err
+	}
+
+	e.removed(ctx, session.ID, participant.ID)
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionBan, map[string]any{
+		"participant_id": participant.ID,
+		"nickname":       participant.Nickname,
+		"user_id":        participant.UserID,
+	})
+	return nil
+}
+
+// Lock stops new players from joining the lobby, or lets them join again.
+func (e *Engine) Lock(ctx context.Context, sessionID, hostID string, locked bool) error {
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if session.Phase != models.GamePhaseLobby || session.IsLocked() == locked {
+		return ErrWrongPhase
+	}
+
+	from := *session
+
+	now := time.Now()
+	session.LockedAt = nil
+	if locked {
+		session.LockedAt = utils.Ptr(now)
+	}
+	session.UpdatedAt = now
+
+	if err := e.transition(ctx, session, &from); err != nil {
+		return err
+	}
+
+	action := models.GameAuditActionUnlock
+	if locked {
+		action = models.GameAuditActionLock
+	}
+
+	e.audit(ctx, session.ID, &hostID, action, nil)
+	return nil
+}
+
+// End finishes the game early, wherever it is.
+func (e *Engine) End(ctx context.Context, sessionID, hostID string) error {
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if !session.IsLive() {
+		return ErrWrongPhase
+	}
+
+	from := session.Phase
+	if err := e.finish(ctx, session); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionEnd, map[string]any{
+		"phase":           from,
+		"question_number": session.QuestionIndex + 1,
+	})
+	return nil
+}
+
+// AssignTeam moves a player to one of the game's teams, whichever way
+// teams are assigned in the game.
+func (e *Engine) AssignTeam(ctx context.Context, sessionID, hostID, participantID, teamID string) error {
+	session, participant, err := e.hostedPlayer(ctx, sessionID, hostID, participantID)
+	if err != nil {
+		return err
+	}
+
+	if !session.HasTeams() {
+		return ErrNoTeams
+	}
+
+	if !slices.ContainsFunc(session.Teams, func(team models.GameTeam) bool { return team.ID == teamID }) {
+		return ErrTeamNotFound
+	}
+
+	from := participant.TeamID
+	participant.TeamID = utils.Ptr(teamID)
+	participant.UpdatedAt = time.Now()
+
+	if err := e.participantRepo.AssignTeams(ctx, []models.GameParticipant{*participant}); err != nil {
+		return err
+	}
+
+	e.notify(ctx, session.ID)
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionAssignTeam, map[string]any{
+		"participant_id": participant.ID,
+		"from_team_id":   from,
+		"team_id":        teamID,
+	})
+	return nil
+}
+
+// BalanceTeams evens out team sizes, placing players without a team and
+// moving as few of the others as possible.
+func (e *Engine) BalanceTeams(ctx context.Context, sessionID, hostID string) error {
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if !session.IsLive() {
+		return ErrWrongPhase
+	}
+
+	if !session.HasTeams() {
+		return ErrNoTeams
+	}
+
+	participants, err := e.participantRepo.FindAll(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+
+	moved := game.Balance(session.Teams, participants)
+	if len(moved) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for i := range moved {
+		moved[i].UpdatedAt = now
+	}
+
+	if err := e.participantRepo.AssignTeams(ctx, moved); err != nil {
+		return err
+	}
+
+	e.notify(ctx, session.ID)
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionBalanceTeams, map[string]any{
+		"moved": len(moved),
+	})
+	return nil
+}
+
+// hostedPlayer loads a game for its host along with one of its players.
+func (e *Engine) hostedPlayer(ctx context.Context, sessionID, hostID, participantID string) (*models.GameSession, *models.GameParticipant, error) {
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !session.IsLive() {
+		return nil, nil, ErrWrongPhase
+	}
+
+	participant, err := e.participantRepo.FindOne(ctx, &models.FindGameParticipantOptions{
+		ID:        participantID,
+		SessionID: session.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGameParticipantNotFound) {
+			return nil, nil, ErrPlayerNotFound
+		}
+		return nil, nil, err
+	}
+
+	if participant.BannedAt != nil {
+		return nil, nil, ErrPlayerNotFound
+	}
+
+	return session, participant, nil
+}
+
+// removed tells every node to drop the player's connections before the
+// game changes for everyone else.
+func (e *Engine) removed(ctx context.Context, sessionID, participantID string) {
+	e.publish(ctx, sessionID, event{Type: "removed", ParticipantID: participantID})
+}
+
+// audit records an action on the game. The action has already happened by
+// then, so failing to record it is only logged.
+func (e *Engine) audit(ctx context.Context, sessionID string, actorID *string, action models.GameAuditAction, details map[string]any) {
+	entry := &models.GameAuditLog{
+		ID:        utils.Uuid(),
+		SessionID: sessionID,
+		ActorID:   actorID,
+		Action:    action,
+		CreatedAt: time.Now(),
+	}
+
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			slog.Error("[live engine]: could not encode audit details", slog.Any("error", err))
+		}
+		entry.Details = raw
+	}
+
+	if err := e.auditRepo.Create(ctx, entry); err != nil {
+		slog.Error("[live engine]: could not record host action",
+			slog.String("session_id", sessionID), slog.String("action", action.String()), slog.Any("error", err))
+	}
+}
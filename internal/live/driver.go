@@ -0,0 +1,165 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/broker"
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+const claimInterval = 2 * time.Second
+
+// Run drives the timers of live games until ctx is done. Every node runs
+// it, and each game is driven by whichever node holds the game's lease,
+// so only one node closes a question when its time runs out. Games whose
+// node goes away are claimed by another node on its next pass.
+func (e *Engine) Run(ctx context.Context) {
+	var (
+		mu     sync.Mutex
+		driven = make(map[string]struct{})
+		wg     sync.WaitGroup
+	)
+
+	ticker := time.NewTicker(claimInterval)
+	defer ticker.Stop()
+
+	for {
+		sessions, err := e.sessionRepo.FindLive(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("[live engine]: could not list live games", slog.Any("error", err))
+		}
+
+		for _, session := range sessions {
+			mu.Lock()
+			_, ok := driven[session.ID]
+			mu.Unlock()
+			if ok {
+				continue
+			}
+
+			lease, err := e.elector.TryAcquire(ctx, Topic(session.ID))
+			if err != nil {
+				if !errors.Is(err, broker.ErrNotLeader) && ctx.Err() == nil {
+					slog.Error("[live engine]: could not claim game", slog.String("session_id", session.ID), slog.Any("error", err))
+				}
+				continue
+			}
+
+			mu.Lock()
+			driven[session.ID] = struct{}{}
+			mu.Unlock()
+
+			wg.Add(1)
+			go func(sessionID string) {
+				defer wg.Done()
+				defer func() {
+					mu.Lock()
+					delete(driven, sessionID)
+					mu.Unlock()
+				}()
+
+				e.drive(ctx, sessionID, lease)
+			}(session.ID)
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drive closes the game's questions as their time runs out, for as long
+// as this node holds the lease and the game is running.
+func (e *Engine) drive(ctx context.Context, sessionID string, lease broker.Lease) {
+	metrics.LiveGames.Inc()
+	defer metrics.LiveGames.Dec()
+
+	defer func() {
+		// release with a fresh context, ctx may be done on shutdown
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := lease.Release(releaseCtx); err != nil {
+			slog.Error("[live engine]: could not release game", slog.String("session_id", sessionID), slog.Any("error", err))
+		}
+	}()
+
+	sub := e.broker.Subscribe(Topic(sessionID))
+	defer sub.Close()
+
+	for {
+		session, quiz, err := e.load(ctx, sessionID)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("[live engine]: could not load game", slog.String("session_id", sessionID), slog.Any("error", err))
+			}
+			return
+		}
+
+		if !session.IsLive() {
+			return
+		}
+
+		if !e.wait(ctx, session, quiz, lease, sub) {
+			return
+		}
+	}
+}
+
+// wait blocks until the game changes, its question runs out of time or
+// its host has been away for too long, returning false when the game
+// should no longer be driven from here.
+func (e *Engine) wait(ctx context.Context, session *models.GameSession, quiz *models.Quiz, lease broker.Lease, sub *broker.Subscription) bool {
+	var expired <-chan time.Time
+	if session.Phase == models.GamePhaseQuestion && session.PhaseEndsAt != nil && !session.IsPaused() {
+		timer := time.NewTimer(time.Until(session.PhaseEndsAt.Add(game.AnswerGracePeriod)))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var hostAway <-chan time.Time
+	if awayAt, ok := e.hostAwayAt(session); ok {
+		timer := time.NewTimer(time.Until(awayAt))
+		defer timer.Stop()
+		hostAway = timer.C
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		return false
+	case <-lease.Lost():
+		return false
+	case _, ok := <-sub.C:
+		return ok
+	case <-expired:
+		err = e.reveal(ctx, session, quiz)
+		if err != nil && !errors.Is(err, ErrWrongPhase) {
+			slog.Error("[live engine]: could not close question", slog.String("session_id", session.ID), slog.Any("error", err))
+		}
+	case <-hostAway:
+		err = e.pauseIfHostAway(ctx, session.ID)
+		if err != nil && !errors.Is(err, ErrWrongPhase) {
+			slog.Error("[live engine]: could not pause game", slog.String("session_id", session.ID), slog.Any("error", err))
+		}
+	}
+
+	if err != nil && !errors.Is(err, ErrWrongPhase) {
+		// retry shortly rather than spinning on a failing database
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Second):
+		}
+	}
+	return true
+}
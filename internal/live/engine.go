@@ -0,0 +1,505 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/broker"
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+	"github.com/oxiginedev/sabipass/internal/report"
+	"github.com/oxiginedev/sabipass/internal/tracing"
+	"github.com/oxiginedev/sabipass/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const maxNicknameLength = 30
+
+var (
+	ErrNotFound        = errors.New("live: game not found")
+	ErrWrongPhase      = errors.New("live: the game is not at that point")
+	ErrLobbyClosed     = errors.New("live: the game has already started")
+	ErrInvalidNickname = errors.New("live: the nickname must be between 1 and 30 characters")
+	ErrWrongQuestion   = errors.New("live: that is not the current question")
+	ErrTimeUp          = errors.New("live: time is up for this question")
+	ErrInvalidAnswer   = errors.New("live: the answer does not fit this question")
+	ErrAlreadyAnswered = errors.New("live: this question has already been answered")
+	ErrPaused          = errors.New("live: the game is paused")
+	ErrUnknownPlayer   = errors.New("live: no player in this game has that token")
+	ErrNotHost         = errors.New("live: only the host can do that")
+	ErrLobbyLocked     = errors.New("live: the lobby is locked")
+	ErrBanned          = errors.New("live: the player is banned from this game")
+	ErrPlayerNotFound  = errors.New("live: player not found")
+	ErrNoTimeLimit     = errors.New("live: the question has no time limit")
+	ErrInvalidExtend   = errors.New("live: the time limit can be extended by 1 to 300 seconds")
+	ErrNoTeams         = errors.New("live: the game is not played in teams")
+	ErrTeamNotFound    = errors.New("live: team not found")
+)
+
+// Engine runs live games. Game state lives in the database and every
+// change is a conditional update, so any node can handle a player's or
+// host's message; the broker then tells every node that the game changed.
+// Timers are the exception, see Run.
+type Engine struct {
+	quizRepo        models.QuizRepository
+	sessionRepo     models.GameSessionRepository
+	participantRepo models.GameParticipantRepository
+	answerRepo      models.GameAnswerRepository
+	auditRepo       models.GameAuditLogRepository
+	reporter        *report.Reporter
+	broker          broker.Broker
+	elector         broker.Elector
+	hostGracePeriod time.Duration
+	abandonAfter    time.Duration
+}
+
+func NewEngine(cfg *config.Config,
+	quizRepo models.QuizRepository,
+	sessionRepo models.GameSessionRepository,
+	participantRepo models.GameParticipantRepository,
+	answerRepo models.GameAnswerRepository,
+	auditRepo models.GameAuditLogRepository,
+	reporter *report.Reporter,
+	b broker.Broker,
+	elector broker.Elector,
+) *Engine {
+	return &Engine{
+		quizRepo:        quizRepo,
+		sessionRepo:     sessionRepo,
+		participantRepo: participantRepo,
+		answerRepo:      answerRepo,
+		auditRepo:       auditRepo,
+		reporter:        reporter,
+		broker:          b,
+		elector:         elector,
+		// the host's connection is only seen every HostHeartbeatInterval,
+		// a shorter grace period would pause games with the host present
+		hostGracePeriod: max(cfg.Live.HostGracePeriod, 2*HostHeartbeatInterval),
+		abandonAfter:    cfg.Live.AbandonAfter,
+	}
+}
+
+// Join adds a player to a game that has not started yet and returns the
+// participant with the token it reconnects with.
+func (e *Engine) Join(ctx context.Context, sessionID string, userID *string, nickname, teamID string) (*models.GameParticipant, string, error) {
+	session, err := e.session(ctx, sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if session.Phase != models.GamePhaseLobby {
+		return nil, "", ErrLobbyClosed
+	}
+
+	if session.IsLocked() {
+		return nil, "", ErrLobbyLocked
+	}
+
+	if userID != nil {
+		banned, err := e.participantRepo.FindOne(ctx, &models.FindGameParticipantOptions{
+			SessionID: session.ID,
+			UserID:    *userID,
+		})
+		if err != nil && !errors.Is(err, database.ErrGameParticipantNotFound) {
+			return nil, "", err
+		}
+
+		if banned != nil && banned.BannedAt != nil {
+			return nil, "", ErrBanned
+		}
+	}
+
+	nickname = strings.TrimSpace(nickname)
+	if nickname == "" || len(nickname) > maxNicknameLength {
+		return nil, "", ErrInvalidNickname
+	}
+
+	token, tokenHash := game.NewParticipantToken()
+	participant := &models.GameParticipant{
+		ID:        utils.Uuid(),
+		SessionID: session.ID,
+		UserID:    userID,
+		Nickname:  nickname,
+		TokenHash: tokenHash,
+	}
+
+	if session.HasTeams() {
+		participants, err := e.participantRepo.FindAll(ctx, session.ID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		participant.TeamID, err = game.JoiningTeam(session, participants, teamID)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := e.participantRepo.Create(ctx, participant); err != nil {
+		return nil, "", err
+	}
+
+	e.notify(ctx, session.ID)
+	return participant, token, nil
+}
+
+// Resume finds the player a token was handed to when joining, so a player
+// whose connection dropped can carry on where they left off.
+func (e *Engine) Resume(ctx context.Context, sessionID, token string) (*models.GameParticipant, error) {
+	session, err := e.session(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := e.participantRepo.FindOne(ctx, &models.FindGameParticipantOptions{
+		SessionID: session.ID,
+		TokenHash: game.HashToken(token),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGameParticipantNotFound) {
+			return nil, ErrUnknownPlayer
+		}
+		return nil, err
+	}
+
+	if participant.BannedAt != nil {
+		return nil, ErrBanned
+	}
+
+	return participant, nil
+}
+
+// Start moves a game from the lobby to its first question.
+func (e *Engine) Start(ctx context.Context, sessionID, hostID string) error {
+	session, quiz, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	if session.Phase != models.GamePhaseLobby {
+		return ErrWrongPhase
+	}
+
+	if err := e.startQuestion(ctx, session, quiz, 0); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionStart, nil)
+	return nil
+}
+
+// Next reveals the current question early or, once revealed, moves on to
+// the next question, finishing the game after the last one.
+func (e *Engine) Next(ctx context.Context, sessionID, hostID string) error {
+	session, quiz, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return err
+	}
+
+	from := session.Phase
+	switch session.Phase {
+	case models.GamePhaseQuestion:
+		err = e.reveal(ctx, session, quiz)
+	case models.GamePhaseReveal:
+		if session.QuestionIndex+1 >= len(quiz.Questions) {
+			err = e.finish(ctx, session)
+		} else {
+			err = e.startQuestion(ctx, session, quiz, session.QuestionIndex+1)
+		}
+	default:
+		err = ErrWrongPhase
+	}
+	if err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionNext, map[string]any{
+		"from":            from,
+		"to":              session.Phase,
+		"question_number": session.QuestionIndex + 1,
+	})
+	return nil
+}
+
+type AnswerResult struct {
+	Result questionkind.Result `json:"result"`
+	Points int                 `json:"points"`
+	Score  int                 `json:"score"`
+	Streak int                 `json:"streak"`
+}
+
+// Answer scores a player's answer to the current question. The reveal
+// comes to everyone together once the question is over.
+func (e *Engine) Answer(ctx context.Context, sessionID, participantID, questionID string, raw json.RawMessage) (*AnswerResult, error) {
+	session, quiz, err := e.load(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Phase != models.GamePhaseQuestion || session.PhaseStartedAt == nil || session.QuestionIndex >= len(quiz.Questions) {
+		return nil, ErrWrongPhase
+	}
+
+	if session.IsPaused() {
+		return nil, ErrPaused
+	}
+
+	question := &quiz.Questions[session.QuestionIndex]
+	if question.ID != questionID {
+		return nil, ErrWrongQuestion
+	}
+
+	now := time.Now()
+	if session.PhaseEndsAt != nil && now.After(session.PhaseEndsAt.Add(game.AnswerGracePeriod)) {
+		return nil, ErrTimeUp
+	}
+
+	kind, err := questionkind.ForQuestion(question)
+	if err != nil {
+		return nil, err
+	}
+
+	var submitted questionkind.Answer
+	if err := json.Unmarshal(raw, &submitted); err != nil || kind.CheckAnswer(question, &submitted) != nil {
+		return nil, ErrInvalidAnswer
+	}
+
+	participant, err := e.participantRepo.FindOne(ctx, &models.FindGameParticipantOptions{
+		ID:        participantID,
+		SessionID: session.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrGameParticipantNotFound) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, err
+	}
+
+	if participant.BannedAt != nil {
+		return nil, ErrBanned
+	}
+
+	// every player starts the question when it is shown to the room
+	answer := &models.GameAnswer{
+		ID:            utils.Uuid(),
+		SessionID:     session.ID,
+		ParticipantID: participant.ID,
+		QuestionID:    question.ID,
+		StartedAt:     *session.PhaseStartedAt,
+	}
+
+	if err := e.answerRepo.Start(ctx, answer); err != nil {
+		return nil, err
+	}
+
+	limit := game.TimeLimit(question.TimeLimitDuration)
+	result := kind.Score(question, &submitted)
+	points := game.Points(kind, result, now.Sub(answer.StartedAt), limit)
+
+	participant.Streak = game.Streak(kind, result, participant.Streak)
+	if kind.Scored() && result.Correct {
+		participant.CorrectCount++
+		points += game.StreakPoints(participant.Streak)
+	}
+
+	answer.Answer = raw
+	answer.Correct = result.Correct
+	answer.Credit = result.Credit
+	answer.Points = points
+	answer.AnsweredAt = utils.Ptr(now)
+	answer.UpdatedAt = now
+
+	participant.Score += points
+	participant.CurrentPosition = session.QuestionIndex + 1
+	participant.UpdatedAt = now
+	if participant.CurrentPosition >= len(quiz.Questions) {
+		participant.FinishedAt = utils.Ptr(now)
+	}
+
+	if err := e.answerRepo.Record(ctx, answer, participant); err != nil {
+		if errors.Is(err, database.ErrGameAnswerRecorded) {
+			return nil, ErrAlreadyAnswered
+		}
+		return nil, err
+	}
+	metrics.Answers.WithLabelValues(models.GameModeLive.String()).Inc()
+
+	e.notify(ctx, session.ID)
+	return &AnswerResult{
+		Result: result,
+		Points: points,
+		Score:  participant.Score,
+		Streak: participant.Streak,
+	}, nil
+}
+
+func (e *Engine) startQuestion(ctx context.Context, session *models.GameSession, quiz *models.Quiz, index int) error {
+	if index >= len(quiz.Questions) {
+		return e.finish(ctx, session)
+	}
+
+	from := *session
+
+	now := time.Now()
+	session.Phase = models.GamePhaseQuestion
+	session.QuestionIndex = index
+	session.PhaseStartedAt = utils.Ptr(now)
+	session.PhaseEndsAt = nil
+	session.PausedAt = nil
+	session.PauseReason = ""
+	session.UpdatedAt = now
+
+	if limit := game.TimeLimit(quiz.Questions[index].TimeLimitDuration); limit > 0 {
+		session.PhaseEndsAt = utils.Ptr(now.Add(limit))
+	}
+
+	return e.transition(ctx, session, &from)
+}
+
+// reveal closes the current question. Players who did not answer a scored
+// question lose their streak.
+func (e *Engine) reveal(ctx context.Context, session *models.GameSession, quiz *models.Quiz) error {
+	if session.QuestionIndex >= len(quiz.Questions) {
+		return e.finish(ctx, session)
+	}
+
+	from := *session
+
+	now := time.Now()
+	session.Phase = models.GamePhaseReveal
+	session.PhaseStartedAt = utils.Ptr(now)
+	session.PhaseEndsAt = nil
+	session.PausedAt = nil
+	session.PauseReason = ""
+	session.UpdatedAt = now
+
+	question := &quiz.Questions[session.QuestionIndex]
+	kind, err := questionkind.ForQuestion(question)
+	if err != nil {
+		return err
+	}
+
+	if kind.Scored() {
+		if err := e.participantRepo.ResetStreaks(ctx, session.ID, question.ID); err != nil {
+			return err
+		}
+	}
+
+	return e.transition(ctx, session, &from)
+}
+
+func (e *Engine) finish(ctx context.Context, session *models.GameSession) error {
+	from := *session
+
+	now := time.Now()
+	session.Phase = models.GamePhaseFinished
+	session.Status = models.GameSessionStatusClosed
+	session.PhaseStartedAt = utils.Ptr(now)
+	session.PhaseEndsAt = nil
+	session.PausedAt = nil
+	session.PauseReason = ""
+	session.UpdatedAt = now
+
+	if err := e.transition(ctx, session, &from); err != nil {
+		return err
+	}
+
+	// the game is over either way, a missing summary is saved again once
+	// the game's report is asked for
+	if _, err := e.reporter.Summarize(ctx, session); err != nil {
+		slog.Error("[live engine]: could not summarize game", slog.String("session_id", session.ID), slog.Any("error", err))
+	}
+	return nil
+}
+
+// transition saves the session's move away from where it was in from.
+func (e *Engine) transition(ctx context.Context, session *models.GameSession, from *models.GameSession) (err error) {
+	ctx, span := tracing.Start(ctx, "live.transition",
+		attribute.String("game.session_id", session.ID),
+		attribute.String("game.phase.from", from.Phase.String()),
+		attribute.String("game.phase.to", session.Phase.String()),
+		attribute.Int("game.question_index", session.QuestionIndex),
+		attribute.Bool("game.paused", session.IsPaused()),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := e.sessionRepo.Transition(ctx, session, from); err != nil {
+		if errors.Is(err, database.ErrGameSessionMoved) {
+			return ErrWrongPhase
+		}
+		return err
+	}
+
+	e.notify(ctx, session.ID)
+	return nil
+}
+
+// notify tells every node that the game changed. Nodes reload the state
+// themselves, which keeps messages small enough for any broker.
+func (e *Engine) notify(ctx context.Context, sessionID string) {
+	e.publish(ctx, sessionID, changedEvent)
+}
+
+func (e *Engine) publish(ctx context.Context, sessionID string, ev event) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		slog.Error("[live engine]: could not encode game change", slog.Any("error", err))
+		return
+	}
+
+	if err := e.broker.Publish(ctx, Topic(sessionID), payload); err != nil {
+		slog.Error("[live engine]: could not publish game change", slog.String("session_id", sessionID), slog.Any("error", err))
+	}
+}
+
+func (e *Engine) session(ctx context.Context, sessionID string) (*models.GameSession, error) {
+	session, err := e.sessionRepo.FindOne(ctx, &models.FindGameSessionOptions{ID: sessionID})
+	if err != nil {
+		if errors.Is(err, database.ErrGameSessionNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if session.Mode != models.GameModeLive {
+		return nil, ErrNotFound
+	}
+
+	return session, nil
+}
+
+// hosted loads a game for its host to control.
+func (e *Engine) hosted(ctx context.Context, sessionID, hostID string) (*models.GameSession, *models.Quiz, error) {
+	session, quiz, err := e.load(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if session.HostID != hostID {
+		return nil, nil, ErrNotHost
+	}
+
+	return session, quiz, nil
+}
+
+func (e *Engine) load(ctx context.Context, sessionID string) (*models.GameSession, *models.Quiz, error) {
+	session, err := e.session(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quiz, err := e.quizRepo.FindOne(ctx, &models.FindQuizOptions{ID: session.QuizID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return session, quiz, nil
+}
@@ -0,0 +1,351 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/metrics"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+)
+
+const (
+	clientReplyBuffer = 16
+	// stateDebounce gathers bursts of changes, such as a room full of
+	// players answering at once, into a single reload.
+	stateDebounce = 100 * time.Millisecond
+)
+
+// Message is sent to a connected client.
+type Message struct {
+	Type string `json:"type"`
+	Data any    `json:"data,omitempty"`
+}
+
+type stateMessage struct {
+	*State
+	Me *Me `json:"me,omitempty"`
+}
+
+// presenterMessage is what a presenter screen is sent, the state along with
+// how the room is answering. ServerTime lets the screen run its countdown
+// on the server's clock.
+type presenterMessage struct {
+	*State
+	Distribution []questionkind.Bucket `json:"distribution,omitempty"`
+	ServerTime   time.Time             `json:"server_time"`
+}
+
+// Client is a connection to a game on this node. Replies to the client's
+// own messages are queued in order, while game states replace each other
+// so a slow client only ever catches up on the latest one.
+type Client struct {
+	Replies <-chan Message
+	States  <-chan Message
+
+	replies chan Message
+	states  chan Message
+
+	mu            sync.Mutex
+	participantID string
+	hostID        string
+	presenter     bool
+}
+
+func NewClient() *Client {
+	replies := make(chan Message, clientReplyBuffer)
+	states := make(chan Message, 1)
+
+	return &Client{
+		Replies: replies,
+		States:  states,
+		replies: replies,
+		states:  states,
+	}
+}
+
+// Reply queues a message for the client, dropping it when the client has
+// stopped reading.
+func (c *Client) Reply(msg Message) {
+	select {
+	case c.replies <- msg:
+	default:
+		slog.Warn("[live hub]: client is not reading replies, dropping message", slog.String("type", msg.Type))
+	}
+}
+
+func (c *Client) SetParticipant(participantID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.participantID = participantID
+}
+
+func (c *Client) Participant() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.participantID
+}
+
+// SetHost lets the client control the game as the given user, once it is
+// known to be the game's host.
+func (c *Client) SetHost(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hostID = userID
+}
+
+// Host returns the user the client controls the game as, if any.
+func (c *Client) Host() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hostID
+}
+
+func (c *Client) IsHost() bool {
+	return c.Host() != ""
+}
+
+// SetPresenter makes the client a presenter screen, which watches the game
+// without taking part in it.
+func (c *Client) SetPresenter() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.presenter = true
+}
+
+func (c *Client) IsPresenter() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.presenter
+}
+
+func (c *Client) sendState(state *State) {
+	msg := Message{Type: "state", Data: stateMessage{State: state, Me: state.me(c.Participant())}}
+	if c.IsPresenter() {
+		msg = Message{Type: "presenter_state", Data: presenterMessage{
+			State:        state,
+			Distribution: state.distribution,
+			ServerTime:   time.Now(),
+		}}
+	}
+
+	for {
+		select {
+		case c.states <- msg:
+			return
+		default:
+		}
+
+		// replace the state the client has not picked up yet
+		select {
+		case <-c.states:
+		default:
+		}
+	}
+}
+
+// Hub keeps the clients connected to this node, grouped by game. Each game
+// with clients here follows the game's broker topic and pushes the new
+// state to its clients whenever any node changes the game.
+type Hub struct {
+	engine *Engine
+
+	mu    sync.Mutex
+	rooms map[string]*room
+}
+
+func NewHub(engine *Engine) *Hub {
+	return &Hub{
+		engine: engine,
+		rooms:  make(map[string]*room),
+	}
+}
+
+type room struct {
+	sessionID string
+	clients   map[*Client]struct{}
+	// pending are the clients waiting for the state of their own, refresh
+	// wakes the room up to send it. Both are guarded by the hub's mu.
+	pending map[*Client]struct{}
+	refresh chan struct{}
+	cancel  context.CancelFunc
+}
+
+// Register adds a client to a game and sends it the current state.
+func (h *Hub) Register(sessionID string, client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[sessionID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		r = &room{
+			sessionID: sessionID,
+			clients:   make(map[*Client]struct{}),
+			pending:   make(map[*Client]struct{}),
+			refresh:   make(chan struct{}, 1),
+			cancel:    cancel,
+		}
+		h.rooms[sessionID] = r
+
+		sub := h.engine.broker.Subscribe(Topic(sessionID))
+		go h.run(ctx, r, sub.C, sub.Close)
+	}
+
+	r.clients[client] = struct{}{}
+	metrics.ConnectedSockets.Inc()
+	r.requestRefresh(client)
+}
+
+func (h *Hub) Unregister(sessionID string, client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rooms[sessionID]
+	if !ok {
+		return
+	}
+
+	if _, ok := r.clients[client]; ok {
+		delete(r.clients, client)
+		delete(r.pending, client)
+		metrics.ConnectedSockets.Dec()
+	}
+
+	if len(r.clients) == 0 {
+		r.cancel()
+		delete(h.rooms, sessionID)
+	}
+}
+
+// Refresh sends the latest state to a single client, for example once it
+// joined and has a view of its own.
+func (h *Hub) Refresh(sessionID string, client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r, ok := h.rooms[sessionID]; ok {
+		r.requestRefresh(client)
+	}
+}
+
+// requestRefresh queues client for the state, the hub's mu must be held.
+// The room sends it to every queued client at once, so none is left out
+// however many ask while it is busy.
+func (r *room) requestRefresh(client *Client) {
+	r.pending[client] = struct{}{}
+
+	select {
+	case r.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (h *Hub) run(ctx context.Context, r *room, changes <-chan []byte, unsubscribe func()) {
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload, ok := <-changes:
+			if !ok {
+				return
+			}
+			h.handleEvent(r, payload)
+
+			// let a burst of changes settle before reloading
+			timer := time.NewTimer(stateDebounce)
+		settle:
+			for {
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case payload, ok := <-changes:
+					if !ok {
+						timer.Stop()
+						return
+					}
+					h.handleEvent(r, payload)
+				case <-timer.C:
+					break settle
+				}
+			}
+
+			state, err := h.engine.State(ctx, r.sessionID)
+			if err != nil {
+				slog.Error("[live hub]: could not load game state", slog.String("session_id", r.sessionID), slog.Any("error", err))
+				continue
+			}
+
+			for _, client := range h.clients(r) {
+				client.sendState(state)
+			}
+		case <-r.refresh:
+			clients := h.takePending(r)
+			if len(clients) == 0 {
+				continue
+			}
+
+			state, err := h.engine.State(ctx, r.sessionID)
+			if err != nil {
+				slog.Error("[live hub]: could not load game state", slog.String("session_id", r.sessionID), slog.Any("error", err))
+				continue
+			}
+
+			for _, client := range clients {
+				client.sendState(state)
+			}
+		}
+	}
+}
+
+// handleEvent acts on the events that concern single clients rather than
+// the whole game.
+func (h *Hub) handleEvent(r *room, payload []byte) {
+	var ev event
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		slog.Error("[live hub]: could not decode game change", slog.Any("error", err))
+		return
+	}
+
+	if ev.Type != "removed" {
+		return
+	}
+
+	// the connection stays open, the removed player watches on like a
+	// spectator
+	for _, client := range h.clients(r) {
+		if client.Participant() == ev.ParticipantID {
+			client.SetParticipant("")
+			client.Reply(Message{Type: "removed"})
+		}
+	}
+}
+
+// takePending returns the clients waiting for the state and clears them.
+func (h *Hub) takePending(r *room) []*Client {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := make([]*Client, 0, len(r.pending))
+	for client := range r.pending {
+		clients = append(clients, client)
+	}
+	clear(r.pending)
+	return clients
+}
+
+func (h *Hub) clients(r *room) []*Client {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := make([]*Client, 0, len(r.clients))
+	for client := range r.clients {
+		clients = append(clients, client)
+	}
+	return clients
+}
@@ -0,0 +1,120 @@
+package live
+
+import (
+	"context"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+// HostHeartbeatInterval is how often a node tells the others that the host
+// is still connected to it.
+const HostHeartbeatInterval = 10 * time.Second
+
+// HostSeen records that the host is connected, resuming the game if it was
+// paused while the host was away. Nodes call it while the host stays
+// connected and once more when the host disconnects.
+func (e *Engine) HostSeen(ctx context.Context, sessionID string) error {
+	if err := e.sessionRepo.TouchHost(ctx, sessionID, time.Now()); err != nil {
+		return err
+	}
+
+	session, err := e.session(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.PauseReason != models.GamePauseReasonHostAway || !session.IsLive() {
+		return nil
+	}
+
+	if err := e.resume(ctx, session); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, nil, models.GameAuditActionUnpause, map[string]any{
+		"question_number": session.QuestionIndex + 1,
+		"reason":          models.GamePauseReasonHostAway,
+	})
+	return nil
+}
+
+// hostAwayAt returns when the game pauses unless the host is seen again.
+// Games in the lobby wait for the host anyway and never pause.
+func (e *Engine) hostAwayAt(session *models.GameSession) (time.Time, bool) {
+	if session.HostSeenAt == nil || session.IsPaused() {
+		return time.Time{}, false
+	}
+
+	if session.Phase != models.GamePhaseQuestion && session.Phase != models.GamePhaseReveal {
+		return time.Time{}, false
+	}
+
+	return session.HostSeenAt.Add(e.hostGracePeriod), true
+}
+
+// pauseIfHostAway pauses the game once the host has been gone for longer
+// than the grace period. The session is loaded again as the host is seen
+// without the game changing.
+func (e *Engine) pauseIfHostAway(ctx context.Context, sessionID string) error {
+	session, err := e.session(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	awayAt, ok := e.hostAwayAt(session)
+	if !ok || time.Now().Before(awayAt) {
+		return nil
+	}
+
+	if err := e.pause(ctx, session, models.GamePauseReasonHostAway); err != nil {
+		return err
+	}
+
+	e.audit(ctx, session.ID, nil, models.GameAuditActionPause, map[string]any{
+		"question_number": session.QuestionIndex + 1,
+		"reason":          models.GamePauseReasonHostAway,
+	})
+	return nil
+}
+
+// pause stops the clock of the current question.
+func (e *Engine) pause(ctx context.Context, session *models.GameSession, reason models.GamePauseReason) error {
+	if session.IsPaused() {
+		return nil
+	}
+
+	from := *session
+
+	now := time.Now()
+	session.PausedAt = utils.Ptr(now)
+	session.PauseReason = reason
+	session.UpdatedAt = now
+
+	return e.transition(ctx, session, &from)
+}
+
+// resume restarts the clock, giving the question back the time that was
+// left when the game paused.
+func (e *Engine) resume(ctx context.Context, session *models.GameSession) error {
+	if !session.IsPaused() {
+		return nil
+	}
+
+	from := *session
+
+	now := time.Now()
+	paused := now.Sub(*session.PausedAt)
+	if session.PhaseStartedAt != nil {
+		session.PhaseStartedAt = utils.Ptr(session.PhaseStartedAt.Add(paused))
+	}
+	if session.PhaseEndsAt != nil {
+		session.PhaseEndsAt = utils.Ptr(session.PhaseEndsAt.Add(paused))
+	}
+	session.PausedAt = nil
+	session.PauseReason = ""
+	session.UpdatedAt = now
+
+	return e.transition(ctx, session, &from)
+}
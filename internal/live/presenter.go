@@ -0,0 +1,37 @@
+package live
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+// NewPresenterToken makes a token for a screen to present the game with,
+// such as a TV in the room, without handing it the host's own credentials.
+// Making a new token stops the previous one from working.
+func (e *Engine) NewPresenterToken(ctx context.Context, sessionID, hostID string) (string, error) {
+	session, _, err := e.hosted(ctx, sessionID, hostID)
+	if err != nil {
+		return "", err
+	}
+
+	token, tokenHash := game.NewPresenterToken()
+	if err := e.sessionRepo.SetPresenterToken(ctx, session.ID, tokenHash); err != nil {
+		return "", err
+	}
+
+	e.audit(ctx, session.ID, &hostID, models.GameAuditActionPresenterLink, nil)
+	return token, nil
+}
+
+// IsPresenterToken reports whether token is the game's current presenter
+// token.
+func IsPresenterToken(session *models.GameSession, token string) bool {
+	if session.PresenterTokenHash == "" || token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(game.HashToken(token)), []byte(session.PresenterTokenHash)) == 1
+}
@@ -0,0 +1,207 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+)
+
+// event is published on a game's topic whenever the game changes.
+type event struct {
+	Type string `json:"type"`
+	// ParticipantID is the player removed from the game by a "removed"
+	// event.
+	ParticipantID string `json:"participant_id,omitempty"`
+}
+
+var changedEvent = event{Type: "changed"}
+
+// Topic is the broker topic a game's changes are published on.
+func Topic(sessionID string) string {
+	return "game:" + sessionID
+}
+
+type PlayerSummary struct {
+	ParticipantID string  `json:"participant_id"`
+	Nickname      string  `json:"nickname"`
+	TeamID        *string `json:"team_id"`
+}
+
+// State is what everyone in a game sees, built from the database after
+// every change.
+type State struct {
+	Code            string                        `json:"code"`
+	Phase           models.GamePhase              `json:"phase"`
+	QuestionNumber  int                           `json:"question_number"`
+	TotalQuestions  int                           `json:"total_questions"`
+	Question        *questionkind.Prompt          `json:"question,omitempty"`
+	StartedAt       *time.Time                    `json:"started_at,omitempty"`
+	EndsAt          *time.Time                    `json:"ends_at,omitempty"`
+	PausedAt        *time.Time                    `json:"paused_at,omitempty"`
+	PauseReason     models.GamePauseReason        `json:"pause_reason,omitempty"`
+	Reveal          any                           `json:"reveal,omitempty"`
+	AnsweredCount   int                           `json:"answered_count"`
+	Players         []PlayerSummary               `json:"players"`
+	Teams           []models.GameTeam             `json:"teams,omitempty"`
+	Leaderboard     []models.LeaderboardEntry     `json:"leaderboard,omitempty"`
+	TeamLeaderboard []models.TeamLeaderboardEntry `json:"team_leaderboard,omitempty"`
+
+	participants map[string]models.GameParticipant
+	answers      map[string]models.GameAnswer
+	answerable   bool
+	distribution []questionkind.Bucket
+}
+
+// Me is a player's own view of the game, sent along with the State.
+type Me struct {
+	ParticipantID string  `json:"participant_id"`
+	Nickname      string  `json:"nickname"`
+	TeamID        *string `json:"team_id"`
+	Score         int     `json:"score"`
+	Streak        int     `json:"streak"`
+	CorrectCount  int     `json:"correct_count"`
+	Rank          int     `json:"rank"`
+	// Answered reports whether the player answered the current question,
+	// and Answer is how it went, for players that missed the result.
+	Answered bool          `json:"answered"`
+	Answer   *AnswerResult `json:"answer,omitempty"`
+	// Answerable reports whether the player can still answer the current
+	// question.
+	Answerable bool `json:"answerable"`
+}
+
+// me returns the player's view, or nil for spectators and players that
+// are no longer in the game.
+func (s *State) me(participantID string) *Me {
+	participant, ok := s.participants[participantID]
+	if !ok {
+		return nil
+	}
+
+	me := &Me{
+		ParticipantID: participant.ID,
+		Nickname:      participant.Nickname,
+		TeamID:        participant.TeamID,
+		Score:         participant.Score,
+		Streak:        participant.Streak,
+		CorrectCount:  participant.CorrectCount,
+	}
+
+	if answer, ok := s.answers[participant.ID]; ok {
+		me.Answered = true
+		me.Answer = &AnswerResult{
+			Result: questionkind.Result{Correct: answer.Correct, Credit: answer.Credit},
+			Points: answer.Points,
+			Score:  participant.Score,
+			Streak: participant.Streak,
+		}
+	}
+	me.Answerable = s.answerable && !me.Answered
+
+	for _, entry := range s.Leaderboard {
+		if entry.ParticipantID == participant.ID {
+			me.Rank = entry.Rank
+			break
+		}
+	}
+
+	return me
+}
+
+// State loads the current state of a game.
+func (e *Engine) State(ctx context.Context, sessionID string) (*State, error) {
+	session, quiz, err := e.load(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	participants, err := e.participantRepo.FindAll(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{
+		Code:           session.Code,
+		Phase:          session.Phase,
+		PausedAt:       session.PausedAt,
+		PauseReason:    session.PauseReason,
+		TotalQuestions: len(quiz.Questions),
+		Players:        make([]PlayerSummary, 0, len(participants)),
+		Teams:          session.Teams,
+		participants:   make(map[string]models.GameParticipant, len(participants)),
+		answers:        make(map[string]models.GameAnswer),
+	}
+
+	for _, participant := range participants {
+		state.participants[participant.ID] = participant
+		state.Players = append(state.Players, PlayerSummary{
+			ParticipantID: participant.ID,
+			Nickname:      participant.Nickname,
+			TeamID:        participant.TeamID,
+		})
+	}
+
+	inQuestion := session.Phase == models.GamePhaseQuestion || session.Phase == models.GamePhaseReveal
+	if inQuestion && session.QuestionIndex < len(quiz.Questions) {
+		question := &quiz.Questions[session.QuestionIndex]
+		kind, err := questionkind.ForQuestion(question)
+		if err != nil {
+			return nil, err
+		}
+
+		state.QuestionNumber = session.QuestionIndex + 1
+		state.Question = kind.Prompt(question)
+
+		answers, err := e.answerRepo.FindAll(ctx, &models.FindGameAnswerOptions{
+			SessionID:  session.ID,
+			QuestionID: question.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		submitted := make([]questionkind.Answer, 0, len(answers))
+		for _, answer := range answers {
+			if answer.AnsweredAt == nil || answer.TimedOut {
+				continue
+			}
+
+			state.answers[answer.ParticipantID] = answer
+			state.AnsweredCount++
+
+			var a questionkind.Answer
+			if err := json.Unmarshal(answer.Answer, &a); err == nil {
+				submitted = append(submitted, a)
+			}
+		}
+		state.distribution = questionkind.Distribution(question, submitted)
+
+		if session.Phase == models.GamePhaseQuestion {
+			state.StartedAt = session.PhaseStartedAt
+			state.EndsAt = session.PhaseEndsAt
+			state.answerable = !session.IsPaused() &&
+				(session.PhaseEndsAt == nil || time.Now().Before(session.PhaseEndsAt.Add(game.AnswerGracePeriod)))
+		} else {
+			state.Reveal = kind.Reveal(question)
+		}
+	}
+
+	// scores stay hidden while a question is open so the room cannot tell
+	// who answered correctly
+	if session.Phase == models.GamePhaseReveal || session.Phase == models.GamePhaseFinished {
+		state.Leaderboard, err = e.participantRepo.Leaderboard(ctx, session.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if session.HasTeams() {
+			state.TeamLeaderboard = game.TeamLeaderboard(session.Teams, state.Leaderboard, session.TeamScoring)
+		}
+	}
+
+	return state, nil
+}
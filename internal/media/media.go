@@ -0,0 +1,186 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"slices"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+const (
+	ThumbnailSize = 320
+
+	// maxPixels guards against decompression bombs, images that are small
+	// on the wire but expand to gigabytes once decoded.
+	maxPixels   = 40_000_000
+	jpegQuality = 90
+)
+
+var (
+	ErrUnsupportedType = errors.New("media: unsupported file type")
+	ErrTooLarge        = errors.New("media: file is too large")
+	ErrInvalidImage    = errors.New("media: invalid image")
+)
+
+type Limits struct {
+	MaxImageSize int64
+	MaxAudioSize int64
+}
+
+type Processed struct {
+	Kind        models.UploadKind
+	ContentType string
+	Extension   string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// Thumb is a scaled down copy of an image.
+type Thumb struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+var (
+	imageTypes = map[string]string{
+		"image/jpeg": "jpg",
+		"image/png":  "png",
+		"image/gif":  "gif",
+	}
+
+	audioTypes = map[string]string{
+		"audio/mpeg": "mp3",
+		"audio/wave": "wav",
+		"audio/ogg":  "ogg",
+		"audio/aiff": "aiff",
+	}
+)
+
+// DetectContentType sniffs the content type of data, falling back to a
+// check for raw MPEG audio frames which carry no ID3 header.
+func DetectContentType(data []byte) string {
+	contentType := http.DetectContentType(data)
+	switch {
+	case contentType == "application/ogg":
+		return "audio/ogg"
+	case contentType == "application/octet-stream" && len(data) > 1 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return "audio/mpeg"
+	}
+	return contentType
+}
+
+// Process validates data against the allowed kinds and size limits. Images
+// are re-encoded, which drops EXIF and other metadata after the EXIF
+// orientation has been applied to the pixels. Thumbnails are made later,
+// see Thumbnail.
+func Process(data []byte, allowed []models.UploadKind, limits Limits) (*Processed, error) {
+	contentType := DetectContentType(data)
+
+	if ext, ok := imageTypes[contentType]; ok && slices.Contains(allowed, models.UploadKindImage) {
+		if int64(len(data)) > limits.MaxImageSize {
+			return nil, ErrTooLarge
+		}
+		return processImage(data, contentType, ext)
+	}
+
+	if ext, ok := audioTypes[contentType]; ok && slices.Contains(allowed, models.UploadKindAudio) {
+		if int64(len(data)) > limits.MaxAudioSize {
+			return nil, ErrTooLarge
+		}
+		return &Processed{
+			Kind:        models.UploadKindAudio,
+			ContentType: contentType,
+			Extension:   ext,
+			Data:        data,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+}
+
+func processImage(data []byte, contentType, ext string) (*Processed, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	processed := &Processed{
+		Kind:        models.UploadKindImage,
+		ContentType: contentType,
+		Extension:   ext,
+	}
+
+	var buf bytes.Buffer
+	switch contentType {
+	case "image/jpeg":
+		img = orient(img, jpegOrientation(data))
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	case "image/gif":
+		// gifs carry no exif, re-encoding would only lose the animation
+		_, err = buf.Write(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	processed.Data = buf.Bytes()
+	processed.Width = img.Bounds().Dx()
+	processed.Height = img.Bounds().Dy()
+
+	return processed, nil
+}
+
+// Thumbnail scales down an image that went through Process. Jpegs stay
+// jpegs, other images become pngs.
+func Thumbnail(data []byte) (*Thumb, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	thumb := &Thumb{ContentType: "image/png", Extension: "png"}
+	scaled := thumbnail(img, ThumbnailSize)
+
+	var buf bytes.Buffer
+	if DetectContentType(data) == "image/jpeg" {
+		thumb.ContentType, thumb.Extension = "image/jpeg", "jpg"
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	thumb.Data = buf.Bytes()
+	return thumb, nil
+}
+
+func decodeImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	return img, nil
+}
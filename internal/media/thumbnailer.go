@@ -0,0 +1,86 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/storage"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+// ThumbnailJob makes the thumbnail of an uploaded image, keeping the
+// scaling out of the upload request.
+var ThumbnailJob = jobs.Kind[ThumbnailArgs]{Name: "media.thumbnail", MaxAttempts: 5, Timeout: time.Minute}
+
+type ThumbnailArgs struct {
+	UploadID string `json:"upload_id"`
+}
+
+type Thumbnailer struct {
+	blobStore  storage.BlobStore
+	uploadRepo models.UploadRepository
+}
+
+func NewThumbnailer(blobStore storage.BlobStore, uploadRepo models.UploadRepository) *Thumbnailer {
+	return &Thumbnailer{
+		blobStore:  blobStore,
+		uploadRepo: uploadRepo,
+	}
+}
+
+// HandleThumbnail runs ThumbnailJob. Uploads that are gone, are not
+// images or already have a thumbnail are left alone.
+func (t *Thumbnailer) HandleThumbnail(ctx context.Context, args ThumbnailArgs) error {
+	upload, err := t.uploadRepo.FindOne(ctx, &models.FindUploadOptions{ID: args.UploadID})
+	if err != nil {
+		if errors.Is(err, database.ErrUploadNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if upload.Kind != models.UploadKindImage || upload.ThumbnailKey != nil {
+		return nil
+	}
+
+	blob, err := t.blobStore.Get(ctx, upload.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil
+		}
+		return err
+	}
+	defer blob.Close()
+
+	data, err := io.ReadAll(blob)
+	if err != nil {
+		return err
+	}
+
+	thumb, err := Thumbnail(data)
+	if err != nil {
+		if errors.Is(err, ErrInvalidImage) || errors.Is(err, ErrTooLarge) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	key := strings.TrimSuffix(upload.Key, path.Ext(upload.Key)) + "_thumb." + thumb.Extension
+	if err := t.blobStore.Put(ctx, key, bytes.NewReader(thumb.Data), int64(len(thumb.Data)), thumb.ContentType); err != nil {
+		return err
+	}
+
+	upload.ThumbnailKey = utils.Ptr(key)
+	upload.ThumbnailURL = utils.Ptr(t.blobStore.URL(key))
+	upload.UpdatedAt = time.Now()
+
+	return t.uploadRepo.Update(ctx, upload)
+}
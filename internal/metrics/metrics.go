@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "sabipass"
+
+// OAuth login outcomes.
+const (
+	OAuthSuccess      = "success"
+	OAuthInvalidState = "invalid_state"
+	OAuthDenied       = "denied"
+	OAuthSuspended    = "suspended"
+	OAuthError        = "error"
+)
+
+// Registry holds every sabipass metric, along with the Go runtime and
+// process metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "How long HTTP requests took, by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "How long database queries took, by the repository method running them.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"method", "operation", "error"})
+
+	LiveGames = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "live",
+		Name:      "games",
+		Help:      "Live games driven by this instance, summed over instances it is every live game.",
+	})
+
+	ConnectedSockets = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "live",
+		Name:      "connected_sockets",
+		Help:      "Game sockets connected to this instance.",
+	})
+
+	Answers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "game",
+		Name:      "answers_total",
+		Help:      "Answers recorded, by game mode. Its rate is the answers per second.",
+	}, []string{"mode"})
+
+	OAuthLogins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "oauth",
+		Name:      "logins_total",
+		Help:      "OAuth sign ins, by provider and outcome.",
+	}, []string{"provider", "outcome"})
+
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "rejected_total",
+		Help:      "Requests and socket messages turned away for going over a limit, by policy.",
+	}, []string{"policy"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		QueryDuration,
+		LiveGames,
+		ConnectedSockets,
+		Answers,
+		OAuthLogins,
+		RateLimited,
+	)
+}
+
+// RegisterDB exports the connection pool stats of db.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ENUM(promote, demote, suspend, unsuspend, ban, warn, revoke_sessions, hide_quiz, unhide_quiz, hide_nickname, transfer_quiz)
+type AdminAction string
+
+// AdminAuditLog records an action taken on a user or a quiz by support,
+// outside of what users can do themselves.
+type AdminAuditLog struct {
+	ID string `bun:"type:uuid,pk" json:"id"`
+	// Actor names who acted, such as the operator running an admin
+	// command.
+	Actor     string          `json:"actor"`
+	Action    AdminAction     `json:"action"`
+	TargetID  string          `bun:"type:uuid,notnull" json:"target_id"`
+	Details   json.RawMessage `bun:"type:jsonb,nullzero" json:"details,omitempty"`
+	CreatedAt time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	bun.BaseModel `bun:"table:admin_audit_logs" json:"-"`
+}
+
+type AdminAuditLogRepository interface {
+	Create(context.Context, *AdminAuditLog) error
+	// UpdateUser, UpdateQuiz and UpdateParticipant save the change made
+	// to the target along with its audit log entry, in one transaction so
+	// neither is kept without the other.
+	UpdateUser(context.Context, *User, *AdminAuditLog) error
+	UpdateQuiz(context.Context, *Quiz, *AdminAuditLog) error
+	UpdateParticipant(context.Context, *GameParticipant, *AdminAuditLog) error
+	// FindAll returns the audit log of a user or quiz, oldest first.
+	FindAll(ctx context.Context, targetID string) ([]AdminAuditLog, error)
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// QuestionStat accumulates the answers to a question across every session
+// played from its quiz.
+type QuestionStat struct {
+	QuestionID   string `bun:"type:uuid,pk" json:"question_id"`
+	QuizID       string `bun:"type:uuid,notnull" json:"quiz_id"`
+	AnswerCount  int    `json:"answer_count"`
+	CorrectCount int    `json:"correct_count"`
+	// The upper and lower groups are the best and worst scoring players of
+	// each session, the difference between how often they get a question
+	// right is the question's discrimination index.
+	UpperCount   int       `json:"upper_count"`
+	UpperCorrect int       `json:"upper_correct"`
+	LowerCount   int       `json:"lower_count"`
+	LowerCorrect int       `json:"lower_correct"`
+	UpdatedAt    time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:question_stats" json:"-"`
+}
+
+type QuestionOptionStat struct {
+	OptionID   string    `bun:"type:uuid,pk" json:"option_id"`
+	QuestionID string    `bun:"type:uuid,notnull" json:"question_id"`
+	PickCount  int       `json:"pick_count"`
+	UpdatedAt  time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:question_option_stats" json:"-"`
+}
+
+// QuestionTimeBucket counts the answers to a question given within the
+// same second, a histogram that medians are read from.
+type QuestionTimeBucket struct {
+	QuestionID string `bun:"type:uuid,pk" json:"question_id"`
+	Seconds    int    `bun:",pk" json:"seconds"`
+	Count      int    `json:"count"`
+
+	bun.BaseModel `bun:"table:question_time_buckets" json:"-"`
+}
+
+// QuizRollup is what one finished session adds to its quiz's statistics.
+type QuizRollup struct {
+	SessionID   string
+	QuizID      string
+	Questions   []QuestionStat
+	Options     []QuestionOptionStat
+	TimeBuckets []QuestionTimeBucket
+}
+
+// QuizStats is everything rolled up for a quiz.
+type QuizStats struct {
+	SessionCount int
+	Questions    []QuestionStat
+	Options      []QuestionOptionStat
+	TimeBuckets  []QuestionTimeBucket
+}
+
+type AnalyticsRepository interface {
+	// FindUnrolled returns finished sessions that are not rolled up yet,
+	// oldest first.
+	FindUnrolled(ctx context.Context, limit int) ([]GameSession, error)
+	// Rollup adds a session's figures to its quiz's statistics, returning
+	// false when the session was already rolled up.
+	Rollup(context.Context, *QuizRollup) (bool, error)
+	FindQuizStats(ctx context.Context, quizID string) (*QuizStats, error)
+}
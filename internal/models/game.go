@@ -0,0 +1,303 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ENUM(challenge, live)
+type GameMode string
+
+// ENUM(lobby, question, reveal, finished)
+type GamePhase string
+
+// ENUM(start, next, pause, unpause, skip, extend, kick, ban, lock, unlock, end, presenter_link, assign_team, balance_teams)
+type GameAuditAction string
+
+// ENUM(host, host_away)
+type GamePauseReason string
+
+// ENUM(open, closed)
+type GameSessionStatus string
+
+// ENUM(manual, auto, player_choice)
+type TeamAssignment string
+
+// ENUM(sum, average, best)
+type TeamScoring string
+
+type GameSession struct {
+	ID                 string            `bun:"type:uuid,pk" json:"id"`
+	QuizID             string            `bun:"type:uuid,notnull" json:"quiz_id"`
+	HostID             string            `bun:"type:uuid,notnull" json:"host_id"`
+	Mode               GameMode          `json:"mode"`
+	Code               string            `json:"code"`
+	Status             GameSessionStatus `json:"status"`
+	ClosesAt           *time.Time        `bun:",nullzero" json:"closes_at"`
+	Phase              GamePhase         `bun:",nullzero" json:"phase,omitempty"`
+	QuestionIndex      int               `json:"question_index"`
+	PhaseStartedAt     *time.Time        `bun:",nullzero" json:"phase_started_at,omitempty"`
+	PhaseEndsAt        *time.Time        `bun:",nullzero" json:"phase_ends_at,omitempty"`
+	PausedAt           *time.Time        `bun:",nullzero" json:"paused_at,omitempty"`
+	PauseReason        GamePauseReason   `bun:",nullzero" json:"pause_reason,omitempty"`
+	HostSeenAt         *time.Time        `bun:",nullzero" json:"-"`
+	LockedAt           *time.Time        `bun:",nullzero" json:"locked_at,omitempty"`
+	PresenterTokenHash string            `bun:",nullzero" json:"-"`
+	TeamAssignment     TeamAssignment    `bun:",nullzero" json:"team_assignment,omitempty"`
+	TeamScoring        TeamScoring       `bun:",nullzero" json:"team_scoring,omitempty"`
+	CreatedAt          time.Time         `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt          time.Time         `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	Quiz    *Quiz        `bun:"rel:belongs-to,join:quiz_id=id" json:"-"`
+	Teams   []GameTeam   `bun:"rel:has-many,join:id=session_id" json:"teams,omitempty"`
+	Summary *GameSummary `bun:"rel:has-one,join:id=session_id" json:"summary,omitempty"`
+
+	bun.BaseModel `bun:"table:game_sessions" json:"-"`
+}
+
+func (g *GameSession) IsOpen(now time.Time) bool {
+	if g.Status != GameSessionStatusOpen {
+		return false
+	}
+	return g.ClosesAt == nil || now.Before(*g.ClosesAt)
+}
+
+// IsLive reports whether the session is a live game that has not
+// finished yet.
+func (g *GameSession) IsLive() bool {
+	return g.Mode == GameModeLive && g.Phase != GamePhaseFinished
+}
+
+// HasTeams reports whether the session is played in teams, individual
+// sessions leave TeamAssignment and TeamScoring empty.
+func (g *GameSession) HasTeams() bool {
+	return g.TeamAssignment != ""
+}
+
+func (g *GameSession) IsPaused() bool {
+	return g.PausedAt != nil
+}
+
+func (g *GameSession) IsLocked() bool {
+	return g.LockedAt != nil
+}
+
+type GameTeam struct {
+	ID        string    `bun:"type:uuid,pk" json:"id"`
+	SessionID string    `bun:"type:uuid,notnull" json:"session_id"`
+	Name      string    `json:"name"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	bun.BaseModel `bun:"table:game_teams" json:"-"`
+}
+
+type GameParticipant struct {
+	ID              string     `bun:"type:uuid,pk" json:"id"`
+	SessionID       string     `bun:"type:uuid,notnull" json:"session_id"`
+	UserID          *string    `bun:"type:uuid,nullzero" json:"user_id"`
+	TeamID          *string    `bun:"type:uuid,nullzero" json:"team_id"`
+	Nickname        string     `json:"nickname"`
+	TokenHash       string     `json:"-"`
+	Score           int        `json:"score"`
+	CorrectCount    int        `json:"correct_count"`
+	Streak          int        `json:"streak"`
+	CurrentPosition int        `json:"current_position"`
+	FinishedAt      *time.Time `bun:",nullzero" json:"finished_at"`
+	BannedAt        *time.Time `bun:",nullzero" json:"banned_at,omitempty"`
+	CreatedAt       time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt       time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:game_participants" json:"-"`
+}
+
+// GameSummary is saved once a session is over, so finished games can be
+// listed without going through their answers.
+type GameSummary struct {
+	SessionID      string    `bun:"type:uuid,pk" json:"session_id"`
+	QuizTitle      string    `json:"quiz_title"`
+	PlayerCount    int       `json:"player_count"`
+	QuestionCount  int       `json:"question_count"`
+	AnswerCount    int       `json:"answer_count"`
+	CorrectRate    float64   `json:"correct_rate"`
+	AverageScore   float64   `json:"average_score"`
+	WinnerID       *string   `bun:"type:uuid,nullzero" json:"winner_id"`
+	WinnerNickname string    `json:"winner_nickname"`
+	WinnerScore    int       `json:"winner_score"`
+	FinishedAt     time.Time `json:"finished_at"`
+	CreatedAt      time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt      time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:game_summaries" json:"-"`
+}
+
+type GameAuditLog struct {
+	ID        string `bun:"type:uuid,pk" json:"id"`
+	SessionID string `bun:"type:uuid,notnull" json:"session_id"`
+	// ActorID is the user that acted, it is nil when the game acted on its
+	// own, such as pausing while the host is away.
+	ActorID   *string         `bun:"type:uuid,nullzero" json:"actor_id"`
+	Action    GameAuditAction `json:"action"`
+	Details   json.RawMessage `bun:"type:jsonb,nullzero" json:"details,omitempty"`
+	CreatedAt time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+
+	bun.BaseModel `bun:"table:game_audit_logs" json:"-"`
+}
+
+type GameAnswer struct {
+	ID            string          `bun:"type:uuid,pk" json:"id"`
+	SessionID     string          `bun:"type:uuid,notnull" json:"session_id"`
+	ParticipantID string          `bun:"type:uuid,notnull" json:"participant_id"`
+	QuestionID    string          `bun:"type:uuid,notnull" json:"question_id"`
+	Answer        json.RawMessage `bun:"type:jsonb,nullzero" json:"answer"`
+	Correct       bool            `json:"correct"`
+	Credit        float64         `json:"credit"`
+	Points        int             `json:"points"`
+	TimedOut      bool            `json:"timed_out"`
+	StartedAt     time.Time       `json:"started_at"`
+	AnsweredAt    *time.Time      `bun:",nullzero" json:"answered_at"`
+	CreatedAt     time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt     time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:game_answers" json:"-"`
+}
+
+type LeaderboardEntry struct {
+	Rank          int        `json:"rank"`
+	ParticipantID string     `json:"participant_id"`
+	TeamID        *string    `json:"team_id"`
+	Nickname      string     `json:"nickname"`
+	Score         int        `json:"score"`
+	CorrectCount  int        `json:"correct_count"`
+	AnsweredCount int        `json:"answered_count"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+type TeamLeaderboardEntry struct {
+	Rank        int    `json:"rank"`
+	TeamID      string `json:"team_id"`
+	Name        string `json:"name"`
+	Score       int    `json:"score"`
+	MemberCount int    `json:"member_count"`
+}
+
+type FindGameSessionOptions struct {
+	ID   string
+	Code string
+}
+
+type ListGameSessionOptions struct {
+	Paginator Paginator
+	// UserID lists the sessions the user hosted or played in.
+	UserID string
+}
+
+type GameSessionRepository interface {
+	// Create saves the session together with its teams.
+	Create(context.Context, *GameSession) error
+	Update(context.Context, *GameSession) error
+	FindOne(context.Context, *FindGameSessionOptions) (*GameSession, error)
+	// FindAll returns sessions newest first, with their quiz and summary.
+	FindAll(context.Context, *ListGameSessionOptions) ([]GameSession, int64, error)
+	// FindLive returns the live sessions that have not finished.
+	FindLive(context.Context) ([]GameSession, error)
+	// FindAbandoned returns the live sessions that have not finished and
+	// have neither seen their host nor changed since before.
+	FindAbandoned(ctx context.Context, before time.Time) ([]GameSession, error)
+	// Transition saves the session's phase only if it is still where it was
+	// in from, at the same phase, question, deadline, pause and lock, so
+	// concurrent transitions cannot both apply.
+	Transition(ctx context.Context, session *GameSession, from *GameSession) error
+	// TouchHost records that the host of a live session is connected.
+	TouchHost(ctx context.Context, sessionID string, seenAt time.Time) error
+	// SetPresenterToken replaces the hash of the session's presenter token,
+	// so only the latest presenter link works.
+	SetPresenterToken(ctx context.Context, sessionID, tokenHash string) error
+}
+
+type FindGameParticipantOptions struct {
+	ID        string
+	SessionID string
+	UserID    string
+	TokenHash string
+}
+
+type GameParticipantRepository interface {
+	Create(context.Context, *GameParticipant) error
+	Update(context.Context, *GameParticipant) error
+	FindOne(context.Context, *FindGameParticipantOptions) (*GameParticipant, error)
+	FindAll(ctx context.Context, sessionID string) ([]GameParticipant, error)
+	// AssignTeams saves the team of every given participant at once.
+	AssignTeams(context.Context, []GameParticipant) error
+	// Delete removes the participant with their answers.
+	Delete(context.Context, *GameParticipant) error
+	// Ban keeps the participant out of the session, FindAll and
+	// Leaderboard leave banned participants out.
+	Ban(context.Context, *GameParticipant) error
+	// ResetStreaks ends the streak of every participant in the session
+	// that did not answer the question.
+	ResetStreaks(ctx context.Context, sessionID, questionID string) error
+	Leaderboard(ctx context.Context, sessionID string) ([]LeaderboardEntry, error)
+}
+
+type FindGameAnswerOptions struct {
+	SessionID     string
+	ParticipantID string
+	QuestionID    string
+}
+
+type GameAnswerRepository interface {
+	// Start records that a participant was shown a question. Starting a
+	// question twice keeps the original start time.
+	Start(context.Context, *GameAnswer) error
+	FindOne(context.Context, *FindGameAnswerOptions) (*GameAnswer, error)
+	FindAll(context.Context, *FindGameAnswerOptions) ([]GameAnswer, error)
+	// Record saves a started answer together with the participant's new
+	// score and progress.
+	Record(context.Context, *GameAnswer, *GameParticipant) error
+}
+
+type GameSummaryRepository interface {
+	// Save creates the session's summary or replaces it.
+	Save(context.Context, *GameSummary) error
+}
+
+type GameAuditLogRepository interface {
+	Create(context.Context, *GameAuditLog) error
+	// FindAll returns the session's audit log, oldest first.
+	FindAll(ctx context.Context, sessionID string) ([]GameAuditLog, error)
+}
+
+type TeamOptions struct {
+	// Teams names the teams to play in, leave it empty to play
+	// individually.
+	Teams          []string       `json:"teams" valid:"-"`
+	TeamAssignment TeamAssignment `json:"team_assignment" valid:"-"`
+	TeamScoring    TeamScoring    `json:"team_scoring" valid:"-"`
+}
+
+type CreateChallengeRequest struct {
+	ClosesAt time.Time `json:"closes_at" valid:"-"`
+	TeamOptions
+}
+
+type CreateLiveGameRequest struct {
+	TeamOptions
+}
+
+type JoinGameRequest struct {
+	Nickname string `json:"nickname" valid:"required~The nickname field is required,maxstringlength(30)~The nickname may not be longer than 30 characters"`
+	TeamID   string `json:"team_id" valid:"uuid~The team id must be a valid uuid,optional"`
+}
+
+type AssignTeamRequest struct {
+	TeamID string `json:"team_id" valid:"required~The team id field is required,uuid~The team id must be a valid uuid"`
+}
+
+type SubmitAnswerRequest struct {
+	QuestionID string          `json:"question_id" valid:"required~The question id field is required"`
+	Answer     json.RawMessage `json:"answer" valid:"-"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ENUM(pending, running, dead)
+type JobStatus string
+
+// Job is a unit of background work. Jobs are deleted once they succeed,
+// the ones that keep failing stay behind as dead.
+type Job struct {
+	ID          string          `bun:"type:uuid,pk" json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `bun:"type:jsonb" json:"payload"`
+	Status      JobStatus       `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedBy    string          `bun:",nullzero" json:"locked_by,omitempty"`
+	LockedUntil *time.Time      `bun:",nullzero" json:"locked_until,omitempty"`
+	LastError   string          `bun:",nullzero" json:"last_error,omitempty"`
+	CreatedAt   time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt   time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:jobs" json:"-"`
+}
+
+// JobSchedule tracks when a cron job is due next, shared by every worker
+// so each run is enqueued once.
+type JobSchedule struct {
+	Name      string    `bun:",pk" json:"name"`
+	Spec      string    `json:"spec"`
+	NextRunAt time.Time `json:"next_run_at"`
+	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:job_schedules" json:"-"`
+}
+
+type JobRepository interface {
+	Enqueue(context.Context, *Job) error
+	// Claim locks up to limit due jobs of the given kinds for the worker,
+	// counting an attempt for each. Running jobs whose lock expired, their
+	// worker having died, are claimed again.
+	Claim(ctx context.Context, workerID string, kinds []string, limit int, lockFor time.Duration) ([]Job, error)
+	// Complete deletes a job its worker finished.
+	Complete(context.Context, *Job) error
+	// Retry puts a failed job back in the queue to run again at runAt.
+	Retry(ctx context.Context, job *Job, runAt time.Time, lastError string) error
+	// Kill marks a job dead, it is not run again.
+	Kill(ctx context.Context, job *Job, lastError string) error
+	// Release puts a job back in the queue without counting the attempt,
+	// for jobs their worker stopped before they finished.
+	Release(context.Context, *Job) error
+	// SaveSchedule registers a cron schedule, a schedule whose spec changed
+	// starts over from its new next run.
+	SaveSchedule(context.Context, *JobSchedule) error
+	// FindDueSchedules returns the named schedules due by now.
+	FindDueSchedules(ctx context.Context, names []string, now time.Time) ([]JobSchedule, error)
+	// AdvanceSchedule moves the schedule to its next run and enqueues job,
+	// only if the schedule was still due at from. It returns false when
+	// another worker advanced it first.
+	AdvanceSchedule(ctx context.Context, schedule *JobSchedule, from time.Time, job *Job) (bool, error)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ENUM(quiz, participant)
+type ReportTarget string
+
+// ENUM(spam, offensive, inappropriate, cheating, other)
+type ReportReason string
+
+// ENUM(open, resolved, dismissed)
+type ReportStatus string
+
+// ENUM(dismiss, hide, warn, suspend, ban)
+type ReportResolution string
+
+// ModerationReport flags a public quiz or a player's nickname for
+// moderators to review. Reports come from users or, for new public
+// quizzes, from the content classifier.
+type ModerationReport struct {
+	ID         string       `bun:"type:uuid,pk" json:"id"`
+	TargetType ReportTarget `json:"target_type"`
+	TargetID   string       `bun:"type:uuid,notnull" json:"target_id"`
+	// AuthorID is the user behind the reported content, it is nil for
+	// players who joined as guests.
+	AuthorID *string `bun:"type:uuid,nullzero" json:"author_id"`
+	// ReporterID is nil when the classifier flagged the content.
+	ReporterID *string          `bun:"type:uuid,nullzero" json:"reporter_id"`
+	Reason     ReportReason     `json:"reason"`
+	Details    *string          `bun:",nullzero" json:"details"`
+	Status     ReportStatus     `json:"status"`
+	Resolution ReportResolution `bun:",nullzero" json:"resolution,omitempty"`
+	ResolvedBy *string          `bun:"type:uuid,nullzero" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time       `bun:",nullzero" json:"resolved_at,omitempty"`
+	CreatedAt  time.Time        `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt  time.Time        `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:moderation_reports" json:"-"`
+}
+
+type ListModerationReportOptions struct {
+	Paginator  Paginator
+	Status     ReportStatus
+	TargetType ReportTarget
+}
+
+type ModerationReportRepository interface {
+	// Create saves a report, database.ErrReportAlreadyOpen is returned when
+	// the reporter already has an open report on the target.
+	Create(context.Context, *ModerationReport) error
+	FindOne(ctx context.Context, id string) (*ModerationReport, error)
+	// FindAll returns reports oldest first, so the queue is worked through
+	// in order.
+	FindAll(context.Context, *ListModerationReportOptions) ([]ModerationReport, int64, error)
+	// Resolve closes every open report on the report's target with the
+	// report's status and resolution, one decision settles them all.
+	Resolve(context.Context, *ModerationReport) error
+}
+
+type CreateModerationReportRequest struct {
+	TargetType ReportTarget `json:"target_type" valid:"required~The target type field is required,in(quiz|participant)~The target type must be quiz or participant"`
+	TargetID   string       `json:"target_id" valid:"required~The target id field is required,uuid~The target id must be a valid uuid"`
+	Reason     ReportReason `json:"reason" valid:"required~The reason field is required,in(spam|offensive|inappropriate|cheating|other)~The reason is not valid"`
+	Details    string       `json:"details" valid:"maxstringlength(1000)~The details may not be longer than 1000 characters"`
+}
+
+type ResolveModerationReportRequest struct {
+	Resolution ReportResolution `json:"resolution" valid:"required~The resolution field is required,in(dismiss|hide|warn|suspend|ban)~The resolution is not valid"`
+	Note       string           `json:"note" valid:"maxstringlength(1000)~The note may not be longer than 1000 characters"`
+}
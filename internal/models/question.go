@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/uptrace/bun"
@@ -25,18 +26,21 @@ type QuestionType struct {
 type OptionType string
 
 type Question struct {
-	ID                string     `bun:"type:uuid,pk" json:"id"`
-	QuizID            string     `bun:"type:uuid,notnull" json:"quiz_id"`
-	QuestionTypeID    string     `bun:"type:uuid,notnull" json:"question_type_id"`
-	Question          string     `json:"question"`
-	TimeLimitDuration int        `json:"time_limit_duration"`
-	Position          int        `json:"position"`
-	OptionType        OptionType `json:"option_type"`
-	CreatedAt         time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
-	UpdatedAt         time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+	ID                string          `bun:"type:uuid,pk" json:"id"`
+	QuizID            string          `bun:"type:uuid,notnull" json:"quiz_id"`
+	QuestionTypeID    string          `bun:"type:uuid,notnull" json:"question_type_id"`
+	Question          string          `json:"question"`
+	TimeLimitDuration int             `json:"time_limit_duration"`
+	Position          int             `json:"position"`
+	OptionType        OptionType      `json:"option_type"`
+	Settings          json.RawMessage `bun:"type:jsonb,nullzero" json:"settings,omitempty"`
+	AttachmentID      *string         `bun:"type:uuid,nullzero" json:"attachment_id"`
+	CreatedAt         time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt         time.Time       `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
 
 	QuestionType    *QuestionType    `bun:"rel:belongs-to,join:question_type_id=id" json:"question_type"`
 	QuestionOptions []QuestionOption `bun:"rel:has-many,join:id=question_id" json:"options"`
+	Attachment      *Upload          `bun:"rel:belongs-to,join:attachment_id=id" json:"attachment"`
 
 	bun.BaseModel `bun:"table:questions" json:"-"`
 }
@@ -46,6 +50,7 @@ type QuestionOption struct {
 	QuestionID string    `bun:"type:uuid,notnull" json:"question_id"`
 	Option     string    `json:"option"`
 	IsCorrect  bool      `json:"is_correct"`
+	Position   int       `json:"position"`
 	CreatedAt  time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt  time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
 
@@ -55,3 +60,14 @@ type QuestionOption struct {
 type QuestionTypeRepository interface {
 	FindAll(context.Context) ([]QuestionType, error)
 }
+
+type FindQuestionOptions struct {
+	ID     string
+	QuizID string
+}
+
+type QuestionRepository interface {
+	CreateMany(context.Context, []Question) error
+	Update(context.Context, *Question) error
+	FindOne(context.Context, *FindQuestionOptions) (*Question, error)
+}
@@ -18,6 +18,7 @@ type Quiz struct {
 	Visibility  QuizVisibility `bun:",nullzero" json:"visibility"`
 	CoverImage  *string        `bun:",nullzero" json:"cover_image"`
 	PublishedAt *time.Time     `bun:",nullzero" json:"published_at"`
+	HiddenAt    *time.Time     `bun:",nullzero" json:"hidden_at,omitempty"`
 	CreatedAt   time.Time      `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt   time.Time      `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
 
@@ -27,6 +28,12 @@ type Quiz struct {
 	bun.BaseModel `bun:"table:quizzes" json:"-"`
 }
 
+// IsHidden reports whether a moderator hid the quiz, hidden quizzes
+// cannot be played.
+func (q *Quiz) IsHidden() bool {
+	return q.HiddenAt != nil
+}
+
 type FindQuizOptions struct {
 	ID      string
 	OwnerID string
@@ -59,6 +66,9 @@ type CreateOrEditQuizRequest struct {
 			IsCorrect bool   `json:"is_correct"`
 		} `json:"options"`
 	} `json:"questions"`
-	Visibility QuizVisibility `json:"visibility" valid:"required~The visibility field is required,in(public|private)~The visibility field must be public or private"`
-	CoverImage string         `json:"cover_image" valid:"optional"`
+	Visibility   QuizVisibility `json:"visibility" valid:"required~The visibility field is required,in(public|private)~The visibility field must be public or private"`
+	CoverImageID string         `json:"cover_image_id" valid:"uuid~The cover image id must be a valid uuid,optional"`
+	// CoverImage was the cover url before covers were uploaded. It is only
+	// read to turn away clients still sending it, rather than dropping it.
+	CoverImage *string `json:"cover_image"`
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ENUM(avatar, quiz_cover, question_attachment)
+type UploadPurpose string
+
+// ENUM(image, audio)
+type UploadKind string
+
+type Upload struct {
+	ID           string        `bun:"type:uuid,pk" json:"id"`
+	OwnerID      string        `bun:"type:uuid,notnull" json:"owner_id"`
+	Purpose      UploadPurpose `json:"purpose"`
+	Kind         UploadKind    `json:"kind"`
+	ContentType  string        `json:"content_type"`
+	Size         int64         `json:"size"`
+	Width        *int          `bun:",nullzero" json:"width"`
+	Height       *int          `bun:",nullzero" json:"height"`
+	Key          string        `json:"-"`
+	URL          string        `json:"url"`
+	ThumbnailKey *string       `bun:",nullzero" json:"-"`
+	ThumbnailURL *string       `bun:",nullzero" json:"thumbnail_url"`
+	CreatedAt    time.Time     `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt    time.Time     `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
+
+	bun.BaseModel `bun:"table:uploads" json:"-"`
+}
+
+type FindUploadOptions struct {
+	ID      string
+	OwnerID string
+}
+
+type UploadRepository interface {
+	Create(context.Context, *Upload) error
+	Update(context.Context, *Upload) error
+	FindOne(context.Context, *FindUploadOptions) (*Upload, error)
+}
+
+type AttachUploadRequest struct {
+	UploadID string `json:"upload_id" valid:"required~The upload id field is required,uuid~The upload id must be a valid uuid"`
+}
@@ -7,6 +7,12 @@ import (
 	"github.com/uptrace/bun"
 )
 
+// ENUM(user, admin)
+type UserRole string
+
+// ENUM(active, suspended, banned)
+type UserStatus string
+
 type User struct {
 	ID              string     `bun:"type:uuid,pk" json:"id"`
 	Name            *string    `bun:",nullzero" json:"name"`
@@ -16,12 +22,31 @@ type User struct {
 	Password        *string    `bun:",nullzero" json:"-"`
 	Avatar          *string    `bun:",nullzero" json:"avatar"`
 	GoogleID        *string    `bun:",nullzero" json:"-"`
+	Role            UserRole   `bun:",nullzero" json:"role"`
+	Status          UserStatus `bun:",nullzero" json:"status"`
+	TokensRevokedAt *time.Time `bun:",nullzero" json:"-"`
 	CreatedAt       time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"created_at"`
 	UpdatedAt       time.Time  `bun:",nullzero,notnull,default:current_timestamp" json:"updated_at"`
 
 	bun.BaseModel `bun:"table:users" json:"-"`
 }
 
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
+func (u *User) IsActive() bool {
+	return u.Status == UserStatusActive
+}
+
+// TokenRevoked reports whether a token issued at issuedAt was revoked,
+// revoking signs the user out everywhere. Tokens carry their issue time
+// in whole seconds, so one issued in the second of the revocation is
+// revoked too.
+func (u *User) TokenRevoked(issuedAt time.Time) bool {
+	return u.TokensRevokedAt != nil && !issuedAt.After(*u.TokensRevokedAt)
+}
+
 type FindUserOptions struct {
 	ID    string
 	Email string
@@ -29,5 +54,6 @@ type FindUserOptions struct {
 
 type UserRepository interface {
 	Create(context.Context, *User) error
+	Update(context.Context, *User) error
 	FindOne(context.Context, *FindUserOptions) (*User, error)
 }
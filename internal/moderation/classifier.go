@@ -0,0 +1,63 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Classifier decides whether text needs a moderator's attention. It is
+// the hook for plugging in a smarter classifier than the keyword list,
+// such as an external moderation service.
+type Classifier interface {
+	Classify(ctx context.Context, text string) (*Verdict, error)
+}
+
+type Verdict struct {
+	Flagged bool
+	// Reason explains the verdict to moderators, for example the words
+	// that matched.
+	Reason string
+}
+
+// KeywordClassifier flags text containing any of its keywords, matched
+// as whole words or phrases regardless of case and punctuation.
+type KeywordClassifier struct {
+	keywords []string
+}
+
+func NewKeywordClassifier(keywords []string) *KeywordClassifier {
+	normalized := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = normalize(keyword); keyword != "" {
+			normalized = append(normalized, keyword)
+		}
+	}
+
+	return &KeywordClassifier{keywords: normalized}
+}
+
+func (k *KeywordClassifier) Classify(_ context.Context, text string) (*Verdict, error) {
+	// padding with spaces matches keywords on word boundaries only
+	text = " " + normalize(text) + " "
+
+	var matched []string
+	for _, keyword := range k.keywords {
+		if strings.Contains(text, " "+keyword+" ") {
+			matched = append(matched, keyword)
+		}
+	}
+
+	if len(matched) == 0 {
+		return &Verdict{}, nil
+	}
+	return &Verdict{Flagged: true, Reason: "matched " + strings.Join(matched, ", ")}, nil
+}
+
+// normalize lowercases s and turns everything but letters and numbers
+// into single spaces.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
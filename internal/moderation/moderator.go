@@ -0,0 +1,95 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/database"
+	"github.com/oxiginedev/sabipass/internal/jobs"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+// ClassifyJob runs a public quiz through the classifier, opening a report
+// for moderators when it is flagged.
+var ClassifyJob = jobs.Kind[ClassifyArgs]{Name: "moderation.classify", MaxAttempts: 5, Timeout: time.Minute}
+
+type ClassifyArgs struct {
+	QuizID string `json:"quiz_id"`
+}
+
+type Moderator struct {
+	classifier Classifier
+	quizRepo   models.QuizRepository
+	reportRepo models.ModerationReportRepository
+}
+
+func NewModerator(classifier Classifier, quizRepo models.QuizRepository, reportRepo models.ModerationReportRepository) *Moderator {
+	return &Moderator{
+		classifier: classifier,
+		quizRepo:   quizRepo,
+		reportRepo: reportRepo,
+	}
+}
+
+// HandleClassify runs ClassifyJob. Quizzes that are gone, private or
+// already hidden are left alone, as are quizzes with an open report from
+// an earlier run.
+func (m *Moderator) HandleClassify(ctx context.Context, args ClassifyArgs) error {
+	quiz, err := m.quizRepo.FindOne(ctx, &models.FindQuizOptions{ID: args.QuizID})
+	if err != nil {
+		if errors.Is(err, database.ErrQuizNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if quiz.Visibility != models.QuizVisibilityPublic || quiz.IsHidden() {
+		return nil
+	}
+
+	verdict, err := m.classifier.Classify(ctx, quizText(quiz))
+	if err != nil {
+		return err
+	}
+
+	if !verdict.Flagged {
+		return nil
+	}
+
+	now := time.Now()
+	err = m.reportRepo.Create(ctx, &models.ModerationReport{
+		ID:         utils.Uuid(),
+		TargetType: models.ReportTargetQuiz,
+		TargetID:   quiz.ID,
+		AuthorID:   utils.Ptr(quiz.OwnerID),
+		Reason:     models.ReportReasonOffensive,
+		Details:    utils.Ptr("flagged automatically: " + verdict.Reason),
+		Status:     models.ReportStatusOpen,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	if errors.Is(err, database.ErrReportAlreadyOpen) {
+		return nil
+	}
+	return err
+}
+
+// quizText is everything players of the quiz get to read.
+func quizText(quiz *models.Quiz) string {
+	parts := []string{quiz.Title}
+	if quiz.Description != nil {
+		parts = append(parts, *quiz.Description)
+	}
+
+	for _, question := range quiz.Questions {
+		parts = append(parts, question.Question)
+		for _, option := range question.QuestionOptions {
+			parts = append(parts, option.Option)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
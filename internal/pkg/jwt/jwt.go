@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/oxiginedev/sabipass/config"
 	"github.com/oxiginedev/sabipass/internal/models"
 )
@@ -25,74 +25,173 @@ type Token struct {
 type ValidatedToken struct {
 	UserID    string
 	ExpiresIn int64
+	IssuedAt  time.Time
 }
 
 type TokenManager interface {
 	GenerateToken(user *models.User) (Token, error)
 	ValidateToken(tokenString string) (*ValidatedToken, error)
+	// JWKS returns the public keys tokens are verified with, it is empty
+	// when tokens are signed with the shared secret.
+	JWKS() JWKS
 }
 
 type jwtTokenManager struct {
-	secretKey string
-	expiry    time.Duration
+	signingMethod jwt.SigningMethod
+	signingKey    any
+	signingKeyID  string
+	// keys are the keys tokens are verified with, by kid. The shared
+	// secret is kept under the empty kid.
+	keys     map[string]verificationKey
+	methods  []string
+	jwks     JWKS
+	issuer   string
+	audience string
+	expiry   time.Duration
 }
 
-func NewJwtTokenManager(cfg *config.Config) TokenManager {
+func NewJwtTokenManager(cfg *config.Config) (TokenManager, error) {
 	if cfg.Auth.JWT.Expiry == 0 {
 		cfg.Auth.JWT.Expiry = defaultTokenExpiry
 	}
 
-	return &jwtTokenManager{
-		secretKey: cfg.Auth.JWT.SecretKey,
-		expiry:    cfg.Auth.JWT.Expiry,
+	j := &jwtTokenManager{
+		keys:     make(map[string]verificationKey),
+		jwks:     JWKS{Keys: []JWK{}},
+		issuer:   cfg.Auth.JWT.Issuer,
+		audience: cfg.Auth.JWT.Audience,
+		expiry:   cfg.Auth.JWT.Expiry,
+	}
+
+	if cfg.Auth.JWT.SecretKey != "" {
+		j.addKey(verificationKey{method: jwt.SigningMethodHS256, public: []byte(cfg.Auth.JWT.SecretKey)}, nil)
+		j.signingMethod = jwt.SigningMethodHS256
+		j.signingKey = []byte(cfg.Auth.JWT.SecretKey)
+	}
+
+	if cfg.Auth.JWT.SigningKey != "" {
+		private, err := parsePrivateKey([]byte(cfg.Auth.JWT.SigningKey))
+		if err != nil {
+			return nil, err
+		}
+
+		key, jwk, err := newVerificationKey(private.Public())
+		if err != nil {
+			return nil, err
+		}
+
+		j.addKey(key, &jwk)
+		j.signingMethod = key.method
+		j.signingKey = private
+		j.signingKeyID = key.id
+	}
+
+	for _, path := range cfg.Auth.JWT.VerificationKeyFiles {
+		public, err := readPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, jwk, err := newVerificationKey(public)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := j.keys[key.id]; !ok {
+			j.addKey(key, &jwk)
+		}
+	}
+
+	if j.signingKey == nil {
+		return nil, errors.New("[jwt]: no signing key or secret key configured")
+	}
+
+	return j, nil
+}
+
+func (j *jwtTokenManager) addKey(key verificationKey, jwk *JWK) {
+	j.keys[key.id] = key
+	j.methods = append(j.methods, key.method.Alg())
+	if jwk != nil {
+		j.jwks.Keys = append(j.jwks.Keys, *jwk)
 	}
 }
 
 func (j *jwtTokenManager) GenerateToken(user *models.User) (Token, error) {
-	claims := jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(j.expiry).Unix(),
-		"iat": time.Now().Unix(),
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   user.ID,
+		Issuer:    j.issuer,
+		Audience:  jwt.ClaimStrings{j.audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(j.expiry)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+
+	jwtToken := jwt.NewWithClaims(j.signingMethod, claims)
+	if j.signingKeyID != "" {
+		jwtToken.Header["kid"] = j.signingKeyID
 	}
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	accessToken, err := jwtToken.SignedString([]byte(j.secretKey))
+	accessToken, err := jwtToken.SignedString(j.signingKey)
 	if err != nil {
 		return Token{}, err
 	}
 
 	return Token{
 		AccessToken: accessToken,
-		ExpiresIn:   claims["exp"].(int64),
+		ExpiresIn:   claims.ExpiresAt.Unix(),
 	}, nil
 }
 
 func (j *jwtTokenManager) ValidateToken(tokenString string) (*ValidatedToken, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok {
-			return nil, fmt.Errorf("[jwt]: unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.secretKey), nil
-	})
+	var claims jwt.RegisteredClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, j.verificationKey,
+		jwt.WithValidMethods(j.methods),
+		jwt.WithIssuer(j.issuer),
+		jwt.WithAudience(j.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 	if err != nil {
-		verr, ok := err.(*jwt.ValidationError)
-		if ok && verr.Errors == jwt.ValidationErrorExpired {
+		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrTokenExpired
 		}
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if ok && token.Valid {
-		userId := claims["sub"].(string)
-		expiresIn := claims["exp"].(float64)
+	if !token.Valid || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
 
-		return &ValidatedToken{
-			UserID:    userId,
-			ExpiresIn: int64(expiresIn),
-		}, nil
+	validated := &ValidatedToken{
+		UserID:    claims.Subject,
+		ExpiresIn: claims.ExpiresAt.Unix(),
 	}
+	if claims.IssuedAt != nil {
+		validated.IssuedAt = claims.IssuedAt.Time
+	}
+
+	return validated, nil
+}
+
+// verificationKey picks the key by the kid of the token, a key only
+// verifies tokens signed with its own method so a public key can never be
+// used as an HMAC secret.
+func (j *jwtTokenManager) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("[jwt]: unknown key %q", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("[jwt]: unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.public, nil
+}
 
-	return nil, ErrInvalidToken
+func (j *jwtTokenManager) JWKS() JWKS {
+	return j.jwks
 }
@@ -0,0 +1,279 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+const testSecret = "a-shared-secret-that-is-32-bytes"
+
+var testUser = &models.User{ID: "5b0f2b4e-8d7e-4a52-9a0e-3f1c2d4b6a70"}
+
+func newConfig(signingKey crypto.Signer, secret string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Auth.JWT.SecretKey = secret
+	cfg.Auth.JWT.Issuer = "sabipass"
+	cfg.Auth.JWT.Audience = "sabipass"
+	cfg.Auth.JWT.Expiry = time.Hour
+	if signingKey != nil {
+		cfg.Auth.JWT.SigningKey = string(privatePEM(signingKey))
+	}
+	return cfg
+}
+
+func newManager(t *testing.T, cfg *config.Config) TokenManager {
+	t.Helper()
+
+	m, err := NewJwtTokenManager(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, minRSAKeySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func privatePEM(key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicPEM(t *testing.T, key crypto.PublicKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func claims(issuer, audience string) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Subject:   testUser.ID,
+		Issuer:    issuer,
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, c jwt.RegisteredClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestRoundTrip(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		alg  string
+	}{
+		{name: "secret", cfg: newConfig(nil, testSecret), alg: "HS256"},
+		{name: "rsa", cfg: newConfig(newRSAKey(t), ""), alg: "RS256"},
+		{name: "ed25519", cfg: newConfig(edKey, ""), alg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newManager(t, tt.cfg)
+
+			token, err := m.GenerateToken(testUser)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token.AccessToken, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Method.Alg() != tt.alg {
+				t.Errorf("alg = %s, want %s", parsed.Method.Alg(), tt.alg)
+			}
+
+			validated, err := m.ValidateToken(token.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateToken: %v", err)
+			}
+			if validated.UserID != testUser.ID {
+				t.Errorf("UserID = %q, want %q", validated.UserID, testUser.ID)
+			}
+		})
+	}
+}
+
+// TestPublicKeyAsHMACSecretRejected signs tokens with HS256 using the
+// published public key as the secret, the classic algorithm confusion.
+func TestPublicKeyAsHMACSecretRejected(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	publicKey := publicPEM(t, &rsaKey.PublicKey)
+
+	for name, cfg := range map[string]*config.Config{
+		"without secret": newConfig(rsaKey, ""),
+		"with secret":    newConfig(rsaKey, testSecret),
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := newManager(t, cfg)
+			kid := m.JWKS().Keys[0].Kid
+
+			for _, kid := range []string{kid, ""} {
+				token := sign(t, jwt.SigningMethodHS256, kid, publicKey, claims("sabipass", "sabipass"))
+				if _, err := m.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("kid %q: err = %v, want ErrInvalidToken", kid, err)
+				}
+			}
+		})
+	}
+}
+
+// TestSecretNotUsableWithKeyID makes sure the shared secret, kept under the
+// empty kid, cannot verify a token naming an RSA key.
+func TestSecretNotUsableWithKeyID(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	m := newManager(t, newConfig(rsaKey, testSecret))
+	kid := m.JWKS().Keys[0].Kid
+
+	token := sign(t, jwt.SigningMethodHS256, kid, []byte(testSecret), claims("sabipass", "sabipass"))
+	if _, err := m.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestUnknownKeyIDRejected(t *testing.T) {
+	m := newManager(t, newConfig(newRSAKey(t), ""))
+	other := newRSAKey(t)
+
+	token := sign(t, jwt.SigningMethodRS256, "not-a-known-kid", other, claims("sabipass", "sabipass"))
+	if _, err := m.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestWrongIssuerOrAudienceRejected(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	m := newManager(t, newConfig(rsaKey, ""))
+	kid := m.JWKS().Keys[0].Kid
+
+	tests := map[string]jwt.RegisteredClaims{
+		"issuer":   claims("someone-else", "sabipass"),
+		"audience": claims("sabipass", "another-service"),
+	}
+
+	for name, c := range tests {
+		t.Run(name, func(t *testing.T) {
+			token := sign(t, jwt.SigningMethodRS256, kid, rsaKey, c)
+			if _, err := m.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestExpiredTokenRejected(t *testing.T) {
+	m := newManager(t, newConfig(nil, testSecret))
+
+	c := claims("sabipass", "sabipass")
+	c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	token := sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), c)
+	if _, err := m.ValidateToken(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("err = %v, want ErrTokenExpired", err)
+	}
+}
+
+// TestRetiredKeyStillValidates rotates the signing key, keeping the old
+// public key as a verification key.
+func TestRetiredKeyStillValidates(t *testing.T) {
+	oldKey := newRSAKey(t)
+	oldManager := newManager(t, newConfig(oldKey, ""))
+
+	token, err := oldManager.GenerateToken(testUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retired := filepath.Join(t.TempDir(), "retired.pem")
+	if err := os.WriteFile(retired, publicPEM(t, &oldKey.PublicKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newConfig(newKey, "")
+	cfg.Auth.JWT.VerificationKeyFiles = []string{retired}
+	m := newManager(t, cfg)
+
+	if _, err := m.ValidateToken(token.AccessToken); err != nil {
+		t.Errorf("token of the retired key: %v", err)
+	}
+
+	if got := len(m.JWKS().Keys); got != 2 {
+		t.Errorf("JWKS has %d keys, want the signing and the retired key", got)
+	}
+
+	newToken, err := m.GenerateToken(testUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oldManager.ValidateToken(newToken.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token of the new key on the old manager: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+// TestThumbprint checks the example of RFC 7638 section 3.1.
+func TestThumbprint(t *testing.T) {
+	jwk := JWK{
+		Kty: "RSA",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W" +
+			"-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbI" +
+			"SD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E: "AQAB",
+	}
+
+	if got, want := thumbprint(jwk), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("thumbprint = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeySize is the smallest RSA modulus accepted, in bits.
+const minRSAKeySize = 2048
+
+// JWK is the public half of a key in the JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// N and E are set for RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Crv and X are set for Ed25519 keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the set of keys tokens can be verified with, served for other
+// services to check sabipass tokens on their own.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// verificationKey checks the signature of tokens whose kid header is id.
+type verificationKey struct {
+	id     string
+	method jwt.SigningMethod
+	public any
+}
+
+func parsePrivateKey(raw []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("[jwt]: signing key is not PEM encoded")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("[jwt]: unexpected signing key block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[jwt]: could not parse signing key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("[jwt]: unsupported signing key type %T, use RSA or Ed25519", key)
+	}
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("[jwt]: could not read verification key: %w", err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("[jwt]: verification key %s is not PEM encoded", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("[jwt]: unexpected verification key block %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[jwt]: could not parse verification key %s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+// newVerificationKey works out the signing method of public and names it
+// after its thumbprint, so the same key always gets the same kid.
+func newVerificationKey(public crypto.PublicKey) (verificationKey, JWK, error) {
+	var jwk JWK
+	var method jwt.SigningMethod
+
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeySize {
+			return verificationKey{}, JWK{}, fmt.Errorf("[jwt]: RSA keys must be at least %d bits", minRSAKeySize)
+		}
+		method = jwt.SigningMethodRS256
+		jwk = JWK{
+			Kty: "RSA",
+			N:   encode(key.N.Bytes()),
+			E:   encode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+		jwk = JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   encode(key),
+		}
+	default:
+		return verificationKey{}, JWK{}, fmt.Errorf("[jwt]: unsupported key type %T, use RSA or Ed25519", public)
+	}
+
+	jwk.Kid = thumbprint(jwk)
+	jwk.Use = "sig"
+	jwk.Alg = method.Alg()
+
+	return verificationKey{id: jwk.Kid, method: method, public: public}, jwk, nil
+}
+
+// thumbprint is the RFC 7638 thumbprint of the key, the hash of its
+// required members in lexicographic order.
+func thumbprint(jwk JWK) string {
+	var members string
+	switch jwk.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return encode(sum[:])
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/oxiginedev/sabipass/config"
+)
+
+type contextKey struct{}
+
+// New returns the logger configured in cfg writing to w.
+func New(cfg *config.Config, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.Log.Level}
+
+	if cfg.Log.Format == config.LogFormatJson {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+// when there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
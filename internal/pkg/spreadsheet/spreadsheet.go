@@ -0,0 +1,341 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxColumns is the number of columns an xlsx sheet can have, up to
+	// column XFD.
+	maxColumns = 16384
+	// maxZipEntrySize bounds the decompressed size of the xlsx parts read,
+	// so a small archive cannot expand into gigabytes of xml.
+	maxZipEntrySize = 16 << 20
+)
+
+var (
+	ErrEmptySheet        = errors.New("spreadsheet: no rows found")
+	ErrUnsupportedFormat = errors.New("spreadsheet: unsupported format")
+	ErrTooManyRows       = errors.New("spreadsheet: too many rows")
+)
+
+var cellRefPattern = regexp.MustCompile(`^([A-Z]{1,3})[0-9]+$`)
+
+// ENUM(csv, tsv, xlsx)
+type Format string
+
+// DetectFormat guesses the table format from the file name and the first
+// bytes of its content. Pasted spreadsheet cells arrive tab separated, so
+// a tab on the first line wins over the file extension.
+func DetectFormat(filename string, content []byte) Format {
+	if bytes.HasPrefix(content, []byte("PK\x03\x04")) {
+		return FormatXlsx
+	}
+
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	if bytes.ContainsRune(firstLine, '\t') {
+		return FormatTsv
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".tsv", ".tab":
+		return FormatTsv
+	case ".xlsx":
+		return FormatXlsx
+	}
+
+	return FormatCsv
+}
+
+// Read parses content into rows of trimmed cells. Trailing empty rows are
+// dropped so that spreadsheets with formatted but unused rows stay clean.
+// xlsx rows are numbered by the file rather than counted, so a row
+// numbered past maxRows is an error instead of being padded up to.
+func Read(filename string, content []byte, maxRows int) ([][]string, error) {
+	var (
+		rows [][]string
+		err  error
+	)
+
+	switch DetectFormat(filename, content) {
+	case FormatCsv:
+		rows, err = readDelimited(content, ',')
+	case FormatTsv:
+		rows, err = readDelimited(content, '\t')
+	case FormatXlsx:
+		rows, err = readXlsx(content, maxRows)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for len(rows) > 0 && isEmptyRow(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptySheet
+	}
+
+	return rows, nil
+}
+
+func readDelimited(content []byte, delimiter rune) ([][]string, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("spreadsheet: %w", err)
+		}
+
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, record)
+	}
+
+	return rows, nil
+}
+
+type xlsxSharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxWorkbook struct {
+	// Sheets are in tab order, RelID points into the workbook relationships
+	Sheets []struct {
+		RelID string `xml:"id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXlsx reads the first worksheet of an xlsx workbook. Formulas are not
+// evaluated; the cached value stored by the authoring application is used.
+func readXlsx(content []byte, maxRows int) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("spreadsheet: invalid xlsx file: %w", err)
+	}
+
+	var sharedStrings []string
+	var sst xlsxSharedStrings
+	if err := decodeZipXML(archive, "xl/sharedStrings.xml", &sst); err == nil {
+		for _, item := range sst.Items {
+			text := item.Text
+			for _, run := range item.Runs {
+				text += run.Text
+			}
+			sharedStrings = append(sharedStrings, text)
+		}
+	} else if !errors.Is(err, errZipEntryNotFound) {
+		return nil, err
+	}
+
+	sheetPath, err := firstWorksheet(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(archive, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		if row.Index > maxRows {
+			return nil, fmt.Errorf("%w: row %d is past row %d", ErrTooManyRows, row.Index, maxRows)
+		}
+
+		// rows without content are omitted from the sheet xml, pad them back
+		// in so that row numbers in error messages match the spreadsheet
+		for row.Index > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				var err error
+				column, err = columnIndex(cell.Ref)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(sharedStrings) {
+					return nil, fmt.Errorf("spreadsheet: invalid shared string reference in cell %s", cell.Ref)
+				}
+				value = sharedStrings[idx]
+			case "inlineStr":
+				value = cell.Inline.Text
+			case "b":
+				value = strings.ToUpper(strconv.FormatBool(cell.Value == "1"))
+			default:
+				value = cell.Value
+			}
+
+			cells[column] = strings.TrimSpace(value)
+		}
+
+		rows = append(rows, cells)
+	}
+
+	return rows, nil
+}
+
+// firstWorksheet finds the part holding the first worksheet tab. Parts are
+// named in the order sheets were created, so after tabs are moved or
+// deleted the first one is not necessarily sheet1.xml.
+func firstWorksheet(archive *zip.Reader) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodeZipXML(archive, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+
+	var rels xlsxRelationships
+	if err := decodeZipXML(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, sheet := range workbook.Sheets {
+		for _, rel := range rels.Relationships {
+			// chart sheets are listed among the tabs too
+			if rel.ID != sheet.RelID || !strings.HasSuffix(rel.Type, "/worksheet") {
+				continue
+			}
+
+			if target, ok := strings.CutPrefix(rel.Target, "/"); ok {
+				return target, nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+
+	return "", ErrEmptySheet
+}
+
+var errZipEntryNotFound = errors.New("spreadsheet: xlsx entry not found")
+
+func decodeZipXML(archive *zip.Reader, name string, v any) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		if file.UncompressedSize64 > maxZipEntrySize {
+			return fmt.Errorf("spreadsheet: %s is too large", name)
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("spreadsheet: could not open %s: %w", name, err)
+		}
+		defer rc.Close()
+
+		// the declared size is not to be trusted, the reader is capped too
+		if err := xml.NewDecoder(io.LimitReader(rc, maxZipEntrySize)).Decode(v); err != nil {
+			return fmt.Errorf("spreadsheet: could not decode %s: %w", name, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", errZipEntryNotFound, name)
+}
+
+// columnIndex converts a cell reference such as "C12" to a zero based
+// column index, references past column XFD are rejected.
+func columnIndex(ref string) (int, error) {
+	match := cellRefPattern.FindStringSubmatch(ref)
+	if match == nil {
+		return 0, fmt.Errorf("spreadsheet: invalid cell reference %q", ref)
+	}
+
+	idx := 0
+	for _, r := range match[1] {
+		idx = idx*26 + int(r-'A'+1)
+	}
+
+	if idx > maxColumns {
+		return 0, fmt.Errorf("spreadsheet: cell reference %q is past the last column", ref)
+	}
+	return idx - 1, nil
+}
+
+// ColumnName converts a zero based column index to its spreadsheet letter,
+// e.g. 0 => "A" and 27 => "AB".
+func ColumnName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
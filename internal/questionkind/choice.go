@@ -0,0 +1,126 @@
+package questionkind
+
+import (
+	"errors"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+// choiceKind is the classic quiz question where players pick one or more
+// of the options marked correct.
+type choiceKind struct{}
+
+func (choiceKind) Slug() string     { return SlugQuiz }
+func (choiceKind) Scored() bool     { return true }
+func (choiceKind) HasOptions() bool { return true }
+
+func (choiceKind) Validate(question *models.Question) error {
+	if err := validateOptions(question, 2); err != nil {
+		return err
+	}
+
+	correct := len(correctOptionIDs(question))
+	switch question.OptionType {
+	case models.OptionTypeSingleChoice:
+		if correct != 1 {
+			return errors.New("a single choice question must have exactly one correct option")
+		}
+	case models.OptionTypeMultipleChoice:
+		if correct == 0 {
+			return errors.New("at least one option must be marked correct")
+		}
+	default:
+		return errors.New("the option type must be single_choice or multiple_choice")
+	}
+	return nil
+}
+
+func (choiceKind) CheckAnswer(question *models.Question, answer *Answer) error {
+	if len(answer.OptionIDs) == 0 {
+		return ErrInvalidAnswer
+	}
+
+	if question.OptionType == models.OptionTypeSingleChoice && len(answer.OptionIDs) != 1 {
+		return ErrInvalidAnswer
+	}
+	return checkOptionIDs(question, answer.OptionIDs)
+}
+
+// Score awards full credit for the exact set of correct options. Multiple
+// choice questions earn partial credit for each correct pick, less one for
+// each wrong pick.
+func (choiceKind) Score(question *models.Question, answer *Answer) Result {
+	correct := make(map[string]bool)
+	for _, id := range correctOptionIDs(question) {
+		correct[id] = true
+	}
+
+	hits, misses := 0, 0
+	for _, id := range answer.OptionIDs {
+		if correct[id] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	if len(correct) == 0 {
+		return Result{}
+	}
+
+	if hits == len(correct) && misses == 0 {
+		return Result{Correct: true, Credit: 1}
+	}
+
+	if question.OptionType != models.OptionTypeMultipleChoice {
+		return Result{}
+	}
+
+	return Result{Credit: max(0, float64(hits-misses)/float64(len(correct)))}
+}
+
+func (k choiceKind) Prompt(question *models.Question) *Prompt {
+	return basePrompt(k, question)
+}
+
+func (choiceKind) Reveal(question *models.Question) any {
+	return map[string]any{"correct_option_ids": correctOptionIDs(question)}
+}
+
+// pollKind collects opinions on a set of options without scoring them.
+type pollKind struct{}
+
+func (pollKind) Slug() string     { return SlugPoll }
+func (pollKind) Scored() bool     { return false }
+func (pollKind) HasOptions() bool { return true }
+
+func (pollKind) Validate(question *models.Question) error {
+	if err := validateOptions(question, 2); err != nil {
+		return err
+	}
+
+	if len(correctOptionIDs(question)) > 0 {
+		return errors.New("poll options cannot be marked correct")
+	}
+
+	if !question.OptionType.IsValid() {
+		return errors.New("the option type must be single_choice or multiple_choice")
+	}
+	return nil
+}
+
+func (pollKind) CheckAnswer(question *models.Question, answer *Answer) error {
+	return choiceKind{}.CheckAnswer(question, answer)
+}
+
+func (pollKind) Score(*models.Question, *Answer) Result {
+	return Result{}
+}
+
+func (k pollKind) Prompt(question *models.Question) *Prompt {
+	return basePrompt(k, question)
+}
+
+func (pollKind) Reveal(*models.Question) any {
+	return nil
+}
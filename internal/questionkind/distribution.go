@@ -0,0 +1,135 @@
+package questionkind
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+// maxTextBuckets caps the typed answers shown, the long tail of one-off
+// answers does not fit on a screen.
+const maxTextBuckets = 20
+
+// Bucket counts the answers that made the same choice.
+type Bucket struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// Distribution counts how the room answered a question. Answers are
+// counted per option for choices and polls, per value for true/false and
+// sliders, and per normalized text for typed answers, keeping the most
+// common ones. Ordering questions have no meaningful buckets and return
+// nil.
+func Distribution(question *models.Question, answers []Answer) []Bucket {
+	if question.QuestionType == nil {
+		return nil
+	}
+
+	switch question.QuestionType.Slug {
+	case SlugQuiz, SlugPoll:
+		return optionDistribution(question, answers)
+	case SlugTrueFalse:
+		buckets := []Bucket{
+			{Key: "true", Label: "True"},
+			{Key: "false", Label: "False"},
+		}
+		for _, answer := range answers {
+			if answer.Boolean == nil {
+				continue
+			}
+			if *answer.Boolean {
+				buckets[0].Count++
+			} else {
+				buckets[1].Count++
+			}
+		}
+		return buckets
+	case SlugSlider:
+		return numberDistribution(answers)
+	case SlugTypeAnswer, SlugWordCloud:
+		return textDistribution(answers)
+	default:
+		return nil
+	}
+}
+
+func optionDistribution(question *models.Question, answers []Answer) []Bucket {
+	options := make([]models.QuestionOption, len(question.QuestionOptions))
+	copy(options, question.QuestionOptions)
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].Position < options[j].Position
+	})
+
+	index := make(map[string]int, len(options))
+	buckets := make([]Bucket, len(options))
+	for i, option := range options {
+		index[option.ID] = i
+		buckets[i] = Bucket{Key: option.ID, Label: option.Option}
+	}
+
+	for _, answer := range answers {
+		for _, id := range answer.OptionIDs {
+			if i, ok := index[id]; ok {
+				buckets[i].Count++
+			}
+		}
+	}
+
+	return buckets
+}
+
+func numberDistribution(answers []Answer) []Bucket {
+	counts := make(map[float64]int)
+	for _, answer := range answers {
+		if answer.Number != nil {
+			counts[*answer.Number]++
+		}
+	}
+
+	numbers := make([]float64, 0, len(counts))
+	for number := range counts {
+		numbers = append(numbers, number)
+	}
+	sort.Float64s(numbers)
+
+	buckets := make([]Bucket, 0, len(numbers))
+	for _, number := range numbers {
+		key := strconv.FormatFloat(number, 'f', -1, 64)
+		buckets = append(buckets, Bucket{Key: key, Label: key, Count: counts[number]})
+	}
+
+	return buckets
+}
+
+func textDistribution(answers []Answer) []Bucket {
+	index := make(map[string]int)
+	buckets := []Bucket{}
+	for _, answer := range answers {
+		key := normalizeText(answer.Text)
+		if key == "" {
+			continue
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(buckets)
+			index[key] = i
+			buckets = append(buckets, Bucket{Key: key, Label: strings.TrimSpace(answer.Text)})
+		}
+		buckets[i].Count++
+	}
+
+	// the first spelling of an answer labels it, ties keep that order
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].Count > buckets[j].Count
+	})
+	if len(buckets) > maxTextBuckets {
+		buckets = buckets[:maxTextBuckets]
+	}
+
+	return buckets
+}
@@ -0,0 +1,231 @@
+package questionkind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+const (
+	SlugQuiz       = "quiz"
+	SlugTrueFalse  = "true_false"
+	SlugTypeAnswer = "type_answer"
+	SlugOrdering   = "ordering"
+	SlugSlider     = "slider"
+	SlugPoll       = "poll"
+	SlugWordCloud  = "word_cloud"
+
+	maxOptions = 10
+)
+
+var (
+	ErrUnknownKind   = errors.New("questionkind: unknown question type")
+	ErrInvalidAnswer = errors.New("questionkind: invalid answer")
+)
+
+// Answer is a player's submission for a question. Which fields are used
+// depends on the kind of the question.
+type Answer struct {
+	OptionIDs []string `json:"option_ids,omitempty"`
+	Text      string   `json:"text,omitempty"`
+	Number    *float64 `json:"number,omitempty"`
+	Boolean   *bool    `json:"boolean,omitempty"`
+}
+
+// Result is the outcome of scoring an answer. Credit ranges from 0 to 1
+// and allows kinds such as ordering to award partial points.
+type Result struct {
+	Correct bool    `json:"correct"`
+	Credit  float64 `json:"credit"`
+}
+
+type Kind interface {
+	Slug() string
+	// Scored reports whether answers earn points, polls and word clouds
+	// only collect responses.
+	Scored() bool
+	// HasOptions reports whether the kind stores its choices as question
+	// options rather than in the question settings.
+	HasOptions() bool
+	// Validate checks that an authored question is playable.
+	Validate(question *models.Question) error
+	// CheckAnswer rejects submissions that do not fit the question.
+	CheckAnswer(question *models.Question, answer *Answer) error
+	Score(question *models.Question, answer *Answer) Result
+	// Prompt returns the question as shown to players, without anything
+	// that gives the answer away.
+	Prompt(question *models.Question) *Prompt
+	// Reveal returns the payload shown to players once the question closes.
+	Reveal(question *models.Question) any
+}
+
+type PromptOption struct {
+	ID     string `json:"id"`
+	Option string `json:"option"`
+}
+
+type Prompt struct {
+	ID                string            `json:"id"`
+	Kind              string            `json:"kind"`
+	Question          string            `json:"question"`
+	Position          int               `json:"position"`
+	OptionType        models.OptionType `json:"option_type"`
+	TimeLimitDuration int               `json:"time_limit_duration"`
+	Attachment        *models.Upload    `json:"attachment,omitempty"`
+	Options           []PromptOption    `json:"options,omitempty"`
+	Settings          any               `json:"settings,omitempty"`
+}
+
+// AnswerKeyParser is implemented by kinds without options whose answer key
+// fits in a single spreadsheet cell, so that they can be bulk imported.
+type AnswerKeyParser interface {
+	ParseAnswerKey(value string) (json.RawMessage, error)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]Kind)
+)
+
+func Register(kind Kind) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := registry[kind.Slug()]; ok {
+		panic(fmt.Sprintf("questionkind: kind %q registered twice", kind.Slug()))
+	}
+	registry[kind.Slug()] = kind
+}
+
+// Lookup returns the kind registered for a question type slug.
+func Lookup(slug string) (Kind, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	kind, ok := registry[slug]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, slug)
+	}
+	return kind, nil
+}
+
+// ForQuestion returns the kind of a question with its QuestionType loaded.
+func ForQuestion(question *models.Question) (Kind, error) {
+	if question.QuestionType == nil {
+		return nil, ErrUnknownKind
+	}
+	return Lookup(question.QuestionType.Slug)
+}
+
+func Slugs() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	slugs := make([]string, 0, len(registry))
+	for slug := range registry {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+func init() {
+	Register(choiceKind{})
+	Register(trueFalseKind{})
+	Register(typeAnswerKind{})
+	Register(orderingKind{})
+	Register(sliderKind{})
+	Register(pollKind{})
+	Register(wordCloudKind{})
+}
+
+func basePrompt(kind Kind, question *models.Question) *Prompt {
+	prompt := &Prompt{
+		ID:                question.ID,
+		Kind:              kind.Slug(),
+		Question:          question.Question,
+		Position:          question.Position,
+		OptionType:        question.OptionType,
+		TimeLimitDuration: question.TimeLimitDuration,
+		Attachment:        question.Attachment,
+	}
+
+	for _, option := range question.QuestionOptions {
+		prompt.Options = append(prompt.Options, PromptOption{ID: option.ID, Option: option.Option})
+	}
+	return prompt
+}
+
+func decodeSettings(question *models.Question, v any) error {
+	if len(question.Settings) == 0 {
+		return errors.New("the question settings are missing")
+	}
+
+	if err := json.Unmarshal(question.Settings, v); err != nil {
+		return fmt.Errorf("the question settings are invalid: %w", err)
+	}
+	return nil
+}
+
+func validateOptions(question *models.Question, min int) error {
+	if len(question.QuestionOptions) < min {
+		return fmt.Errorf("at least %d options are required", min)
+	}
+
+	if len(question.QuestionOptions) > maxOptions {
+		return fmt.Errorf("at most %d options are allowed", maxOptions)
+	}
+
+	for _, option := range question.QuestionOptions {
+		if option.Option == "" {
+			return errors.New("options cannot be empty")
+		}
+	}
+	return nil
+}
+
+func validateNoOptions(question *models.Question) error {
+	if len(question.QuestionOptions) > 0 {
+		return errors.New("this question type does not take options")
+	}
+	return nil
+}
+
+func validateSingleChoice(question *models.Question) error {
+	if question.OptionType != models.OptionTypeSingleChoice {
+		return errors.New("this question type only supports single_choice")
+	}
+	return nil
+}
+
+// checkOptionIDs ensures every submitted id belongs to the question and is
+// only submitted once.
+func checkOptionIDs(question *models.Question, ids []string) error {
+	valid := make(map[string]bool, len(question.QuestionOptions))
+	for _, option := range question.QuestionOptions {
+		valid[option.ID] = true
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if !valid[id] || seen[id] {
+			return ErrInvalidAnswer
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func correctOptionIDs(question *models.Question) []string {
+	ids := []string{}
+	for _, option := range question.QuestionOptions {
+		if option.IsCorrect {
+			ids = append(ids, option.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,90 @@
+package questionkind
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+// orderingKind asks players to put the options in order. The correct order
+// is the order of the options' positions.
+type orderingKind struct{}
+
+func (orderingKind) Slug() string     { return SlugOrdering }
+func (orderingKind) Scored() bool     { return true }
+func (orderingKind) HasOptions() bool { return true }
+
+func (orderingKind) Validate(question *models.Question) error {
+	if err := validateOptions(question, 2); err != nil {
+		return err
+	}
+
+	if err := validateSingleChoice(question); err != nil {
+		return err
+	}
+
+	positions := make(map[int]bool)
+	for _, option := range question.QuestionOptions {
+		if positions[option.Position] {
+			return errors.New("each option must have a distinct position")
+		}
+		positions[option.Position] = true
+	}
+	return nil
+}
+
+func (orderingKind) CheckAnswer(question *models.Question, answer *Answer) error {
+	if len(answer.OptionIDs) != len(question.QuestionOptions) {
+		return ErrInvalidAnswer
+	}
+	return checkOptionIDs(question, answer.OptionIDs)
+}
+
+// Score awards credit for every option placed in its correct position.
+func (orderingKind) Score(question *models.Question, answer *Answer) Result {
+	order := correctOrder(question)
+	if len(order) == 0 {
+		return Result{}
+	}
+
+	placed := 0
+	for i, id := range answer.OptionIDs {
+		if i < len(order) && order[i] == id {
+			placed++
+		}
+	}
+
+	return Result{
+		Correct: placed == len(order),
+		Credit:  float64(placed) / float64(len(order)),
+	}
+}
+
+// Prompt shuffles the options so that their stored order, which is the
+// answer, is not given away.
+func (k orderingKind) Prompt(question *models.Question) *Prompt {
+	prompt := basePrompt(k, question)
+	rand.Shuffle(len(prompt.Options), func(i, j int) {
+		prompt.Options[i], prompt.Options[j] = prompt.Options[j], prompt.Options[i]
+	})
+	return prompt
+}
+
+func (orderingKind) Reveal(question *models.Question) any {
+	return map[string]any{"option_ids": correctOrder(question)}
+}
+
+func correctOrder(question *models.Question) []string {
+	options := slices.Clone(question.QuestionOptions)
+	slices.SortStableFunc(options, func(a, b models.QuestionOption) int {
+		return a.Position - b.Position
+	})
+
+	ids := make([]string, 0, len(options))
+	for _, option := range options {
+		ids = append(ids, option.ID)
+	}
+	return ids
+}
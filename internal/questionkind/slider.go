@@ -0,0 +1,127 @@
+package questionkind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+type SliderSettings struct {
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Step      float64  `json:"step,omitempty"`
+	Answer    float64  `json:"answer"`
+	Tolerance float64  `json:"tolerance"`
+}
+
+// sliderKind asks for a number, accepting anything within the tolerance
+// of the answer.
+type sliderKind struct{}
+
+func (sliderKind) Slug() string     { return SlugSlider }
+func (sliderKind) Scored() bool     { return true }
+func (sliderKind) HasOptions() bool { return false }
+
+func (sliderKind) Validate(question *models.Question) error {
+	if err := validateNoOptions(question); err != nil {
+		return err
+	}
+
+	if err := validateSingleChoice(question); err != nil {
+		return err
+	}
+
+	var settings SliderSettings
+	if err := decodeSettings(question, &settings); err != nil {
+		return err
+	}
+
+	if settings.Tolerance < 0 {
+		return errors.New("the tolerance cannot be negative")
+	}
+
+	if settings.Step < 0 {
+		return errors.New("the step cannot be negative")
+	}
+
+	if settings.Min != nil && settings.Max != nil && *settings.Min >= *settings.Max {
+		return errors.New("the minimum must be less than the maximum")
+	}
+
+	if !settings.inRange(settings.Answer) {
+		return errors.New("the answer must be between the minimum and maximum")
+	}
+	return nil
+}
+
+func (sliderKind) CheckAnswer(question *models.Question, answer *Answer) error {
+	if answer.Number == nil || math.IsNaN(*answer.Number) || math.IsInf(*answer.Number, 0) {
+		return ErrInvalidAnswer
+	}
+
+	var settings SliderSettings
+	if err := decodeSettings(question, &settings); err != nil || !settings.inRange(*answer.Number) {
+		return ErrInvalidAnswer
+	}
+	return nil
+}
+
+func (sliderKind) Score(question *models.Question, answer *Answer) Result {
+	var settings SliderSettings
+	if decodeSettings(question, &settings) != nil || answer.Number == nil {
+		return Result{}
+	}
+
+	if math.Abs(*answer.Number-settings.Answer) <= settings.Tolerance {
+		return Result{Correct: true, Credit: 1}
+	}
+	return Result{}
+}
+
+func (k sliderKind) Prompt(question *models.Question) *Prompt {
+	var settings SliderSettings
+	_ = decodeSettings(question, &settings)
+
+	prompt := basePrompt(k, question)
+	prompt.Settings = map[string]any{"min": settings.Min, "max": settings.Max, "step": settings.Step}
+	return prompt
+}
+
+func (sliderKind) Reveal(question *models.Question) any {
+	var settings SliderSettings
+	_ = decodeSettings(question, &settings)
+	return map[string]any{"answer": settings.Answer, "tolerance": settings.Tolerance}
+}
+
+// ParseAnswerKey reads an answer with an optional tolerance such as "42",
+// "42±2" or "42 +- 2".
+func (sliderKind) ParseAnswerKey(value string) (json.RawMessage, error) {
+	value = strings.ReplaceAll(value, "+-", "±")
+	answerPart, tolerancePart, hasTolerance := strings.Cut(value, "±")
+
+	var settings SliderSettings
+	var err error
+
+	settings.Answer, err = strconv.ParseFloat(strings.TrimSpace(answerPart), 64)
+	if err != nil {
+		return nil, fmt.Errorf("answer %q must be a number", value)
+	}
+
+	if hasTolerance {
+		settings.Tolerance, err = strconv.ParseFloat(strings.TrimSpace(tolerancePart), 64)
+		if err != nil || settings.Tolerance < 0 {
+			return nil, fmt.Errorf("tolerance %q must be a positive number", tolerancePart)
+		}
+	}
+
+	return json.Marshal(settings)
+}
+
+func (s SliderSettings) inRange(n float64) bool {
+	return (s.Min == nil || n >= *s.Min) && (s.Max == nil || n <= *s.Max)
+}
@@ -0,0 +1,88 @@
+package questionkind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+type TrueFalseSettings struct {
+	Answer *bool `json:"answer"`
+}
+
+type trueFalseKind struct{}
+
+func (trueFalseKind) Slug() string     { return SlugTrueFalse }
+func (trueFalseKind) Scored() bool     { return true }
+func (trueFalseKind) HasOptions() bool { return false }
+
+func (trueFalseKind) Validate(question *models.Question) error {
+	if err := validateNoOptions(question); err != nil {
+		return err
+	}
+
+	if err := validateSingleChoice(question); err != nil {
+		return err
+	}
+
+	var settings TrueFalseSettings
+	if err := decodeSettings(question, &settings); err != nil {
+		return err
+	}
+
+	if settings.Answer == nil {
+		return errors.New("the answer must be true or false")
+	}
+	return nil
+}
+
+func (trueFalseKind) CheckAnswer(_ *models.Question, answer *Answer) error {
+	if answer.Boolean == nil {
+		return ErrInvalidAnswer
+	}
+	return nil
+}
+
+func (trueFalseKind) Score(question *models.Question, answer *Answer) Result {
+	var settings TrueFalseSettings
+	if decodeSettings(question, &settings) != nil || settings.Answer == nil || answer.Boolean == nil {
+		return Result{}
+	}
+
+	if *settings.Answer == *answer.Boolean {
+		return Result{Correct: true, Credit: 1}
+	}
+	return Result{}
+}
+
+func (k trueFalseKind) Prompt(question *models.Question) *Prompt {
+	return basePrompt(k, question)
+}
+
+func (trueFalseKind) Reveal(question *models.Question) any {
+	var settings TrueFalseSettings
+	_ = decodeSettings(question, &settings)
+	return settings
+}
+
+func (trueFalseKind) ParseAnswerKey(value string) (json.RawMessage, error) {
+	var answer bool
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y":
+		answer = true
+	case "no", "n":
+		answer = false
+	default:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("answer %q must be true or false", value)
+		}
+		answer = parsed
+	}
+
+	return json.Marshal(TrueFalseSettings{Answer: &answer})
+}
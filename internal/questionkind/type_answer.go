@@ -0,0 +1,157 @@
+package questionkind
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+const maxAnswerLength = 100
+
+type TypeAnswerSettings struct {
+	AcceptedAnswers []string `json:"accepted_answers"`
+	// Fuzzy tolerates small typos, allowing more mistakes in longer
+	// answers. See allowedTypos.
+	Fuzzy bool `json:"fuzzy"`
+}
+
+type typeAnswerKind struct{}
+
+func (typeAnswerKind) Slug() string     { return SlugTypeAnswer }
+func (typeAnswerKind) Scored() bool     { return true }
+func (typeAnswerKind) HasOptions() bool { return false }
+
+func (typeAnswerKind) Validate(question *models.Question) error {
+	if err := validateNoOptions(question); err != nil {
+		return err
+	}
+
+	if err := validateSingleChoice(question); err != nil {
+		return err
+	}
+
+	var settings TypeAnswerSettings
+	if err := decodeSettings(question, &settings); err != nil {
+		return err
+	}
+
+	if len(settings.AcceptedAnswers) == 0 {
+		return errors.New("at least one accepted answer is required")
+	}
+
+	for _, answer := range settings.AcceptedAnswers {
+		if normalizeText(answer) == "" {
+			return errors.New("accepted answers cannot be empty")
+		}
+		if len(answer) > maxAnswerLength {
+			return errors.New("accepted answers must be at most 100 characters")
+		}
+	}
+	return nil
+}
+
+func (typeAnswerKind) CheckAnswer(_ *models.Question, answer *Answer) error {
+	if normalizeText(answer.Text) == "" || len(answer.Text) > maxAnswerLength {
+		return ErrInvalidAnswer
+	}
+	return nil
+}
+
+func (typeAnswerKind) Score(question *models.Question, answer *Answer) Result {
+	var settings TypeAnswerSettings
+	if decodeSettings(question, &settings) != nil {
+		return Result{}
+	}
+
+	given := normalizeText(answer.Text)
+	for _, accepted := range settings.AcceptedAnswers {
+		accepted = normalizeText(accepted)
+		if given == accepted {
+			return Result{Correct: true, Credit: 1}
+		}
+
+		if settings.Fuzzy && levenshtein(given, accepted) <= allowedTypos(accepted) {
+			return Result{Correct: true, Credit: 1}
+		}
+	}
+	return Result{}
+}
+
+func (k typeAnswerKind) Prompt(question *models.Question) *Prompt {
+	return basePrompt(k, question)
+}
+
+func (typeAnswerKind) Reveal(question *models.Question) any {
+	var settings TypeAnswerSettings
+	_ = decodeSettings(question, &settings)
+	return map[string]any{"accepted_answers": settings.AcceptedAnswers}
+}
+
+// ParseAnswerKey reads accepted answers separated by "|" or ";". Fuzzy
+// matching is enabled for imported questions.
+func (typeAnswerKind) ParseAnswerKey(value string) (json.RawMessage, error) {
+	settings := TypeAnswerSettings{AcceptedAnswers: []string{}, Fuzzy: true}
+	for _, answer := range strings.FieldsFunc(value, func(r rune) bool { return r == '|' || r == ';' }) {
+		if answer = strings.TrimSpace(answer); answer != "" {
+			settings.AcceptedAnswers = append(settings.AcceptedAnswers, answer)
+		}
+	}
+
+	if len(settings.AcceptedAnswers) == 0 {
+		return nil, errors.New("at least one accepted answer is required")
+	}
+	return json.Marshal(settings)
+}
+
+// normalizeText lowercases s, drops punctuation and collapses whitespace so
+// that "The Beatles!" matches "the  beatles".
+func normalizeText(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func allowedTypos(answer string) int {
+	switch n := len([]rune(answer)); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
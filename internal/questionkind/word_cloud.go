@@ -0,0 +1,43 @@
+package questionkind
+
+import (
+	"strings"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+)
+
+const maxWordCloudLength = 40
+
+// wordCloudKind collects short free text responses without scoring them.
+type wordCloudKind struct{}
+
+func (wordCloudKind) Slug() string     { return SlugWordCloud }
+func (wordCloudKind) Scored() bool     { return false }
+func (wordCloudKind) HasOptions() bool { return false }
+
+func (wordCloudKind) Validate(question *models.Question) error {
+	if err := validateNoOptions(question); err != nil {
+		return err
+	}
+	return validateSingleChoice(question)
+}
+
+func (wordCloudKind) CheckAnswer(_ *models.Question, answer *Answer) error {
+	text := strings.TrimSpace(answer.Text)
+	if text == "" || len(text) > maxWordCloudLength {
+		return ErrInvalidAnswer
+	}
+	return nil
+}
+
+func (wordCloudKind) Score(*models.Question, *Answer) Result {
+	return Result{}
+}
+
+func (k wordCloudKind) Prompt(question *models.Question) *Prompt {
+	return basePrompt(k, question)
+}
+
+func (wordCloudKind) Reveal(*models.Question) any {
+	return nil
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryBucket struct {
+	bucket
+	// full is when the bucket will have refilled, from then on it is the
+	// same as a new one and can be forgotten.
+	full time.Time
+}
+
+type memoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewMemoryLimiter returns a limiter keeping its buckets in this process,
+// each instance limits on its own.
+func NewMemoryLimiter() Limiter {
+	m := &memoryLimiter{
+		buckets: make(map[string]*memoryBucket),
+		stop:    make(chan struct{}),
+	}
+
+	go m.sweep()
+	return m
+}
+
+func (m *memoryLimiter) Take(_ context.Context, policy Policy, key string, cost int) (Result, error) {
+	now := time.Now()
+	key = bucketKey(policy, key)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		m.buckets[key] = b
+	}
+
+	result := b.take(policy, cost, now)
+	b.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+func (m *memoryLimiter) Refund(_ context.Context, policy Policy, key string, cost int) error {
+	key = bucketKey(policy, key)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// a bucket that is gone has refilled already
+	if b, ok := m.buckets[key]; ok {
+		b.refund(policy, cost)
+	}
+
+	return nil
+}
+
+func (m *memoryLimiter) Close() error {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+	return nil
+}
+
+func (m *memoryLimiter) sweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.mu.Lock()
+			for key, b := range m.buckets {
+				if now.After(b.full) {
+					delete(m.buckets, key)
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
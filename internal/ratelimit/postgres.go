@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type postgresLimiter struct {
+	db   *bun.DB
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewPostgresLimiter returns a limiter whose buckets are shared by every
+// instance connected to the same database. Each take locks its bucket row,
+// so it suits limits on a handful of routes better than on every request
+// of a busy deployment.
+func NewPostgresLimiter(db *bun.DB) Limiter {
+	p := &postgresLimiter{
+		db:   db,
+		stop: make(chan struct{}),
+	}
+
+	go p.sweep()
+	return p
+}
+
+func (p *postgresLimiter) Take(ctx context.Context, policy Policy, key string, cost int) (Result, error) {
+	now := time.Now()
+	key = bucketKey(policy, key)
+
+	var result Result
+	err := p.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewRaw(
+			"INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at) VALUES (?, ?, ?, ?) ON CONFLICT (key) DO NOTHING",
+			key, policy.Limit, now, now).Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		var b bucket
+		err = tx.NewRaw("SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = ? FOR UPDATE", key).
+			Scan(ctx, &b.tokens, &b.updated)
+		if err != nil {
+			return err
+		}
+
+		result = b.take(policy, cost, now)
+
+		_, err = tx.NewRaw("UPDATE rate_limit_buckets SET tokens = ?, updated_at = ?, expires_at = ? WHERE key = ?",
+			b.tokens, b.updated, now.Add(result.Reset), key).Exec(ctx)
+		return err
+	})
+
+	return result, err
+}
+
+func (p *postgresLimiter) Refund(ctx context.Context, policy Policy, key string, cost int) error {
+	_, err := p.db.NewRaw("UPDATE rate_limit_buckets SET tokens = LEAST(?, tokens + ?) WHERE key = ?",
+		policy.Limit, cost, bucketKey(policy, key)).Exec(ctx)
+	return err
+}
+
+func (p *postgresLimiter) Close() error {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+	return nil
+}
+
+// sweep deletes the buckets that have filled up again. Every instance
+// sweeps, deleting a row twice does no harm.
+func (p *postgresLimiter) sweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), sweepInterval)
+			_, err := p.db.NewRaw("DELETE FROM rate_limit_buckets WHERE expires_at < ?", time.Now()).Exec(ctx)
+			cancel()
+			if err != nil {
+				slog.Error("[ratelimit]: could not sweep buckets", slog.Any("error", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/oxiginedev/sabipass/config"
+	"github.com/uptrace/bun"
+)
+
+// sweepInterval is how often buckets that have filled up again are
+// forgotten.
+const sweepInterval = time.Minute
+
+// Policy is a token bucket holding up to Limit tokens, refilled at a
+// steady pace so an empty bucket is full again after Period.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// PerMinute returns a policy allowing limit requests a minute, with bursts
+// of up to limit.
+func PerMinute(name string, limit int) Policy {
+	return Policy{Name: name, Limit: limit, Period: time.Minute}
+}
+
+// Enabled reports whether the policy limits anything.
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Period > 0
+}
+
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Result tells how a take went and how the bucket stands after it.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the take would be allowed, zero when it
+	// was.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Limiter keeps a bucket per policy and key.
+type Limiter interface {
+	// Take removes cost tokens from the bucket of key under policy when it
+	// holds enough. A cost of 0 takes nothing, it only checks a token is
+	// left.
+	Take(ctx context.Context, policy Policy, key string, cost int) (Result, error)
+	// Refund puts cost tokens back into the bucket of key under policy,
+	// for takes that turned out not to count. The bucket never holds more
+	// than the policy's limit.
+	Refund(ctx context.Context, policy Policy, key string, cost int) error
+	Close() error
+}
+
+// New returns the limiter for the configured driver.
+func New(cfg *config.Config, db *bun.DB) (Limiter, error) {
+	switch cfg.RateLimit.Driver {
+	case config.RateLimitDriverMemory:
+		return NewMemoryLimiter(), nil
+	case config.RateLimitDriverPostgres:
+		return NewPostgresLimiter(db), nil
+	default:
+		return nil, fmt.Errorf("ratelimit: unknown driver %q", cfg.RateLimit.Driver)
+	}
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// take refills the bucket for the time passed since it was last updated,
+// then takes cost tokens out of it if it can.
+func (b *bucket) take(policy Policy, cost int, now time.Time) Result {
+	limit := float64(policy.Limit)
+	rate := policy.rate()
+
+	if b.updated.IsZero() {
+		b.tokens = limit
+	} else if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(limit, b.tokens+elapsed*rate)
+	}
+	b.updated = now
+
+	result := Result{Limit: policy.Limit}
+
+	need := math.Max(float64(cost), 1)
+	if b.tokens >= need {
+		b.tokens -= float64(cost)
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((need - b.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = seconds((limit - b.tokens) / rate)
+
+	return result
+}
+
+// refund puts cost tokens back, without going over the limit.
+func (b *bucket) refund(policy Policy, cost int) {
+	b.tokens = math.Min(float64(policy.Limit), b.tokens+float64(cost))
+}
+
+func bucketKey(policy Policy, key string) string {
+	return policy.Name + ":" + key
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
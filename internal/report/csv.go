@@ -0,0 +1,71 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var csvHeader = []string{
+	"rank", "nickname", "score", "correct_count",
+	"question_position", "question", "answer", "correct", "points", "timed_out", "answer_seconds",
+}
+
+// WriteCSV writes a row for every answer of every player, players without
+// answers get a single row with their totals.
+func (r *Report) WriteCSV(w io.Writer) error {
+	questions := make(map[string]QuestionStats, len(r.Questions))
+	for _, stats := range r.Questions {
+		questions[stats.QuestionID] = stats
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, player := range r.Players {
+		totals := []string{
+			strconv.Itoa(player.Rank),
+			csvText(player.Nickname),
+			strconv.Itoa(player.Score),
+			strconv.Itoa(player.CorrectCount),
+		}
+
+		if len(player.Answers) == 0 {
+			if err := cw.Write(append(totals, "", "", "", "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, answer := range player.Answers {
+			question := questions[answer.QuestionID]
+			row := append(totals[:len(totals):len(totals)],
+				strconv.Itoa(question.Position),
+				csvText(question.Question),
+				csvText(string(answer.Answer)),
+				strconv.FormatBool(answer.Correct),
+				strconv.Itoa(answer.Points),
+				strconv.FormatBool(answer.TimedOut),
+				strconv.FormatFloat(answer.AnswerSeconds, 'f', 2, 64),
+			)
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText keeps text typed by players and hosts from being run as a
+// formula when the file is opened in a spreadsheet.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
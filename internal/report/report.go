@@ -0,0 +1,290 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+)
+
+var ErrNotFinished = errors.New("report: the game has not finished yet")
+
+type QuestionStats struct {
+	QuestionID   string `json:"question_id"`
+	Position     int    `json:"position"`
+	Question     string `json:"question"`
+	Kind         string `json:"kind"`
+	AnswerCount  int    `json:"answer_count"`
+	CorrectCount int    `json:"correct_count"`
+	// CorrectRate is nil for kinds that are not scored, such as polls.
+	CorrectRate          *float64 `json:"correct_rate"`
+	AverageAnswerSeconds float64  `json:"average_answer_seconds"`
+}
+
+type PlayerAnswer struct {
+	QuestionID    string          `json:"question_id"`
+	Answer        json.RawMessage `json:"answer"`
+	Correct       bool            `json:"correct"`
+	Points        int             `json:"points"`
+	TimedOut      bool            `json:"timed_out"`
+	AnswerSeconds float64         `json:"answer_seconds"`
+}
+
+type PlayerReport struct {
+	models.LeaderboardEntry
+	Answers []PlayerAnswer `json:"answers"`
+}
+
+// Report is the review of a finished game.
+type Report struct {
+	SessionID       string                        `json:"session_id"`
+	Code            string                        `json:"code"`
+	Mode            models.GameMode               `json:"mode"`
+	QuizTitle       string                        `json:"quiz_title"`
+	FinishedAt      time.Time                     `json:"finished_at"`
+	Questions       []QuestionStats               `json:"questions"`
+	Hardest         *QuestionStats                `json:"hardest_question"`
+	Players         []PlayerReport                `json:"players"`
+	TeamLeaderboard []models.TeamLeaderboardEntry `json:"team_leaderboard,omitempty"`
+}
+
+// Reporter builds the reports of finished games and keeps their summaries.
+type Reporter struct {
+	quizRepo        models.QuizRepository
+	participantRepo models.GameParticipantRepository
+	answerRepo      models.GameAnswerRepository
+	summaryRepo     models.GameSummaryRepository
+}
+
+func NewReporter(quizRepo models.QuizRepository,
+	participantRepo models.GameParticipantRepository,
+	answerRepo models.GameAnswerRepository,
+	summaryRepo models.GameSummaryRepository,
+) *Reporter {
+	return &Reporter{
+		quizRepo:        quizRepo,
+		participantRepo: participantRepo,
+		answerRepo:      answerRepo,
+		summaryRepo:     summaryRepo,
+	}
+}
+
+// FinishedAt returns when the session was over, live games when they
+// finished and challenges when they closed.
+func FinishedAt(session *models.GameSession, now time.Time) (time.Time, bool) {
+	switch {
+	case session.Mode == models.GameModeLive:
+		if session.Phase != models.GamePhaseFinished || session.PhaseStartedAt == nil {
+			return time.Time{}, false
+		}
+		return *session.PhaseStartedAt, true
+	case session.IsOpen(now):
+		return time.Time{}, false
+	case session.ClosesAt != nil && session.ClosesAt.Before(now):
+		return *session.ClosesAt, true
+	default:
+		return session.UpdatedAt, true
+	}
+}
+
+// Build reports on a finished game.
+func (r *Reporter) Build(ctx context.Context, session *models.GameSession) (*Report, error) {
+	finishedAt, ok := FinishedAt(session, time.Now())
+	if !ok {
+		return nil, ErrNotFinished
+	}
+
+	quiz, err := r.quizRepo.FindOne(ctx, &models.FindQuizOptions{ID: session.QuizID})
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard, err := r.participantRepo.Leaderboard(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := r.answerRepo.FindAll(ctx, &models.FindGameAnswerOptions{SessionID: session.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		SessionID:  session.ID,
+		Code:       session.Code,
+		Mode:       session.Mode,
+		QuizTitle:  quiz.Title,
+		FinishedAt: finishedAt,
+		Questions:  make([]QuestionStats, 0, len(quiz.Questions)),
+		Players:    make([]PlayerReport, 0, len(leaderboard)),
+	}
+
+	players := make(map[string]int, len(leaderboard))
+	for i, entry := range leaderboard {
+		players[entry.ParticipantID] = i
+		report.Players = append(report.Players, PlayerReport{
+			LeaderboardEntry: entry,
+			Answers:          []PlayerAnswer{},
+		})
+	}
+
+	byQuestion := make(map[string][]models.GameAnswer)
+	for _, answer := range answers {
+		// answers of banned players stay out like the players themselves
+		i, ok := players[answer.ParticipantID]
+		if !ok || answer.AnsweredAt == nil {
+			continue
+		}
+
+		byQuestion[answer.QuestionID] = append(byQuestion[answer.QuestionID], answer)
+		report.Players[i].Answers = append(report.Players[i].Answers, PlayerAnswer{
+			QuestionID:    answer.QuestionID,
+			Answer:        answer.Answer,
+			Correct:       answer.Correct,
+			Points:        answer.Points,
+			TimedOut:      answer.TimedOut,
+			AnswerSeconds: answerSeconds(&answer),
+		})
+	}
+
+	for i := range quiz.Questions {
+		question := &quiz.Questions[i]
+		stats := questionStats(question, byQuestion[question.ID])
+		report.Questions = append(report.Questions, stats)
+	}
+	report.Hardest = hardest(report.Questions)
+
+	if session.HasTeams() {
+		report.TeamLeaderboard = game.TeamLeaderboard(session.Teams, leaderboard, session.TeamScoring)
+	}
+
+	return report, nil
+}
+
+// Summarize builds the report of a finished game and saves its summary.
+// Challenges close without anyone finishing them, so their summary is
+// saved again along with every report.
+func (r *Reporter) Summarize(ctx context.Context, session *models.GameSession) (*Report, error) {
+	report, err := r.Build(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.summaryRepo.Save(ctx, report.Summary()); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (r *Report) Summary() *models.GameSummary {
+	now := time.Now()
+	summary := &models.GameSummary{
+		SessionID:     r.SessionID,
+		QuizTitle:     r.QuizTitle,
+		PlayerCount:   len(r.Players),
+		QuestionCount: len(r.Questions),
+		FinishedAt:    r.FinishedAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	answered, correct := 0, 0
+	for _, stats := range r.Questions {
+		summary.AnswerCount += stats.AnswerCount
+		if stats.CorrectRate != nil {
+			answered += stats.AnswerCount
+			correct += stats.CorrectCount
+		}
+	}
+	if answered > 0 {
+		summary.CorrectRate = float64(correct) / float64(answered)
+	}
+
+	total := 0
+	for _, player := range r.Players {
+		total += player.Score
+	}
+	if len(r.Players) > 0 {
+		winner := r.Players[0]
+		summary.AverageScore = float64(total) / float64(len(r.Players))
+		summary.WinnerID = &winner.ParticipantID
+		summary.WinnerNickname = winner.Nickname
+		summary.WinnerScore = winner.Score
+	}
+
+	return summary
+}
+
+func questionStats(question *models.Question, answers []models.GameAnswer) QuestionStats {
+	stats := QuestionStats{
+		QuestionID:  question.ID,
+		Position:    question.Position,
+		Question:    question.Question,
+		AnswerCount: len(answers),
+	}
+
+	kind, err := questionkind.ForQuestion(question)
+	if err == nil {
+		stats.Kind = kind.Slug()
+	}
+
+	var seconds float64
+	timed := 0
+	for i := range answers {
+		if answers[i].Correct {
+			stats.CorrectCount++
+		}
+		if !answers[i].TimedOut {
+			seconds += answerSeconds(&answers[i])
+			timed++
+		}
+	}
+
+	if timed > 0 {
+		stats.AverageAnswerSeconds = seconds / float64(timed)
+	}
+
+	if kind != nil && kind.Scored() && stats.AnswerCount > 0 {
+		rate := float64(stats.CorrectCount) / float64(stats.AnswerCount)
+		stats.CorrectRate = &rate
+	}
+
+	return stats
+}
+
+// hardest picks the scored question the fewest players got right, the one
+// that took longest to answer breaking ties.
+func hardest(questions []QuestionStats) *QuestionStats {
+	candidates := make([]QuestionStats, 0, len(questions))
+	for _, stats := range questions {
+		if stats.CorrectRate != nil {
+			candidates = append(candidates, stats)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if *candidates[i].CorrectRate != *candidates[j].CorrectRate {
+			return *candidates[i].CorrectRate < *candidates[j].CorrectRate
+		}
+		return candidates[i].AverageAnswerSeconds > candidates[j].AverageAnswerSeconds
+	})
+
+	return &candidates[0]
+}
+
+func answerSeconds(answer *models.GameAnswer) float64 {
+	if answer.AnsweredAt == nil {
+		return 0
+	}
+	return answer.AnsweredAt.Sub(answer.StartedAt).Seconds()
+}
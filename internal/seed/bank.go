@@ -0,0 +1,234 @@
+package seed
+
+import (
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+	"github.com/oxiginedev/sabipass/utils"
+)
+
+type bankOption struct {
+	text    string
+	correct bool
+}
+
+// bankQuestion is a demo question. Ordering options are listed in their
+// correct order. responses holds what players type: the wrong answers of
+// type answer questions and the words of word clouds.
+type bankQuestion struct {
+	kind       string
+	question   string
+	limit      int
+	optionType models.OptionType
+	options    []bankOption
+	settings   any
+	responses  []string
+}
+
+type bankQuiz struct {
+	title       string
+	description string
+	questions   []bankQuestion
+}
+
+// bank holds the demo quizzes, between them they use every question kind.
+var bank = []bankQuiz{
+	{
+		title:       "Naija General Knowledge",
+		description: "How well do you know Nigeria?",
+		questions: []bankQuestion{
+			{
+				kind:     questionkind.SlugQuiz,
+				question: "What is the capital of Nigeria?",
+				limit:    20,
+				options: []bankOption{
+					{text: "Lagos"},
+					{text: "Abuja", correct: true},
+					{text: "Kano"},
+					{text: "Ibadan"},
+				},
+			},
+			{
+				kind:     questionkind.SlugTrueFalse,
+				question: "Nigeria gained independence in 1960.",
+				limit:    15,
+				settings: questionkind.TrueFalseSettings{Answer: utils.Ptr(true)},
+			},
+			{
+				kind:     questionkind.SlugTypeAnswer,
+				question: "Which river gives Nigeria its name?",
+				limit:    30,
+				settings: questionkind.TypeAnswerSettings{
+					AcceptedAnswers: []string{"Niger", "River Niger"},
+					Fuzzy:           true,
+				},
+				responses: []string{"Benue", "Nile", "Ogun", "Cross River"},
+			},
+			{
+				kind:       questionkind.SlugQuiz,
+				question:   "Which of these are Nigerian states?",
+				limit:      30,
+				optionType: models.OptionTypeMultipleChoice,
+				options: []bankOption{
+					{text: "Enugu", correct: true},
+					{text: "Kaduna", correct: true},
+					{text: "Accra"},
+					{text: "Ekiti", correct: true},
+				},
+			},
+			{
+				kind:     questionkind.SlugSlider,
+				question: "How many states does Nigeria have?",
+				limit:    20,
+				settings: questionkind.SliderSettings{
+					Min:    utils.Ptr(20.0),
+					Max:    utils.Ptr(50.0),
+					Step:   1,
+					Answer: 36,
+				},
+			},
+			{
+				kind:     questionkind.SlugOrdering,
+				question: "Put these cities in order of population, largest first.",
+				limit:    40,
+				options: []bankOption{
+					{text: "Lagos"},
+					{text: "Kano"},
+					{text: "Ibadan"},
+					{text: "Port Harcourt"},
+				},
+			},
+			{
+				kind:     questionkind.SlugPoll,
+				question: "Which jollof is the best?",
+				limit:    20,
+				options: []bankOption{
+					{text: "Nigerian"},
+					{text: "Ghanaian"},
+					{text: "Senegalese"},
+				},
+			},
+		},
+	},
+	{
+		title:       "Science Basics",
+		description: "A warm up on everyday science.",
+		questions: []bankQuestion{
+			{
+				kind:     questionkind.SlugQuiz,
+				question: "What gas do plants take in from the air?",
+				limit:    20,
+				options: []bankOption{
+					{text: "Oxygen"},
+					{text: "Nitrogen"},
+					{text: "Carbon dioxide", correct: true},
+					{text: "Helium"},
+				},
+			},
+			{
+				kind:     questionkind.SlugTrueFalse,
+				question: "Sound travels faster than light.",
+				limit:    15,
+				settings: questionkind.TrueFalseSettings{Answer: utils.Ptr(false)},
+			},
+			{
+				kind:     questionkind.SlugSlider,
+				question: "At what temperature in °C does water boil at sea level?",
+				limit:    20,
+				settings: questionkind.SliderSettings{
+					Min:       utils.Ptr(0.0),
+					Max:       utils.Ptr(200.0),
+					Step:      1,
+					Answer:    100,
+					Tolerance: 5,
+				},
+			},
+			{
+				kind:     questionkind.SlugTypeAnswer,
+				question: "What is the chemical symbol for gold?",
+				limit:    20,
+				settings: questionkind.TypeAnswerSettings{
+					AcceptedAnswers: []string{"Au"},
+				},
+				responses: []string{"Ag", "Go", "Gd", "Fe"},
+			},
+			{
+				kind:     questionkind.SlugOrdering,
+				question: "Order the planets from closest to the sun.",
+				limit:    40,
+				options: []bankOption{
+					{text: "Mercury"},
+					{text: "Venus"},
+					{text: "Earth"},
+					{text: "Mars"},
+				},
+			},
+			{
+				kind:      questionkind.SlugWordCloud,
+				question:  "In one word, what does science mean to you?",
+				limit:     30,
+				responses: []string{"curiosity", "discovery", "experiments", "truth", "questions", "lab", "nature", "maths"},
+			},
+		},
+	},
+	{
+		title:       "Football Fever",
+		description: "For those who never miss a match.",
+		questions: []bankQuestion{
+			{
+				kind:     questionkind.SlugQuiz,
+				question: "What is the nickname of the Nigerian national team?",
+				limit:    20,
+				options: []bankOption{
+					{text: "Black Stars"},
+					{text: "Super Eagles", correct: true},
+					{text: "Indomitable Lions"},
+					{text: "Pharaohs"},
+				},
+			},
+			{
+				kind:     questionkind.SlugSlider,
+				question: "How many players does a football team have on the pitch?",
+				limit:    15,
+				settings: questionkind.SliderSettings{
+					Min:    utils.Ptr(5.0),
+					Max:    utils.Ptr(15.0),
+					Step:   1,
+					Answer: 11,
+				},
+			},
+			{
+				kind:     questionkind.SlugTrueFalse,
+				question: "Nigeria won the Olympic football gold in 1996.",
+				limit:    15,
+				settings: questionkind.TrueFalseSettings{Answer: utils.Ptr(true)},
+			},
+			{
+				kind:     questionkind.SlugTypeAnswer,
+				question: "Which country hosts the Premier League?",
+				limit:    20,
+				settings: questionkind.TypeAnswerSettings{
+					AcceptedAnswers: []string{"England"},
+					Fuzzy:           true,
+				},
+				responses: []string{"Spain", "Italy", "Scotland", "France"},
+			},
+			{
+				kind:     questionkind.SlugPoll,
+				question: "Who should take the last penalty?",
+				limit:    20,
+				options: []bankOption{
+					{text: "The captain"},
+					{text: "The striker"},
+					{text: "The goalkeeper"},
+					{text: "Whoever wants it most"},
+				},
+			},
+			{
+				kind:      questionkind.SlugWordCloud,
+				question:  "Describe your team's last season in one word.",
+				limit:     30,
+				responses: []string{"painful", "glorious", "average", "rebuilding", "chaotic", "champions", "meh"},
+			},
+		},
+	},
+}
@@ -0,0 +1,544 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/game"
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+	"github.com/oxiginedev/sabipass/utils"
+	"github.com/uptrace/bun"
+)
+
+const (
+	// demoHistory is how far back the demo games are spread.
+	demoHistory = 30 * 24 * time.Hour
+	// questionGap is the time between questions of a live game, spent on
+	// the reveal and the leaderboard.
+	questionGap = 10 * time.Second
+	// challengeLength is how long demo challenges are open.
+	challengeLength = 48 * time.Hour
+	// timeoutRate is how often a player lets a question run out.
+	timeoutRate = 0.05
+)
+
+var nicknames = []string{
+	"Ada", "Tunde", "Ngozi", "Emeka", "Funke", "Chidi", "Bisi", "Ike",
+	"Amaka", "Seyi", "Kemi", "Obinna", "Zainab", "Musa", "Halima", "Dayo",
+	"Yemi", "Uche", "Femi", "Nneka", "Bola", "Kunle", "Aisha", "Efe",
+}
+
+type DemoOptions struct {
+	Users             int
+	QuizzesPerUser    int
+	SessionsPerQuiz   int
+	PlayersPerSession int
+	// Seed makes demo players pick the same answers on every run.
+	Seed uint64
+}
+
+type DemoResult struct {
+	Users    int
+	Quizzes  int
+	Sessions int
+	Answers  int
+}
+
+// Demo generates demo users, each owning public quizzes from the demo
+// bank that have been played in finished games. Demo users are found by
+// their username and quizzes by their owner and title, so running it
+// again only adds what is missing.
+func (s *Seeder) Demo(ctx context.Context, opts *DemoOptions) (*DemoResult, error) {
+	questionTypes, err := s.questionTypesBySlug(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &demo{
+		Seeder:        s,
+		rng:           rand.New(rand.NewPCG(opts.Seed, opts.Seed)),
+		questionTypes: questionTypes,
+		responses:     make(map[string][]string),
+	}
+	result := &DemoResult{}
+
+	users, err := d.users(ctx, opts.Users, result)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, user := range users {
+		for j := range opts.QuizzesPerUser {
+			template := &bank[(i+j)%len(bank)]
+
+			quiz, err := d.quiz(ctx, &user, template)
+			if err != nil {
+				return nil, err
+			}
+			if quiz == nil {
+				continue
+			}
+			result.Quizzes++
+
+			for range opts.SessionsPerQuiz {
+				answers, err := d.session(ctx, quiz, users, opts.PlayersPerSession)
+				if err != nil {
+					return nil, err
+				}
+				result.Sessions++
+				result.Answers += answers
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// demo is the state of a single Demo run.
+type demo struct {
+	*Seeder
+	rng           *rand.Rand
+	questionTypes map[string]string
+	// responses holds the typed responses of the bank questions by the id
+	// of the question created from them
+	responses map[string][]string
+}
+
+// users returns the demo users, creating the ones that do not exist yet.
+func (d *demo) users(ctx context.Context, count int, result *DemoResult) ([]models.User, error) {
+	usernames := make([]string, 0, count)
+	for i := range count {
+		usernames = append(usernames, fmt.Sprintf("demo_%02d", i+1))
+	}
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	var existing []models.User
+	err := d.db.NewSelect().
+		Model(&existing).
+		Where("username IN (?)", bun.In(usernames)).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byUsername := make(map[string]models.User, len(existing))
+	for _, user := range existing {
+		byUsername[user.Username] = user
+	}
+
+	now := time.Now()
+	users := make([]models.User, 0, count)
+	var created []models.User
+	for i, username := range usernames {
+		user, ok := byUsername[username]
+		if !ok {
+			user = models.User{
+				ID:              utils.Uuid(),
+				Name:            utils.Ptr(nicknames[i%len(nicknames)] + " Demo"),
+				Username:        username,
+				Email:           username + "@sabipass.test",
+				EmailVerifiedAt: utils.Ptr(now),
+				Role:            models.UserRoleUser,
+				Status:          models.UserStatusActive,
+				CreatedAt:       now,
+				UpdatedAt:       now,
+			}
+			created = append(created, user)
+		}
+		users = append(users, user)
+	}
+
+	if len(created) > 0 {
+		if _, err := d.db.NewInsert().Model(&created).Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+	result.Users = len(created)
+
+	return users, nil
+}
+
+// quiz creates a published public quiz for the user from the template,
+// it returns nil when the user already owns it.
+func (d *demo) quiz(ctx context.Context, user *models.User, template *bankQuiz) (*models.Quiz, error) {
+	exists, err := d.db.NewSelect().
+		Model((*models.Quiz)(nil)).
+		Where("owner_id = ?", user.ID).
+		Where("title = ?", template.title).
+		Exists(ctx)
+	if err != nil || exists {
+		return nil, err
+	}
+
+	now := time.Now()
+	createdAt := now.Add(-demoHistory - time.Duration(d.rng.Int64N(int64(demoHistory))))
+
+	quiz := &models.Quiz{
+		ID:          utils.Uuid(),
+		OwnerID:     user.ID,
+		Title:       template.title,
+		Description: utils.Ptr(template.description),
+		Visibility:  models.QuizVisibilityPublic,
+		PublishedAt: utils.Ptr(createdAt),
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+
+	var options []models.QuestionOption
+	for position, bq := range template.questions {
+		question, err := d.question(quiz, position, &bq, createdAt)
+		if err != nil {
+			return nil, err
+		}
+
+		kind, err := questionkind.ForQuestion(question)
+		if err != nil {
+			return nil, err
+		}
+		if err := kind.Validate(question); err != nil {
+			return nil, fmt.Errorf("seed: demo question %q is invalid: %w", bq.question, err)
+		}
+
+		quiz.Questions = append(quiz.Questions, *question)
+		options = append(options, question.QuestionOptions...)
+		d.responses[question.ID] = bq.responses
+	}
+
+	err = d.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(quiz).Exec(ctx); err != nil {
+			return err
+		}
+
+		if _, err := tx.NewInsert().Model(&quiz.Questions).Exec(ctx); err != nil {
+			return err
+		}
+
+		if len(options) == 0 {
+			return nil
+		}
+
+		_, err := tx.NewInsert().Model(&options).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return quiz, nil
+}
+
+func (d *demo) question(quiz *models.Quiz, position int, bq *bankQuestion, now time.Time) (*models.Question, error) {
+	questionTypeID, ok := d.questionTypes[bq.kind]
+	if !ok {
+		return nil, fmt.Errorf("seed: no question type for %s, seed the question types first", bq.kind)
+	}
+
+	question := &models.Question{
+		ID:                utils.Uuid(),
+		QuizID:            quiz.ID,
+		QuestionTypeID:    questionTypeID,
+		Question:          bq.question,
+		TimeLimitDuration: bq.limit,
+		Position:          position,
+		OptionType:        bq.optionType,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		QuestionType:      &models.QuestionType{ID: questionTypeID, Slug: bq.kind},
+	}
+	if question.OptionType == "" {
+		question.OptionType = models.OptionTypeSingleChoice
+	}
+
+	if bq.settings != nil {
+		settings, err := json.Marshal(bq.settings)
+		if err != nil {
+			return nil, err
+		}
+		question.Settings = settings
+	}
+
+	for i, option := range bq.options {
+		question.QuestionOptions = append(question.QuestionOptions, models.QuestionOption{
+			ID:         utils.Uuid(),
+			QuestionID: question.ID,
+			Option:     option.text,
+			IsCorrect:  option.correct,
+			Position:   i,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	return question, nil
+}
+
+// session plays a finished game of the quiz, hosted by its owner, and
+// returns how many answers it recorded. Players are a mix of the other
+// demo users and guests.
+func (d *demo) session(ctx context.Context, quiz *models.Quiz, users []models.User, players int) (int, error) {
+	now := time.Now()
+	// challenges stay open for two days, so games start early enough to be
+	// over by now
+	startedAt := now.Add(-challengeLength - time.Duration(d.rng.Int64N(int64(demoHistory))))
+
+	session := &models.GameSession{
+		ID:        utils.Uuid(),
+		QuizID:    quiz.ID,
+		HostID:    quiz.OwnerID,
+		Mode:      models.GameModeLive,
+		Code:      game.NewJoinCode(),
+		Status:    models.GameSessionStatusClosed,
+		CreatedAt: startedAt.Add(-5 * time.Minute),
+	}
+	if d.rng.IntN(2) == 0 {
+		session.Mode = models.GameModeChallenge
+	}
+
+	participants := make([]models.GameParticipant, 0, players)
+	var answers []models.GameAnswer
+	finishedAt := startedAt
+
+	for i := range players {
+		participant := models.GameParticipant{
+			ID:        utils.Uuid(),
+			SessionID: session.ID,
+			Nickname:  fmt.Sprintf("%s%d", nicknames[i%len(nicknames)], i/len(nicknames)+1),
+			CreatedAt: session.CreatedAt,
+		}
+		_, participant.TokenHash = game.NewParticipantToken()
+
+		// about half the players are signed in demo users, never the host
+		if user := users[d.rng.IntN(len(users))]; user.ID != quiz.OwnerID && d.rng.IntN(2) == 0 && !playing(participants, user.ID) {
+			participant.UserID = utils.Ptr(user.ID)
+		}
+
+		// live players all start together, challengers whenever they like
+		// while it is open
+		playerStart := startedAt
+		if session.Mode == models.GameModeChallenge {
+			playerStart = startedAt.Add(time.Duration(d.rng.Int64N(int64(challengeLength / 2))))
+		}
+
+		played, closedAt, err := d.play(quiz, &participant, playerStart)
+		if err != nil {
+			return 0, err
+		}
+
+		answers = append(answers, played...)
+		participants = append(participants, participant)
+		finishedAt = maxTime(finishedAt, closedAt)
+	}
+
+	if session.Mode == models.GameModeLive {
+		session.Phase = models.GamePhaseFinished
+		session.QuestionIndex = max(len(quiz.Questions)-1, 0)
+		session.PhaseStartedAt = utils.Ptr(finishedAt)
+		session.UpdatedAt = finishedAt
+	} else {
+		session.ClosesAt = utils.Ptr(startedAt.Add(challengeLength))
+		session.UpdatedAt = *session.ClosesAt
+	}
+
+	err := d.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(session).Exec(ctx); err != nil {
+			return err
+		}
+
+		if len(participants) == 0 {
+			return nil
+		}
+
+		if _, err := tx.NewInsert().Model(&participants).Exec(ctx); err != nil {
+			return err
+		}
+
+		if len(answers) == 0 {
+			return nil
+		}
+
+		_, err := tx.NewInsert().Model(&answers).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := d.reporter.Summarize(ctx, session); err != nil {
+		return 0, fmt.Errorf("seed: could not summarize demo session: %w", err)
+	}
+
+	return len(answers), nil
+}
+
+// play answers every question of the quiz as the participant, scoring
+// the answers the way a game does. It returns the answers and when the
+// participant's last question closed.
+func (d *demo) play(quiz *models.Quiz,
+	participant *models.GameParticipant,
+	startedAt time.Time,
+) ([]models.GameAnswer, time.Time, error) {
+	// how likely the player is to get a question right
+	skill := 0.3 + d.rng.Float64()*0.65
+
+	answers := make([]models.GameAnswer, 0, len(quiz.Questions))
+	at := startedAt
+
+	for i := range quiz.Questions {
+		question := &quiz.Questions[i]
+
+		kind, err := questionkind.ForQuestion(question)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		limit := game.TimeLimit(question.TimeLimitDuration)
+		answer := models.GameAnswer{
+			ID:            utils.Uuid(),
+			SessionID:     participant.SessionID,
+			ParticipantID: participant.ID,
+			QuestionID:    question.ID,
+			StartedAt:     at,
+		}
+
+		if d.rng.Float64() < timeoutRate {
+			answer.TimedOut = true
+			answer.AnsweredAt = utils.Ptr(at.Add(limit))
+			if kind.Scored() {
+				participant.Streak = 0
+			}
+		} else {
+			right := d.rng.Float64() < skill
+			submitted := d.answer(question, right)
+
+			raw, err := json.Marshal(submitted)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+
+			// players who know the answer tend to be quicker
+			speed := 0.2 + d.rng.Float64()*0.6
+			if !right {
+				speed += 0.2
+			}
+			elapsed := time.Duration(float64(limit) * speed)
+
+			result := kind.Score(question, submitted)
+			points := game.Points(kind, result, elapsed, limit)
+
+			participant.Streak = game.Streak(kind, result, participant.Streak)
+			if kind.Scored() && result.Correct {
+				participant.CorrectCount++
+				points += game.StreakPoints(participant.Streak)
+			}
+
+			answer.Answer = raw
+			answer.Correct = result.Correct
+			answer.Credit = result.Credit
+			answer.Points = points
+			answer.AnsweredAt = utils.Ptr(at.Add(elapsed))
+			participant.Score += points
+		}
+
+		answer.CreatedAt = answer.StartedAt
+		answer.UpdatedAt = *answer.AnsweredAt
+		answers = append(answers, answer)
+
+		participant.CurrentPosition++
+		participant.FinishedAt = answer.AnsweredAt
+		participant.UpdatedAt = *answer.AnsweredAt
+		at = at.Add(limit + questionGap)
+	}
+
+	return answers, at, nil
+}
+
+// answer makes up a submission for the question, the right one when
+// right is set.
+func (d *demo) answer(question *models.Question, right bool) *questionkind.Answer {
+	options := question.QuestionOptions
+	answer := &questionkind.Answer{}
+
+	switch question.QuestionType.Slug {
+	case questionkind.SlugQuiz:
+		for _, option := range options {
+			if option.IsCorrect == right {
+				answer.OptionIDs = append(answer.OptionIDs, option.ID)
+			}
+		}
+		if question.OptionType == models.OptionTypeSingleChoice || !right {
+			answer.OptionIDs = []string{answer.OptionIDs[d.rng.IntN(len(answer.OptionIDs))]}
+		}
+	case questionkind.SlugOrdering:
+		for _, option := range options {
+			answer.OptionIDs = append(answer.OptionIDs, option.ID)
+		}
+		if !right {
+			d.rng.Shuffle(len(answer.OptionIDs), func(i, j int) {
+				answer.OptionIDs[i], answer.OptionIDs[j] = answer.OptionIDs[j], answer.OptionIDs[i]
+			})
+		}
+	case questionkind.SlugPoll:
+		answer.OptionIDs = []string{options[d.rng.IntN(len(options))].ID}
+	case questionkind.SlugTrueFalse:
+		var settings questionkind.TrueFalseSettings
+		_ = json.Unmarshal(question.Settings, &settings)
+		answer.Boolean = utils.Ptr(*settings.Answer == right)
+	case questionkind.SlugTypeAnswer:
+		var settings questionkind.TypeAnswerSettings
+		_ = json.Unmarshal(question.Settings, &settings)
+		if right {
+			answer.Text = settings.AcceptedAnswers[d.rng.IntN(len(settings.AcceptedAnswers))]
+		} else {
+			answer.Text = d.response(question)
+		}
+	case questionkind.SlugSlider:
+		var settings questionkind.SliderSettings
+		_ = json.Unmarshal(question.Settings, &settings)
+		number := settings.Answer
+		if !right {
+			number = *settings.Min + d.rng.Float64()*(*settings.Max-*settings.Min)
+			if settings.Step > 0 {
+				number = *settings.Min + math.Round((number-*settings.Min)/settings.Step)*settings.Step
+			}
+		}
+		answer.Number = utils.Ptr(number)
+	case questionkind.SlugWordCloud:
+		answer.Text = d.response(question)
+	}
+
+	return answer
+}
+
+// response picks one of the typed responses the bank has for the
+// question.
+func (d *demo) response(question *models.Question) string {
+	responses := d.responses[question.ID]
+	if len(responses) == 0 {
+		return "pass"
+	}
+	return responses[d.rng.IntN(len(responses))]
+}
+
+func playing(participants []models.GameParticipant, userID string) bool {
+	for _, participant := range participants {
+		if participant.UserID != nil && *participant.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,96 @@
+package seed
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/oxiginedev/sabipass/internal/models"
+	"github.com/oxiginedev/sabipass/internal/questionkind"
+	"github.com/oxiginedev/sabipass/internal/report"
+	"github.com/oxiginedev/sabipass/utils"
+	"github.com/uptrace/bun"
+)
+
+// questionTypeNames are the display names of the canonical question
+// types, kinds missing here are named after their slug.
+var questionTypeNames = map[string]string{
+	questionkind.SlugQuiz:       "Quiz",
+	questionkind.SlugTrueFalse:  "True or False",
+	questionkind.SlugTypeAnswer: "Type Answer",
+	questionkind.SlugOrdering:   "Ordering",
+	questionkind.SlugSlider:     "Slider",
+	questionkind.SlugPoll:       "Poll",
+	questionkind.SlugWordCloud:  "Word Cloud",
+}
+
+// Seeder fills a database with the rows the app cannot run without and,
+// for development, with demo data.
+type Seeder struct {
+	db       *bun.DB
+	reporter *report.Reporter
+}
+
+func NewSeeder(db *bun.DB, reporter *report.Reporter) *Seeder {
+	return &Seeder{db: db, reporter: reporter}
+}
+
+// QuestionTypes inserts a question type for every registered question
+// kind that has none yet and returns how many it inserted. Existing
+// question types are left as they are, so seeding again is safe.
+func (s *Seeder) QuestionTypes(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	slugs := questionkind.Slugs()
+	questionTypes := make([]models.QuestionType, 0, len(slugs))
+	for _, slug := range slugs {
+		questionTypes = append(questionTypes, models.QuestionType{
+			ID:        utils.Uuid(),
+			Name:      questionTypeName(slug),
+			Slug:      slug,
+			Status:    models.QuestionTypeStatusActive,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	res, err := s.db.NewInsert().
+		Model(&questionTypes).
+		On("CONFLICT (slug) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	inserted, err := res.RowsAffected()
+	return int(inserted), err
+}
+
+// questionTypesBySlug returns the ids of the question types, keyed by
+// their slug.
+func (s *Seeder) questionTypesBySlug(ctx context.Context) (map[string]string, error) {
+	var questionTypes []models.QuestionType
+	if err := s.db.NewSelect().Model(&questionTypes).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	bySlug := make(map[string]string, len(questionTypes))
+	for _, questionType := range questionTypes {
+		bySlug[questionType.Slug] = questionType.ID
+	}
+	return bySlug, nil
+}
+
+func questionTypeName(slug string) string {
+	if name, ok := questionTypeNames[slug]; ok {
+		return name
+	}
+
+	words := strings.Split(slug, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// certificate holds the TLS certificate served, it is read again from its
+// files on SIGHUP.
+type certificate struct {
+	certFile string
+	keyFile  string
+	current  atomic.Pointer[tls.Certificate]
+}
+
+func loadCertificate(certFile, keyFile string) (*certificate, error) {
+	c := &certificate{certFile: certFile, keyFile: keyFile}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *certificate) reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("server: could not load tls certificate: %w", err)
+	}
+
+	c.current.Store(&cert)
+	return nil
+}
+
+func (c *certificate) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.current.Load(), nil
+}
+
+// watch reloads the certificate on every SIGHUP until ctx is done. A
+// certificate that fails to load is logged and the previous one kept.
+func (c *certificate) watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := c.reload(); err != nil {
+				slog.Error("[server]: could not reload tls certificate", slog.Any("error", err))
+				continue
+			}
+			slog.Info("reloaded tls certificate")
+		}
+	}
+}
@@ -2,9 +2,11 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,56 +17,194 @@ import (
 )
 
 type Server struct {
-	s      *http.Server
-	stopFn func()
+	s               *http.Server
+	admin           *http.Server
+	certFile        string
+	keyFile         string
+	stopFn          func()
+	stopping        []func()
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+	drainTimeout    time.Duration
+	drains          []func(context.Context) error
 }
 
 func NewServer(cfg *config.Config, stopFn func()) *Server {
-	return &Server{
-		s: &http.Server{
-			Addr:              fmt.Sprintf(":%d", cfg.HTTP.Port),
-			ReadTimeout:       10 * time.Second,
-			WriteTimeout:      10 * time.Second,
-			ReadHeaderTimeout: 2 * time.Second,
-		},
-		stopFn: stopFn,
+	s := &Server{
+		s:               newHTTPServer(cfg, cfg.HTTP.Port),
+		admin:           newHTTPServer(cfg, cfg.HTTP.AdminPort),
+		certFile:        cfg.HTTP.TLS.CertFile,
+		keyFile:         cfg.HTTP.TLS.KeyFile,
+		stopFn:          stopFn,
+		shutdownDelay:   cfg.HTTP.ShutdownDelay,
+		shutdownTimeout: cfg.HTTP.ShutdownTimeout,
+		drainTimeout:    cfg.Jobs.DrainTimeout,
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(s.tls())
+	protocols.SetUnencryptedHTTP2(cfg.HTTP.H2C)
+	s.s.Protocols = protocols
+
+	return s
+}
+
+func newHTTPServer(cfg *config.Config, port uint16) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 	}
 }
 
-func (s *Server) Listen() {
+// OnStopping registers fn to run as soon as the server is asked to stop,
+// while requests are still served for the shutdown delay.
+func (s *Server) OnStopping(fn func()) {
+	s.stopping = append(s.stopping, fn)
+}
+
+// OnShutdown registers fn to drain background work once the server stops
+// taking requests, before stopFn runs. Drains run one after the other and
+// share the drain timeout.
+func (s *Server) OnShutdown(fn func(context.Context) error) {
+	s.drains = append(s.drains, fn)
+}
+
+// Listen serves until ctx is done or serving fails, then shuts down
+// gracefully. It returns why serving stopped along with anything that
+// went wrong shutting down.
+func (s *Server) Listen(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.s.Addr)
+	if err != nil {
+		return fmt.Errorf("server: could not listen on %s: %w", s.s.Addr, err)
+	}
+
+	var adminLn net.Listener
+	if s.admin.Handler != nil {
+		adminLn, err = net.Listen("tcp", s.admin.Addr)
+		if err != nil {
+			_ = ln.Close()
+			return fmt.Errorf("server: could not listen on %s: %w", s.admin.Addr, err)
+		}
+	}
+
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+
+	if s.tls() {
+		certs, err := loadCertificate(s.certFile, s.keyFile)
+		if err != nil {
+			_ = ln.Close()
+			if adminLn != nil {
+				_ = adminLn.Close()
+			}
+			return err
+		}
+
+		s.s.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.getCertificate,
+		}
+		go certs.watch(watchCtx)
+	}
+
+	errs := make(chan error, 2)
 	go func() {
-		err := s.s.ListenAndServe()
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			slog.Error("could not start server", slog.Any("error", err))
-			os.Exit(1)
+		if s.tls() {
+			errs <- s.s.ServeTLS(ln, "", "")
+			return
 		}
+		errs <- s.s.Serve(ln)
 	}()
 
-	s.waitForShutdown()
+	if adminLn != nil {
+		go func() {
+			errs <- s.admin.Serve(adminLn)
+		}()
+	}
+
+	slog.Info("server started", slog.String("addr", s.s.Addr), slog.Bool("tls", s.tls()))
+
+	// the servers only return before shutdown when they fail
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case err := <-errs:
+		serveErr = fmt.Errorf("server: %w", err)
+	}
+
+	return errors.Join(serveErr, s.shutdown())
 }
 
 func (s *Server) SetHandler(handler http.Handler) {
 	s.s.Handler = handler
 }
 
-func (s *Server) waitForShutdown() {
+// SetAdminHandler serves handler on the admin port, it is not served when
+// the admin port is 0.
+func (s *Server) SetAdminHandler(handler http.Handler) {
+	if s.admin.Addr == ":0" {
+		return
+	}
+	s.admin.Handler = handler
+}
+
+func (s *Server) tls() bool {
+	return s.certFile != "" && s.keyFile != ""
+}
+
+// WaitForSignal blocks until the process is asked to stop.
+func WaitForSignal() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
+}
 
-	slog.Info("shutting down server gracefully...\n")
+func (s *Server) shutdown() error {
+	slog.Info("shutting down server gracefully...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	for _, fn := range s.stopping {
+		fn()
+	}
+	time.Sleep(s.shutdownDelay)
+
+	var errs []error
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 
 	if err := s.s.Shutdown(ctx); err != nil {
-		slog.Error("server forced to shutdown", slog.Any("error", err))
-		os.Exit(1)
+		errs = append(errs, fmt.Errorf("server: forced to shut down: %w", err))
+	} else {
+		slog.Info("server exited properly")
+	}
+
+	if len(s.drains) > 0 {
+		drainCtx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+		defer cancel()
+
+		for _, drain := range s.drains {
+			if err := drain(drainCtx); err != nil {
+				slog.Error("could not drain background work", slog.Any("error", err))
+			}
+		}
 	}
 
-	slog.Info("server exited properly")
+	// the admin server stays up while the api drains, so the drain shows
+	// up in the metrics
+	adminCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.admin.Shutdown(adminCtx); err != nil {
+		errs = append(errs, fmt.Errorf("server: could not shut down admin server: %w", err))
+	}
 
 	if s.stopFn != nil {
 		s.stopFn()
 	}
+
+	return errors.Join(errs...)
 }
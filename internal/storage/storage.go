@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/oxiginedev/sabipass/config"
+)
+
+var (
+	ErrBlobNotFound = errors.New("storage: blob not found")
+	ErrInvalidKey   = errors.New("storage: invalid blob key")
+)
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+func NewBlobStore(cfg *config.Config) (BlobStore, error) {
+	switch cfg.Storage.Driver {
+	case config.StorageDriverLocal:
+		return NewLocalStore(cfg.Storage.Local.Path, cfg.Storage.Local.BaseURL)
+	case config.StorageDriverS3:
+		return NewS3Store(S3Options{
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			Region:          cfg.Storage.S3.Region,
+			Bucket:          cfg.Storage.S3.Bucket,
+			AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+			PublicURL:       cfg.Storage.S3.PublicURL,
+			UsePathStyle:    cfg.Storage.S3.UsePathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q", cfg.Storage.Driver)
+	}
+}